@@ -39,6 +39,10 @@ var (
 	// ErrNoCodeAfterDeploy is returned by WaitDeployed if contract creation leaves
 	// an empty contract behind.
 	ErrNoCodeAfterDeploy = errors.New("no contract code after deployment")
+
+	// ErrNoChainReader is raised when attempting to stream events on a backend
+	// that doesn't implement ContractChainReader.
+	ErrNoChainReader = errors.New("backend does not support chain access")
 )
 
 // ContractCaller defines the methods needed to allow operating with a contract on a read
@@ -112,6 +116,18 @@ type ContractFilterer interface {
 	SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
 }
 
+// ContractChainReader defines the methods needed to check the position of a
+// resumed event stream against the canonical chain. Event streaming will try to
+// discover this interface on the filterer.
+type ContractChainReader interface {
+	// HeaderByHash returns the block header with the given hash.
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+
+	// HeaderByNumber returns a block header from the current canonical chain. If
+	// number is nil, the latest known header is returned.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
 // DeployBackend wraps the operations needed by WaitMined and WaitDeployed.
 type DeployBackend interface {
 	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
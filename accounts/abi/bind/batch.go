@@ -0,0 +1,335 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	ethereum "github.com/ubiq/go-ubiq/v7"
+	"github.com/ubiq/go-ubiq/v7/accounts/abi"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/rpc"
+)
+
+// maxBatchCalls is the maximum number of contract calls executed in a single
+// JSON-RPC batch or multicall invocation.
+const maxBatchCalls = 1000
+
+// MulticallABI is the input ABI of the tryAggregate method of the Multicall2
+// (and compatible Multicall3) contract, used to aggregate calls on chain.
+const MulticallABI = `[{"inputs":[{"internalType":"bool","name":"requireSuccess","type":"bool"},{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall2.Call[]","name":"calls","type":"tuple[]"}],"name":"tryAggregate","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall2.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"nonpayable","type":"function"}]`
+
+var (
+	// ErrBatchPending is returned when retrieving the results of a batched call
+	// before the batch it was queued into was executed.
+	ErrBatchPending = errors.New("batch not executed yet")
+
+	// multicallABI is the parsed version of MulticallABI.
+	multicallABI, _ = abi.JSON(strings.NewReader(MulticallABI))
+)
+
+// multicallCall is a single call aggregated by the multicall contract.
+type multicallCall struct {
+	Target   common.Address
+	CallData []byte
+}
+
+// multicallResult is the outcome of a single call aggregated by the multicall
+// contract.
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// batchCall is a contract call queued into a batch.
+type batchCall struct {
+	contract *BoundContract
+	opts     *CallOpts
+	method   string
+	input    []byte
+	results  *[]interface{}
+
+	done bool  // Whether the batch containing the call was executed
+	err  error // Error of the call execution or result unpacking
+}
+
+// BatchCaller collects contract calls and executes them in as few round trips as
+// possible: either as a single JSON-RPC batch of eth_call requests, or as a single
+// eth_call per block aggregating them via an on-chain multicall contract.
+//
+// A BatchCaller can be reused after being executed. It is safe for concurrent use.
+type BatchCaller struct {
+	client    *rpc.Client    // RPC client to batch the calls with (nil = multicall)
+	caller    ContractCaller // Contract caller to invoke the multicall contract through
+	multicall common.Address // Address of the multicall contract to aggregate calls with
+
+	calls []*batchCall
+	lock  sync.Mutex
+}
+
+// NewBatchCaller creates a batch caller executing the queued calls as a single
+// JSON-RPC batch through the given client.
+func NewBatchCaller(client *rpc.Client) *BatchCaller {
+	return &BatchCaller{client: client}
+}
+
+// NewMulticallCaller creates a batch caller aggregating the queued calls through
+// a deployed Multicall2 compatible contract. Note, the aggregated calls are all
+// executed with the multicall contract as the sender, ignoring CallOpts.From.
+func NewMulticallCaller(caller ContractCaller, multicall common.Address) *BatchCaller {
+	return &BatchCaller{caller: caller, multicall: multicall}
+}
+
+// Len returns the number of calls queued up for execution.
+func (b *BatchCaller) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.calls)
+}
+
+// queue adds a contract call to the batch.
+func (b *BatchCaller) queue(call *batchCall) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.calls = append(b.calls, call)
+}
+
+// finish unpacks the output of an executed call into the requested results,
+// unless the call was already finished before.
+func (b *BatchCaller) finish(call *batchCall, output []byte, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if call.done {
+		return
+	}
+	call.done = true
+	if err != nil {
+		call.err = err
+		return
+	}
+	if len(*call.results) == 0 {
+		*call.results, call.err = call.contract.abi.Unpack(call.method, output)
+		return
+	}
+	call.err = call.contract.abi.UnpackIntoInterface((*call.results)[0], call.method, output)
+}
+
+// Execute runs all the queued calls and resets the batch. An error is only
+// returned if the batch as a whole failed, individual call failures are reported
+// through the result retrieval of each call.
+func (b *BatchCaller) Execute(ctx context.Context) error {
+	b.lock.Lock()
+	calls := b.calls
+	b.calls = nil
+	b.lock.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var err error
+	if b.client != nil {
+		err = b.executeBatch(ctx, calls)
+	} else {
+		err = b.executeMulticall(ctx, calls)
+	}
+	// Fail any calls left hanging, so they don't report ErrBatchPending forever
+	if err != nil {
+		for _, call := range calls {
+			b.finish(call, nil, err)
+		}
+	}
+	return err
+}
+
+// executeBatch runs the calls as JSON-RPC batches of eth_call requests.
+func (b *BatchCaller) executeBatch(ctx context.Context, calls []*batchCall) error {
+	for len(calls) > 0 {
+		chunk := calls
+		if len(chunk) > maxBatchCalls {
+			chunk = chunk[:maxBatchCalls]
+		}
+		calls = calls[len(chunk):]
+
+		var (
+			outputs = make([]hexutil.Bytes, len(chunk))
+			reqs    = make([]rpc.BatchElem, len(chunk))
+		)
+		for i, call := range chunk {
+			arg := map[string]interface{}{
+				"from": call.opts.From,
+				"to":   call.contract.address,
+				"data": hexutil.Bytes(call.input),
+			}
+			block := "latest"
+			if call.opts.Pending {
+				block = "pending"
+			} else if call.opts.BlockNumber != nil {
+				block = hexutil.EncodeBig(call.opts.BlockNumber)
+			}
+			reqs[i] = rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{arg, block},
+				Result: &outputs[i],
+			}
+		}
+		if err := b.client.BatchCallContext(ctx, reqs); err != nil {
+			return err
+		}
+		for i, call := range chunk {
+			b.finish(call, outputs[i], reqs[i].Error)
+		}
+	}
+	return nil
+}
+
+// executeMulticall runs the calls through the multicall contract, aggregating
+// all the ones targeting the same block into a single eth_call.
+func (b *BatchCaller) executeMulticall(ctx context.Context, calls []*batchCall) error {
+	var (
+		order  []string
+		groups = make(map[string][]*batchCall)
+	)
+	for _, call := range calls {
+		key := "latest"
+		if call.opts.Pending {
+			key = "pending"
+		} else if call.opts.BlockNumber != nil {
+			key = call.opts.BlockNumber.String()
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], call)
+	}
+	for _, key := range order {
+		calls := groups[key]
+		for len(calls) > 0 {
+			chunk := calls
+			if len(chunk) > maxBatchCalls {
+				chunk = chunk[:maxBatchCalls]
+			}
+			calls = calls[len(chunk):]
+
+			if err := b.aggregate(ctx, chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// aggregate runs a set of calls targeting the same block through the multicall
+// contract.
+func (b *BatchCaller) aggregate(ctx context.Context, calls []*batchCall) error {
+	aggregated := make([]multicallCall, len(calls))
+	for i, call := range calls {
+		aggregated[i] = multicallCall{Target: call.contract.address, CallData: call.input}
+	}
+	input, err := multicallABI.Pack("tryAggregate", false, aggregated)
+	if err != nil {
+		return err
+	}
+	var (
+		msg    = ethereum.CallMsg{To: &b.multicall, Data: input}
+		opts   = calls[0].opts
+		output []byte
+	)
+	if opts.Pending {
+		pb, ok := b.caller.(PendingContractCaller)
+		if !ok {
+			return ErrNoPendingState
+		}
+		output, err = pb.PendingCallContract(ctx, msg)
+	} else {
+		output, err = b.caller.CallContract(ctx, msg, opts.BlockNumber)
+	}
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		return ErrNoCode
+	}
+	unpacked, err := multicallABI.Unpack("tryAggregate", output)
+	if err != nil {
+		return err
+	}
+	results := *abi.ConvertType(unpacked[0], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(calls) {
+		return fmt.Errorf("multicall result count mismatch: have %d, want %d", len(results), len(calls))
+	}
+	for i, call := range calls {
+		if !results[i].Success {
+			b.finish(call, nil, multicallRevertError(results[i].ReturnData))
+			continue
+		}
+		b.finish(call, results[i].ReturnData, nil)
+	}
+	return nil
+}
+
+// multicallRevertError converts the return data of a failed aggregated call into
+// an error, unpacking the revert reason if available.
+func multicallRevertError(data []byte) error {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return fmt.Errorf("execution reverted: %v", reason)
+	}
+	return errors.New("execution reverted")
+}
+
+// BatchCall queues the (constant) contract method call with params as input
+// values into a batch, instead of executing it directly. The returned function
+// reports the outcome of the call once the batch was executed, at which point
+// the results are also filled in.
+func (c *BoundContract) BatchCall(batch *BatchCaller, opts *CallOpts, results *[]interface{}, method string, params ...interface{}) func() error {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	if results == nil {
+		results = new([]interface{})
+	}
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return func() error { return err }
+	}
+	call := &batchCall{
+		contract: c,
+		opts:     opts,
+		method:   method,
+		input:    input,
+		results:  results,
+	}
+	batch.queue(call)
+
+	return func() error {
+		batch.lock.Lock()
+		defer batch.lock.Unlock()
+
+		if !call.done {
+			return ErrBatchPending
+		}
+		return call.err
+	}
+}
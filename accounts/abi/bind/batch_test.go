@@ -0,0 +1,164 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	ethereum "github.com/ubiq/go-ubiq/v7"
+	"github.com/ubiq/go-ubiq/v7/accounts/abi"
+	"github.com/ubiq/go-ubiq/v7/accounts/abi/bind"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/rpc"
+)
+
+const batchTestABI = `[{"inputs":[{"name":"x","type":"uint256"}],"name":"double","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"fail","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+var batchTestParsed, _ = abi.JSON(strings.NewReader(batchTestABI))
+
+// batchTestExec emulates the execution of the batch test contract.
+func batchTestExec(data []byte) ([]byte, error) {
+	method, err := batchTestParsed.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "fail" {
+		return nil, errors.New("execution reverted")
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(new(big.Int).Lsh(args[0].(*big.Int), 1))
+}
+
+// batchTestService is an eth RPC namespace executing calls against the batch
+// test contract.
+type batchTestService struct{}
+
+type batchTestCallArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+func (s *batchTestService) Call(args batchTestCallArgs, block string) (hexutil.Bytes, error) {
+	return batchTestExec(args.Data)
+}
+
+// batchTestMulticaller is a contract caller emulating a deployed multicall
+// contract aggregating calls to the batch test contract.
+type batchTestMulticaller struct {
+	calls  int
+	blocks []*big.Int
+}
+
+func (mc *batchTestMulticaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (mc *batchTestMulticaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	mc.calls++
+	mc.blocks = append(mc.blocks, blockNumber)
+
+	parsed, _ := abi.JSON(strings.NewReader(bind.MulticallABI))
+	method := parsed.Methods["tryAggregate"]
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	type aggregated struct {
+		Target   common.Address
+		CallData []byte
+	}
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	var results []result
+	for _, call := range *abi.ConvertType(args[1], new([]aggregated)).(*[]aggregated) {
+		output, err := batchTestExec(call.CallData)
+		results = append(results, result{Success: err == nil, ReturnData: output})
+	}
+	return method.Outputs.Pack(results)
+}
+
+// Tests that batched contract calls deliver the same results as individual ones,
+// with failing calls not affecting the others.
+func TestBatchCaller(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", new(batchTestService)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	multicaller := new(batchTestMulticaller)
+	batchers := map[string]*bind.BatchCaller{
+		"rpc":       bind.NewBatchCaller(client),
+		"multicall": bind.NewMulticallCaller(multicaller, common.HexToAddress("0xca11")),
+	}
+	for name, batch := range batchers {
+		contract := bind.NewBoundContract(common.HexToAddress("0x01"), batchTestParsed, nil, nil, nil)
+
+		var (
+			outs    = make([][]interface{}, 3)
+			results = make([]func() error, 3)
+		)
+		for i := range results {
+			results[i] = contract.BatchCall(batch, nil, &outs[i], "double", big.NewInt(int64(i+1)))
+		}
+		var failed []interface{}
+		failure := contract.BatchCall(batch, &bind.CallOpts{BlockNumber: big.NewInt(1)}, &failed, "fail")
+
+		if err := results[0](); err != bind.ErrBatchPending {
+			t.Fatalf("%s: pending call error mismatch: have %v, want %v", name, err, bind.ErrBatchPending)
+		}
+		if batch.Len() != 4 {
+			t.Fatalf("%s: queued call count mismatch: have %d, want 4", name, batch.Len())
+		}
+		if err := batch.Execute(context.Background()); err != nil {
+			t.Fatalf("%s: failed to execute batch: %v", name, err)
+		}
+		for i, result := range results {
+			if err := result(); err != nil {
+				t.Fatalf("%s: call %d failed: %v", name, i, err)
+			}
+			if have, want := outs[i][0].(*big.Int), big.NewInt(int64(2*(i+1))); have.Cmp(want) != 0 {
+				t.Errorf("%s: call %d result mismatch: have %v, want %v", name, i, have, want)
+			}
+		}
+		if err := failure(); err == nil {
+			t.Errorf("%s: failing call succeeded", name)
+		}
+		if batch.Len() != 0 {
+			t.Errorf("%s: batch not reset after execution", name)
+		}
+	}
+	// Calls against different blocks must be aggregated separately
+	if multicaller.calls != 2 {
+		t.Fatalf("multicall invocation count mismatch: have %d, want 2", multicaller.calls)
+	}
+	if multicaller.blocks[0] != nil || multicaller.blocks[1].Uint64() != 1 {
+		t.Errorf("multicall block mismatch: have %v, want [nil 1]", multicaller.blocks)
+	}
+}
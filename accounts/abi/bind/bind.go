@@ -41,6 +41,7 @@ const (
 	LangGo Lang = iota
 	LangJava
 	LangObjC
+	LangTypeScript
 )
 
 // Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
@@ -225,6 +226,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 
 	funcs := map[string]interface{}{
 		"bindtype":      bindType[lang],
+		"bindinputtype": bindInputType[lang],
 		"bindtopictype": bindTopicType[lang],
 		"namedtype":     namedType[lang],
 		"capitalise":    capitalise,
@@ -249,8 +251,18 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language types.
 var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo:   bindTypeGo,
-	LangJava: bindTypeJava,
+	LangGo:         bindTypeGo,
+	LangJava:       bindTypeJava,
+	LangTypeScript: bindTypeTypeScript,
+}
+
+// bindInputType is a set of type binders that convert Solidity types to the types
+// accepted as method arguments. Only languages with loosely typed inputs differ
+// from bindType.
+var bindInputType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
+	LangGo:         bindTypeGo,
+	LangJava:       bindTypeJava,
+	LangTypeScript: bindInputTypeTypeScript,
 }
 
 // bindBasicTypeGo converts basic solidity types(except array, slice and tuple) to Go ones.
@@ -368,11 +380,62 @@ func bindTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// bindBasicTypeTypeScript converts basic solidity types(except array, slice and
+// tuple) to the TypeScript ones returned by ethers.js.
+func bindBasicTypeTypeScript(kind abi.Type) string {
+	switch kind.T {
+	case abi.AddressTy, abi.StringTy, abi.FixedBytesTy, abi.BytesTy, abi.FunctionTy:
+		return "string"
+	case abi.IntTy, abi.UintTy:
+		// Integers that fit into a double without losing precision are returned as
+		// plain numbers by ethers.js, everything else as a BigNumber.
+		if kind.Size <= 48 {
+			return "number"
+		}
+		return "BigNumber"
+	case abi.BoolTy:
+		return "boolean"
+	default:
+		return kind.String()
+	}
+}
+
+// bindTypeTypeScript converts a Solidity type to a TypeScript one, as returned
+// from calls and events.
+func bindTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy, abi.SliceTy:
+		return bindTypeTypeScript(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTypeScript(kind)
+	}
+}
+
+// bindInputTypeTypeScript converts a Solidity type to the TypeScript one accepted
+// as a method argument, which is laxer than the returned one.
+func bindInputTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return structs[kind.TupleRawName+kind.String()].Name + "Input"
+	case abi.ArrayTy, abi.SliceTy:
+		return bindInputTypeTypeScript(*kind.Elem, structs) + "[]"
+	case abi.IntTy, abi.UintTy:
+		return "BigNumberish"
+	case abi.FixedBytesTy, abi.BytesTy, abi.FunctionTy:
+		return "BytesLike"
+	default:
+		return bindBasicTypeTypeScript(kind)
+	}
+}
+
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
 var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo:   bindTopicTypeGo,
-	LangJava: bindTopicTypeJava,
+	LangGo:         bindTopicTypeGo,
+	LangJava:       bindTopicTypeJava,
+	LangTypeScript: bindTopicTypeTypeScript,
 }
 
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
@@ -409,11 +472,24 @@ func bindTopicTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	return bound
 }
 
+// bindTopicTypeTypeScript converts a Solidity topic type to a TypeScript one.
+// Contrary to the other languages, all non value types are hashed, which ethers.js
+// represents as an Indexed object.
+func bindTopicTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.ArrayTy, abi.SliceTy, abi.TupleTy:
+		return "utils.Indexed"
+	default:
+		return bindTypeTypeScript(kind, structs)
+	}
+}
+
 // bindStructType is a set of type binders that convert Solidity tuple types to some supported
 // programming language struct definition.
 var bindStructType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
-	LangGo:   bindStructTypeGo,
-	LangJava: bindStructTypeJava,
+	LangGo:         bindStructTypeGo,
+	LangJava:       bindStructTypeJava,
+	LangTypeScript: bindStructTypeTypeScript,
 }
 
 // bindStructTypeGo converts a Solidity tuple type to a Go one and records the mapping
@@ -492,11 +568,44 @@ func bindStructTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	}
 }
 
+// bindStructTypeTypeScript converts a Solidity tuple type to a TypeScript one and
+// records the mapping in the given map. Field names are kept as is, since ethers.js
+// exposes the tuple fields by their original names.
+// Notably, this function will resolve and record nested struct recursively.
+func bindStructTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		id := kind.TupleRawName + kind.String()
+		if s, exist := structs[id]; exist {
+			return s.Name
+		}
+		var fields []*tmplField
+		for i, elem := range kind.TupleElems {
+			field := bindStructTypeTypeScript(*elem, structs)
+			fields = append(fields, &tmplField{Type: field, Name: kind.TupleRawNames[i], SolKind: *elem})
+		}
+		name := kind.TupleRawName
+		if name == "" {
+			name = fmt.Sprintf("Struct%d", len(structs))
+		}
+		structs[id] = &tmplStruct{
+			Name:   name,
+			Fields: fields,
+		}
+		return name
+	case abi.ArrayTy, abi.SliceTy:
+		return bindStructTypeTypeScript(*kind.Elem, structs) + "[]"
+	default:
+		return bindBasicTypeTypeScript(kind)
+	}
+}
+
 // namedType is a set of functions that transform language specific types to
 // named versions that may be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
-	LangGo:   func(string, abi.Type) string { panic("this shouldn't be needed") },
-	LangJava: namedTypeJava,
+	LangGo:         func(string, abi.Type) string { panic("this shouldn't be needed") },
+	LangJava:       namedTypeJava,
+	LangTypeScript: func(string, abi.Type) string { panic("this shouldn't be needed") },
 }
 
 // namedTypeJava converts some primitive data types to named variants that can
@@ -537,8 +646,9 @@ func alias(aliases map[string]string, n string) string {
 // methodNormalizer is a name transformer that modifies Solidity method names to
 // conform to target language naming conventions.
 var methodNormalizer = map[Lang]func(string) string{
-	LangGo:   abi.ToCamelCase,
-	LangJava: decapitalise,
+	LangGo:         abi.ToCamelCase,
+	LangJava:       decapitalise,
+	LangTypeScript: decapitalise,
 }
 
 // capitalise makes a camel-case string which starts with an upper case character.
@@ -1062,6 +1062,54 @@ var bindTests = []struct {
 				t.Fatalf("unsubscribed simple event arrived: %v", event)
 			case <-time.After(250 * time.Millisecond):
 			}
+			// Test streaming past events from a given block and tracking a cursor
+			var (
+				start  = uint64(1)
+				cursor = new(bind.EventCursor)
+				stream = make(chan *EventerSimpleEvent, 16)
+			)
+			sub, err = eventer.StreamSimpleEvent(&bind.StreamOpts{Start: &start}, stream, []common.Address{common.Address{1}}, nil, nil)
+			if err != nil {
+				t.Fatalf("failed to stream simple events: %v", err)
+			}
+			for _, want := range []uint64{11, 21, 31} {
+				select {
+				case event := <-stream:
+					if event.Value.Uint64() != want || event.Raw.Removed {
+						t.Errorf("streamed log content mismatch: have %v, want %d", event, want)
+					}
+					cursor.Advance(event.Raw)
+				case <-time.After(250 * time.Millisecond):
+					t.Fatalf("streamed simple event %d didn't arrive", want)
+				}
+			}
+			sub.Unsubscribe()
+
+			// Raise a new event while offline and resume the stream from the cursor
+			if _, err := eventer.RaiseSimpleEvent(auth, common.Address{1}, [32]byte{1}, true, big.NewInt(100)); err != nil {
+				t.Fatalf("failed to raise streamed simple event: %v", err)
+			}
+			sim.Commit()
+
+			sub, err = eventer.StreamSimpleEvent(&bind.StreamOpts{Cursor: cursor}, stream, []common.Address{common.Address{1}}, nil, nil)
+			if err != nil {
+				t.Fatalf("failed to resume simple event stream: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			select {
+			case event := <-stream:
+				if event.Value.Uint64() != 100 {
+					t.Errorf("resumed log content mismatch: have %v, want 100", event)
+				}
+			case <-time.After(250 * time.Millisecond):
+				t.Fatalf("resumed simple event didn't arrive")
+			}
+			select {
+			case event := <-stream:
+				t.Fatalf("already streamed simple event arrived again: %v", event)
+			case <-time.After(250 * time.Millisecond):
+			}
 		`,
 		nil,
 		nil,
@@ -2424,3 +2472,41 @@ public class Test {
 		}
 	}
 }
+
+// Tests that TypeScript bindings are generated with typed structs, overloaded
+// methods bound by their full signature and typed event accessors.
+func TestTypeScriptBindings(t *testing.T) {
+	abi := `[
+		{"type":"constructor","inputs":[{"name":"owner","type":"address"}],"stateMutability":"payable"},
+		{"type":"function","name":"double","inputs":[{"name":"x","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+		{"type":"function","name":"double","inputs":[{"name":"x","type":"uint256"},{"name":"y","type":"uint8"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+		{"type":"function","name":"pair","inputs":[],"outputs":[{"name":"a","type":"uint32"},{"name":"b","type":"string"}],"stateMutability":"view"},
+		{"type":"function","name":"store","inputs":[{"name":"item","type":"tuple","internalType":"struct Item","components":[{"name":"id","type":"uint64"},{"name":"data","type":"bytes"}]}],"outputs":[],"stateMutability":"nonpayable"},
+		{"type":"event","name":"Stored","anonymous":false,"inputs":[{"name":"who","type":"address","indexed":true},{"name":"tag","type":"string","indexed":true},{"name":"n","type":"uint256","indexed":false}]}
+	]`
+	binding, err := Bind([]string{"Store"}, []string{abi}, []string{"0x6060"}, nil, "", LangTypeScript, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, want := range []string{
+		"export interface Item {",
+		"id: BigNumber;",
+		"export interface ItemInput {",
+		"data: BytesLike;",
+		"export async function deployStore(signer: Signer, owner: string, overrides: PayableOverrides = {}): Promise<Store> {",
+		"export type StorePairOutput = [number, string] & { a: number; b: string; };",
+		"async double(x: BigNumberish, overrides: CallOverrides = {}): Promise<BigNumber> {",
+		`return this.contract["double(uint256)"](x, overrides);`,
+		"async double0(x: BigNumberish, y: BigNumberish, overrides: CallOverrides = {}): Promise<BigNumber> {",
+		`return this.contract["double(uint256,uint8)"](x, y, overrides);`,
+		"async store(item: ItemInput, overrides: Overrides = {}): Promise<ContractTransaction> {",
+		"tag: utils.Indexed;",
+		"filterStored(who?: string | string[] | null, tag?: string | string[] | null): EventFilter {",
+		`return this.contract.filters["Stored(address,string,uint256)"](who, tag);`,
+		"watchStored(listener: (event: StoreStoredEvent) => void, filter: EventFilter = this.filterStored()): () => void {",
+	} {
+		if !strings.Contains(binding, want) {
+			t.Errorf("binding missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ubiq/go-ubiq/v7"
+	"github.com/ubiq/go-ubiq/v7/accounts/abi"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/event"
+)
+
+// StreamOpts is the collection of options to fine tune a reorg aware event
+// stream within a bound contract.
+type StreamOpts struct {
+	Start   *uint64         // Start of the queried range if not resuming (nil = latest)
+	Cursor  *EventCursor    // Checkpoint to resume the stream after (nil = start fresh)
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
+// EventCursor is a checkpoint within a contract event stream, which can be
+// persisted by the consumer and used to resume the stream after a restart.
+type EventCursor struct {
+	BlockNumber uint64      `json:"blockNumber"` // Number of the block the cursor is in
+	BlockHash   common.Hash `json:"blockHash"`   // Hash of the block the cursor is in
+	LogIndex    uint        `json:"logIndex"`    // Index of the first log within the block not yet processed
+}
+
+// Advance moves the cursor past a processed log. Removed logs rewind the cursor
+// to right before their original position instead, so that a resumed stream
+// neither skips nor double reverts any events.
+func (c *EventCursor) Advance(log types.Log) {
+	if log.Removed {
+		if log.BlockNumber < c.BlockNumber || (log.BlockNumber == c.BlockNumber && log.Index < c.LogIndex) {
+			c.BlockNumber, c.BlockHash, c.LogIndex = log.BlockNumber, log.BlockHash, log.Index
+		}
+		return
+	}
+	c.BlockNumber, c.BlockHash, c.LogIndex = log.BlockNumber, log.BlockHash, log.Index+1
+}
+
+// logPosition uniquely identifies a log across all forks.
+type logPosition struct {
+	block common.Hash
+	index uint
+}
+
+// StreamLogs subscribes to contract logs, returning a subscription object that
+// can be used to tear down the stream. Contrary to WatchLogs, the stream can be
+// resumed from a cursor: any events rolled back while the consumer was offline
+// are first delivered as removed, followed by all the events since the last
+// canonical checkpoint and finally the live ones, removed logs included.
+func (c *BoundContract) StreamLogs(opts *StreamOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(StreamOpts)
+	}
+	reader, ok := c.filterer.(ContractChainReader)
+	if !ok {
+		return nil, nil, ErrNoChainReader
+	}
+	// Append the event selector to the query parameters and construct the topic set
+	query = append([][]interface{}{{c.abi.Events[name].ID}}, query...)
+
+	topics, err := abi.MakeTopics(query...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := ensureContext(opts.Context)
+	config := ethereum.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+	}
+	// Subscribe to live logs before catching up to avoid missing any in between
+	live := make(chan types.Log, 128)
+	sub, err := c.filterer.SubscribeFilterLogs(ctx, config, live)
+	if err != nil {
+		return nil, nil, err
+	}
+	head, err := reader.HeaderByNumber(ctx, nil)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, nil, err
+	}
+	// Resolve the position to resume from, reverting any events which were rolled
+	// back since the cursor was taken
+	var (
+		removed []types.Log
+		from    = head.Number.Uint64() + 1
+		skip    uint
+	)
+	switch {
+	case opts.Cursor != nil:
+		removed, from, skip, err = c.rewindCursor(ctx, reader, config, opts.Cursor)
+		if err != nil {
+			sub.Unsubscribe()
+			return nil, nil, err
+		}
+	case opts.Start != nil:
+		from = *opts.Start
+	}
+	// Retrieve all the events since the resume position up to the current head
+	var past []types.Log
+	if from <= head.Number.Uint64() {
+		config.FromBlock = new(big.Int).SetUint64(from)
+		config.ToBlock = head.Number
+		if past, err = c.filterer.FilterLogs(ctx, config); err != nil {
+			sub.Unsubscribe()
+			return nil, nil, err
+		}
+	}
+	// Track the logs known to the consumer up to the current head, so that live
+	// duplicates are dropped and only delivered events are reported as removed.
+	// Everything past the head is forwarded as is.
+	var (
+		limit = head.Number.Uint64()
+		known = make(map[logPosition]struct{})
+	)
+	delivered := func(log types.Log) bool {
+		if _, ok := known[logPosition{log.BlockHash, log.Index}]; ok {
+			return true
+		}
+		// Logs before the resume position were processed prior to a restart
+		if opts.Cursor == nil || log.BlockNumber > opts.Cursor.BlockNumber {
+			return false
+		}
+		return log.BlockNumber < opts.Cursor.BlockNumber || (log.BlockHash == opts.Cursor.BlockHash && log.Index < opts.Cursor.LogIndex)
+	}
+	logs := make(chan types.Log, 128)
+	return logs, event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+
+		send := func(log types.Log) bool {
+			select {
+			case logs <- log:
+				return true
+			case <-quit:
+				return false
+			}
+		}
+		for _, log := range removed {
+			if !send(log) {
+				return nil
+			}
+		}
+		for _, log := range past {
+			if log.BlockNumber == from && log.Index < skip {
+				continue
+			}
+			known[logPosition{log.BlockHash, log.Index}] = struct{}{}
+			if !send(log) {
+				return nil
+			}
+		}
+		for {
+			select {
+			case log := <-live:
+				if log.BlockNumber <= limit {
+					if log.Removed != delivered(log) {
+						continue
+					}
+					if !log.Removed {
+						known[logPosition{log.BlockHash, log.Index}] = struct{}{}
+					}
+				}
+				select {
+				case logs <- log:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// rewindCursor checks a stream cursor against the canonical chain. If the block
+// it points to was reorged out, the logs delivered from the stale blocks are
+// collected as removed ones and the cursor is rewound to the common ancestor.
+// The block to resume from is returned, along with the index of the first log
+// within it not yet processed.
+func (c *BoundContract) rewindCursor(ctx context.Context, reader ContractChainReader, config ethereum.FilterQuery, cursor *EventCursor) ([]types.Log, uint64, uint, error) {
+	header, err := reader.HeaderByHash(ctx, cursor.BlockHash)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("cursor block %x unavailable: %v", cursor.BlockHash, err)
+	}
+	var (
+		stale [][]types.Log
+		limit = cursor.LogIndex
+	)
+	for {
+		canonical, err := reader.HeaderByNumber(ctx, header.Number)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, 0, 0, err
+		}
+		hash := header.Hash()
+		if canonical != nil && canonical.Hash() == hash {
+			break
+		}
+		// Block reorged out, gather all the logs the consumer has seen from it
+		config.BlockHash = &hash
+		logs, err := c.filterer.FilterLogs(ctx, config)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		var removed []types.Log
+		for _, log := range logs {
+			if log.Index < limit {
+				log.Removed = true
+				removed = append(removed, log)
+			}
+		}
+		stale = append(stale, removed)
+
+		if header.Number.Sign() == 0 {
+			return nil, 0, 0, errors.New("cursor not on a chain with a known genesis")
+		}
+		if header, err = reader.HeaderByHash(ctx, header.ParentHash); err != nil {
+			return nil, 0, 0, err
+		}
+		limit = ^uint(0)
+	}
+	// Deliver the reverted logs in chain order, same as live reorgs do
+	var removed []types.Log
+	for i := len(stale) - 1; i >= 0; i-- {
+		removed = append(removed, stale[i]...)
+	}
+	if len(stale) > 0 {
+		return removed, header.Number.Uint64() + 1, 0, nil
+	}
+	return nil, cursor.BlockNumber, cursor.LogIndex, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/accounts/abi"
+	"github.com/ubiq/go-ubiq/v7/accounts/abi/bind"
+	"github.com/ubiq/go-ubiq/v7/accounts/abi/bind/backends"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+)
+
+// Callable contract emitting a Called() event on each Call() invocation.
+const (
+	callableAbi = "[{\"anonymous\":false,\"inputs\":[],\"name\":\"Called\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"Call\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+	callableBin = "6080604052348015600f57600080fd5b5060998061001e6000396000f3fe6080604052348015600f57600080fd5b506004361060285760003560e01c806334e2292114602d575b600080fd5b60336035565b005b7f81fab7a4a0aa961db47eefc81f143a5220e8c8495260dd65b1356f1d19d3c7b860405160405180910390a156fea2646970667358221220029436d24f3ac598ceca41d4d712e13ced6d70727f4cdc580667de66d2f51d8b64736f6c63430008010033"
+)
+
+// Tests that event streams deliver events rolled back by reorgs as removed ones,
+// both live and when resuming from a cursor after the reorg already happened.
+func TestStreamLogsReorg(t *testing.T) {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(100000000000000000)},
+	}, 10000000)
+	defer sim.Close()
+
+	parsed, _ := abi.JSON(strings.NewReader(callableAbi))
+	auth, _ := bind.NewKeyedTransactorWithChainID(testKey, big.NewInt(1337))
+	_, _, contract, err := bind.DeployContract(auth, parsed, common.FromHex(callableBin), sim)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	sim.Commit()
+	parent := sim.Blockchain().CurrentBlock()
+
+	call := func() *types.Transaction {
+		tx, err := contract.Transact(auth, "Call")
+		if err != nil {
+			t.Fatalf("failed to call contract: %v", err)
+		}
+		return tx
+	}
+	next := func(logs chan types.Log, tx *types.Transaction, removed bool) types.Log {
+		select {
+		case log := <-logs:
+			if log.TxHash != tx.Hash() {
+				t.Fatalf("event tx hash mismatch: have %x, want %x", log.TxHash, tx.Hash())
+			}
+			if log.Removed != removed {
+				t.Fatalf("event removal mismatch: have %v, want %v", log.Removed, removed)
+			}
+			return log
+		case <-time.After(3 * time.Second):
+			t.Fatalf("event stream timeout")
+		}
+		return types.Log{}
+	}
+	// Stream two events from a fresh block and checkpoint them
+	logs, sub, err := contract.StreamLogs(nil, "Called")
+	if err != nil {
+		t.Fatalf("failed to stream logs: %v", err)
+	}
+	first, second := call(), call()
+	sim.Commit()
+
+	cursor := new(bind.EventCursor)
+	cursor.Advance(next(logs, first, false))
+	cursor.Advance(next(logs, second, false))
+	sub.Unsubscribe()
+
+	// Reorg the events out while offline, including a new one on the side chain
+	if err := sim.Fork(context.Background(), parent.Hash()); err != nil {
+		t.Fatalf("failed to fork: %v", err)
+	}
+	sim.Commit()
+	third := call()
+	sim.Commit()
+
+	// Resuming the stream must revert the stale events before delivering the new
+	logs, sub, err = contract.StreamLogs(&bind.StreamOpts{Cursor: cursor}, "Called")
+	if err != nil {
+		t.Fatalf("failed to resume log stream: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	cursor.Advance(next(logs, first, true))
+	cursor.Advance(next(logs, second, true))
+	if cursor.BlockNumber != parent.NumberU64()+1 || cursor.LogIndex != 0 {
+		t.Fatalf("cursor not rewound: have %+v", cursor)
+	}
+	cursor.Advance(next(logs, third, false))
+	if head := sim.Blockchain().CurrentBlock(); cursor.BlockHash != head.Hash() || cursor.LogIndex != 1 {
+		t.Fatalf("cursor mismatch: have %+v, want block %x, index 1", cursor, head.Hash())
+	}
+	// Reorg the new event out live, it must be delivered as removed
+	if err := sim.Fork(context.Background(), sim.Blockchain().CurrentBlock().ParentHash()); err != nil {
+		t.Fatalf("failed to fork: %v", err)
+	}
+	sim.Commit()
+	sim.Commit()
+	next(logs, third, true)
+
+	select {
+	case log := <-logs:
+		t.Fatalf("unexpected event: %+v", log)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -75,8 +75,9 @@ type tmplStruct struct {
 // tmplSource is language to template mapping containing all the supported
 // programming languages the package can generate to.
 var tmplSource = map[Lang]string{
-	LangGo:   tmplSourceGo,
-	LangJava: tmplSourceJava,
+	LangGo:         tmplSourceGo,
+	LangJava:       tmplSourceJava,
+	LangTypeScript: tmplSourceTypeScript,
 }
 
 // tmplSourceGo is the Go source template that the generated Go contract binding
@@ -340,6 +341,36 @@ var (
 			{{end}}
 		}
 
+		// Batch{{.Normalized.Name}} queues a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}} into a batch.
+		// The returned function retrieves the results once the batch was executed.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}Caller) Batch{{.Normalized.Name}}(batch *bind.BatchCaller, opts *bind.CallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type $structs}} {{end}}) func() ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+			var out []interface{}
+			result := _{{$contract.Type}}.contract.BatchCall(batch, opts, &out, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			return func() ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type $structs}};{{end}} },{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}},{{end}}{{end}} error) {
+				err := result()
+				{{if .Structured}}
+				outstruct := new(struct{ {{range .Normalized.Outputs}} {{.Name}} {{bindtype .Type $structs}}; {{end}} })
+				if err != nil {
+					return *outstruct, err
+				}
+				{{range $i, $t := .Normalized.Outputs}} 
+				outstruct.{{.Name}} = *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+				return *outstruct, err
+				{{else}}
+				if err != nil {
+					return {{range $i, $_ := .Normalized.Outputs}}*new({{bindtype .Type $structs}}), {{end}} err
+				}
+				{{range $i, $t := .Normalized.Outputs}}
+				out{{$i}} := *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+				
+				return {{range $i, $t := .Normalized.Outputs}}out{{$i}}, {{end}} err
+				{{end}}
+			}
+		}
+
 		// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
 		//
 		// Solidity: {{.Original.String}}
@@ -554,6 +585,49 @@ var (
 			}), nil
 		}
 
+		// Stream{{.Normalized.Name}} is a reorg aware, resumable log subscription operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+		// Events rolled back by a reorg are delivered again with Raw.Removed set.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}Filterer) Stream{{.Normalized.Name}}(opts *bind.StreamOpts, sink chan<- *{{$contract.Type}}{{.Normalized.Name}}{{range .Normalized.Inputs}}{{if .Indexed}}, {{.Name}} []{{bindtype .Type $structs}}{{end}}{{end}}) (event.Subscription, error) {
+			{{range .Normalized.Inputs}}
+			{{if .Indexed}}var {{.Name}}Rule []interface{}
+			for _, {{.Name}}Item := range {{.Name}} {
+				{{.Name}}Rule = append({{.Name}}Rule, {{.Name}}Item)
+			}{{end}}{{end}}
+
+			logs, sub, err := _{{$contract.Type}}.contract.StreamLogs(opts, "{{.Original.Name}}"{{range .Normalized.Inputs}}{{if .Indexed}}, {{.Name}}Rule{{end}}{{end}})
+			if err != nil {
+				return nil, err
+			}
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				defer sub.Unsubscribe()
+				for {
+					select {
+					case log := <-logs:
+						// New or removed log arrived, parse the event and forward to the user
+						event := new({{$contract.Type}}{{.Normalized.Name}})
+						if err := _{{$contract.Type}}.contract.UnpackLog(event, "{{.Original.Name}}", log); err != nil {
+							return err
+						}
+						event.Raw = log
+
+						select {
+						case sink <- event:
+						case err := <-sub.Err():
+							return err
+						case <-quit:
+							return nil
+						}
+					case err := <-sub.Err():
+						return err
+					case <-quit:
+						return nil
+					}
+				}
+			}), nil
+		}
+
 		// Parse{{.Normalized.Name}} is a log parse operation binding the contract event 0x{{printf "%x" .Original.ID}}.
 		//
 		// Solidity: {{.Original.String}}
@@ -706,3 +780,192 @@ import java.util.*;
 }
 {{end}}
 `
+
+// tmplSourceTypeScript is the TypeScript source template that the generated
+// TypeScript contract binding is based on. The bindings wrap ethers.js (v5).
+const tmplSourceTypeScript = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+import {
+	BigNumber,
+	BigNumberish,
+	BytesLike,
+	CallOverrides,
+	Contract,
+	ContractFactory,
+	ContractTransaction,
+	Event,
+	EventFilter,
+	Overrides,
+	PayableOverrides,
+	Signer,
+	providers,
+	utils,
+} from "ethers";
+
+{{$structs := .Structs}}
+{{range $structs}}
+	// {{.Name}} is an auto generated low-level TypeScript binding around an user-defined struct.
+	export interface {{.Name}} {
+	{{range $field := .Fields}}
+		{{$field.Name}}: {{$field.Type}};{{end}}
+	}
+
+	// {{.Name}}Input is the argument form of {{.Name}}, accepting any convertible values.
+	export interface {{.Name}}Input {
+	{{range $field := .Fields}}
+		{{$field.Name}}: {{bindinputtype $field.SolKind $structs}};{{end}}
+	}
+{{end}}
+
+{{range $contract := .Contracts}}
+	// {{.Type}}ABI is the input ABI used to generate the binding from.
+	export const {{.Type}}ABI = "{{.InputABI}}";
+
+	{{if $contract.FuncSigs}}
+		// {{.Type}}FuncSigs maps the 4-byte function signature to its string representation.
+		export const {{.Type}}FuncSigs: { [sig: string]: string } = {
+			{{range $strsig, $binsig := .FuncSigs}}"{{$binsig}}": "{{$strsig}}",
+			{{end}}
+		};
+	{{end}}
+
+	{{if .InputBin}}
+		// {{.Type}}Bin is the compiled bytecode used for deploying new contracts.
+		export const {{.Type}}Bin = "0x{{.InputBin}}";
+
+		// deploy{{.Type}} deploys a new Ubiq contract, binding an instance of {{.Type}} to it.
+		export async function deploy{{.Type}}(signer: Signer{{range .Constructor.Inputs}}, {{.Name}}: {{bindinputtype .Type $structs}}{{end}}{{if .Libraries}}, libraries: { {{range $pattern, $name := .Libraries}}{{decapitalise $name}}: string; {{end}}}{{end}}, overrides: {{if .Constructor.IsPayable}}PayableOverrides{{else}}Overrides{{end}} = {}): Promise<{{.Type}}> {
+			let bytecode = {{.Type}}Bin;
+			{{range $pattern, $name := .Libraries}}
+			bytecode = bytecode.split("__${{$pattern}}$__").join(libraries.{{decapitalise $name}}.toLowerCase().replace(/^0x/, ""));{{end}}
+
+			const factory = new ContractFactory({{.Type}}ABI, bytecode, signer);
+			const contract = await factory.deploy({{range .Constructor.Inputs}}{{.Name}}, {{end}}overrides);
+			await contract.deployTransaction.wait();
+			return new {{.Type}}(contract.address, signer);
+		}
+	{{end}}
+
+	{{range .Calls}}
+		{{if gt (len .Normalized.Outputs) 1}}
+		// {{$contract.Type}}{{capitalise .Normalized.Name}}Output is the output of a call to {{.Normalized.Name}}.
+		export type {{$contract.Type}}{{capitalise .Normalized.Name}}Output = [{{range $i, $_ := .Original.Outputs}}{{if $i}}, {{end}}{{bindtype .Type $structs}}{{end}}]{{if .Structured}} & { {{range .Original.Outputs}}{{.Name}}: {{bindtype .Type $structs}}; {{end}}}{{end}};
+		{{end}}
+	{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{capitalise .Normalized.Name}}Event represents a {{.Original.Name}} event raised by the {{$contract.Type}} contract.
+		export interface {{$contract.Type}}{{capitalise .Normalized.Name}}Event {
+		{{range .Normalized.Inputs}}
+			{{.Name}}: {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}};{{end}}
+			raw: providers.Log; // Blockchain specific contextual infos
+		}
+	{{end}}
+
+	// {{.Type}} is an auto generated TypeScript binding around an Ubiq contract.
+	export class {{.Type}} {
+		readonly address: string;  // Address where the contract is located at
+		readonly contract: Contract; // Generic contract wrapper for the low level calls
+
+		// Creates a new instance of {{.Type}}, bound to a specific deployed contract.
+		constructor(address: string, signerOrProvider: Signer | providers.Provider) {
+			this.address = address;
+			this.contract = new Contract(address, {{.Type}}ABI, signerOrProvider);
+		}
+
+		// connect returns a new instance of {{.Type}} operating through a different signer or provider.
+		connect(signerOrProvider: Signer | providers.Provider): {{.Type}} {
+			return new {{.Type}}(this.address, signerOrProvider);
+		}
+
+		{{range .Calls}}
+			// {{.Normalized.Name}} is a free data retrieval call binding the contract method 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			async {{.Normalized.Name}}({{range .Normalized.Inputs}}{{.Name}}: {{bindinputtype .Type $structs}}, {{end}}overrides: CallOverrides = {}): Promise<{{if gt (len .Normalized.Outputs) 1}}{{$contract.Type}}{{capitalise .Normalized.Name}}Output{{else if eq (len .Normalized.Outputs) 0}}void{{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}}{{end}}{{end}}> {
+				return this.contract["{{.Original.Sig}}"]({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+			}
+		{{end}}
+
+		{{range .Transacts}}
+			// {{.Normalized.Name}} is a paid mutator transaction binding the contract method 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			async {{.Normalized.Name}}({{range .Normalized.Inputs}}{{.Name}}: {{bindinputtype .Type $structs}}, {{end}}overrides: {{if .Original.IsPayable}}PayableOverrides{{else}}Overrides{{end}} = {}): Promise<ContractTransaction> {
+				return this.contract["{{.Original.Sig}}"]({{range .Normalized.Inputs}}{{.Name}}, {{end}}overrides);
+			}
+		{{end}}
+
+		{{if .Fallback}}
+			// fallback is a paid mutator transaction binding the contract fallback function.
+			//
+			// Solidity: {{.Fallback.Original.String}}
+			async fallback(calldata: BytesLike, overrides: PayableOverrides = {}): Promise<ContractTransaction> {
+				return this.contract.fallback!({ ...overrides, data: calldata });
+			}
+		{{end}}
+
+		{{if .Receive}}
+			// receive is a paid mutator transaction binding the contract receive function.
+			//
+			// Solidity: {{.Receive.Original.String}}
+			async receive(overrides: PayableOverrides = {}): Promise<ContractTransaction> {
+				return this.contract.fallback!(overrides);
+			}
+		{{end}}
+
+		{{range .Events}}
+			// filter{{capitalise .Normalized.Name}} creates a log filter for the contract event 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			filter{{capitalise .Normalized.Name}}({{$sep := ""}}{{range .Normalized.Inputs}}{{if .Indexed}}{{$sep}}{{.Name}}?: {{bindinputtype .Type $structs}} | {{bindinputtype .Type $structs}}[] | null{{$sep = ", "}}{{end}}{{end}}): EventFilter {
+				return this.contract.filters["{{.Original.Sig}}"]({{$sep = ""}}{{range .Normalized.Inputs}}{{if .Indexed}}{{$sep}}{{.Name}}{{$sep = ", "}}{{end}}{{end}});
+			}
+
+			// query{{capitalise .Normalized.Name}} is a free log retrieval operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			async query{{capitalise .Normalized.Name}}(filter: EventFilter = this.filter{{capitalise .Normalized.Name}}(), fromBlock?: providers.BlockTag, toBlock?: providers.BlockTag): Promise<{{$contract.Type}}{{capitalise .Normalized.Name}}Event[]> {
+				const events = await this.contract.queryFilter(filter, fromBlock, toBlock);
+				return events.map((event) => this.unpack{{capitalise .Normalized.Name}}(event.args!, event));
+			}
+
+			// watch{{capitalise .Normalized.Name}} is a free log subscription operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+			// The returned function tears down the subscription. Logs removed by a reorg are delivered with raw.removed set.
+			//
+			// Solidity: {{.Original.String}}
+			watch{{capitalise .Normalized.Name}}(listener: (event: {{$contract.Type}}{{capitalise .Normalized.Name}}Event) => void, filter: EventFilter = this.filter{{capitalise .Normalized.Name}}()): () => void {
+				const handler = (...args: any[]) => {
+					const event = args[args.length - 1] as Event;
+					listener(this.unpack{{capitalise .Normalized.Name}}(event.args!, event));
+				};
+				this.contract.on(filter, handler);
+				return () => {
+					this.contract.off(filter, handler);
+				};
+			}
+
+			// parse{{capitalise .Normalized.Name}} is a log parse operation binding the contract event 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			parse{{capitalise .Normalized.Name}}(log: providers.Log): {{$contract.Type}}{{capitalise .Normalized.Name}}Event {
+				const parsed = this.contract.interface.parseLog(log);
+				if (parsed.signature !== "{{.Original.Sig}}") {
+					throw new Error("event signature mismatch");
+				}
+				return this.unpack{{capitalise .Normalized.Name}}(parsed.args, log);
+			}
+
+			private unpack{{capitalise .Normalized.Name}}(args: utils.Result, raw: providers.Log): {{$contract.Type}}{{capitalise .Normalized.Name}}Event {
+				return {
+				{{range $i, $_ := .Normalized.Inputs}}
+					{{.Name}}: args[{{$i}}],{{end}}
+					raw: raw,
+				};
+			}
+		{{end}}
+	}
+{{end}}
+`
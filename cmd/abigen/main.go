@@ -91,7 +91,7 @@ var (
 	}
 	langFlag = cli.StringFlag{
 		Name:  "lang",
-		Usage: "Destination language for the bindings (go, java, objc)",
+		Usage: "Destination language for the bindings (go, java, objc, ts)",
 		Value: "go",
 	}
 	aliasFlag = cli.StringFlag{
@@ -123,9 +123,6 @@ func init() {
 
 func abigen(c *cli.Context) error {
 	utils.CheckExclusive(c, abiFlag, jsonFlag, solFlag, vyFlag) // Only one source can be selected.
-	if c.GlobalString(pkgFlag.Name) == "" {
-		utils.Fatalf("No destination package specified (--pkg)")
-	}
 	var lang bind.Lang
 	switch c.GlobalString(langFlag.Name) {
 	case "go":
@@ -135,9 +132,15 @@ func abigen(c *cli.Context) error {
 	case "objc":
 		lang = bind.LangObjC
 		utils.Fatalf("Objc binding generation is uncompleted")
+	case "ts":
+		lang = bind.LangTypeScript
 	default:
 		utils.Fatalf("Unsupported destination language \"%s\" (--lang)", c.GlobalString(langFlag.Name))
 	}
+	// TypeScript bindings are modules of their own, without a package
+	if lang != bind.LangTypeScript && c.GlobalString(pkgFlag.Name) == "" {
+		utils.Fatalf("No destination package specified (--pkg)")
+	}
 	// If the entire solidity code was specified, build and bind based on that
 	var (
 		abis    []string
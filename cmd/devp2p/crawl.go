@@ -17,6 +17,7 @@
 package main
 
 import (
+	"os"
 	"time"
 
 	"github.com/ubiq/go-ubiq/v7/log"
@@ -34,6 +35,8 @@ type crawler struct {
 
 	// settings
 	revalidateInterval time.Duration
+	history            *crawlHistory    // optional, records responding nodes
+	interrupt          <-chan os.Signal // optional, ends the crawl
 }
 
 type resolver interface {
@@ -89,6 +92,9 @@ loop:
 			}
 		case <-timeoutCh:
 			break loop
+		case <-c.interrupt:
+			log.Info("Crawl interrupted")
+			break loop
 		}
 	}
 
@@ -139,6 +145,9 @@ func (c *crawler) updateNode(n *enode.Node) {
 			node.FirstResponse = node.LastCheck
 		}
 		node.LastResponse = node.LastCheck
+		if c.history != nil {
+			c.history.seen(nn, node.LastCheck)
+		}
 	}
 
 	// Store/update node in output set.
@@ -0,0 +1,548 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/cmd/devp2p/internal/ethtest"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/core/forkid"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/eth/protocols/eth"
+	"github.com/ubiq/go-ubiq/v7/ethdb"
+	"github.com/ubiq/go-ubiq/v7/ethdb/leveldb"
+	"github.com/ubiq/go-ubiq/v7/log"
+	"github.com/ubiq/go-ubiq/v7/p2p"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+	"github.com/ubiq/go-ubiq/v7/p2p/rlpx"
+	"github.com/ubiq/go-ubiq/v7/params"
+	"github.com/ubiq/go-ubiq/v7/rlp"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	probeTimeout       = 15 * time.Second
+	probeQueueSize     = 1024
+	defaultReportRange = 24 * time.Hour
+)
+
+var historyKeyPrefix = []byte("n:") // history key prefix, followed by the node ID
+
+var (
+	monitorHTTPFlag = cli.StringFlag{
+		Name:  "http",
+		Usage: "Listening address of the HTTP report server",
+		Value: "127.0.0.1:8080",
+	}
+	monitorProbeIntervalFlag = cli.DurationFlag{
+		Name:  "probe-interval",
+		Usage: "Minimum time between RLPx connections to the same node",
+		Value: 6 * time.Hour,
+	}
+)
+
+// monitorNetwork runs the crawler until interrupted, recording the history of all
+// nodes in a database and serving reports about the Ubiq mainnet over HTTP.
+func monitorNetwork(ctx *cli.Context, disc resolver, it enode.Iterator) error {
+	db, err := leveldb.New(ctx.Args().First(), 16, 16, "", false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	history := newCrawlHistory(db, ctx.Duration(monitorProbeIntervalFlag.Name))
+	history.start(16)
+	defer history.close()
+
+	server := &http.Server{
+		Addr: ctx.String(monitorHTTPFlag.Name),
+		Handler: &historyServer{
+			history: history,
+			genesis: params.MainnetGenesisHash,
+			filter:  forkid.NewStaticFilter(params.MainnetChainConfig, params.MainnetGenesisHash),
+		},
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	go server.Serve(listener)
+	defer server.Close()
+	log.Info("Serving network report", "url", fmt.Sprintf("http://%v/report", listener.Addr()))
+
+	// Revalidate the nodes of the last day first, they are likely still online.
+	input := history.nodeSet(time.Now().Add(-defaultReportRange))
+	log.Info("Loaded node history", "recent", len(input))
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	c := newCrawler(input, disc, it)
+	c.revalidateInterval = 10 * time.Minute
+	c.history = history
+	c.interrupt = sigc
+	c.run(0)
+	return nil
+}
+
+// nodeHistory is the database entry of a crawled node.
+type nodeHistory struct {
+	N         *enode.Node `json:"record"`
+	FirstSeen time.Time   `json:"firstSeen"`
+	LastSeen  time.Time   `json:"lastSeen"`
+
+	// These are filled by connecting to the node through RLPx.
+	LastProbe     time.Time   `json:"lastProbe,omitempty"`
+	LastConnected time.Time   `json:"lastConnected,omitempty"`
+	ClientName    string      `json:"clientName,omitempty"`
+	Caps          []string    `json:"caps,omitempty"`
+	Status        *nodeStatus `json:"status,omitempty"`
+	LastError     string      `json:"lastError,omitempty"`
+}
+
+// nodeStatus is the eth protocol handshake of a node.
+type nodeStatus struct {
+	ProtocolVersion uint32        `json:"protocolVersion"`
+	NetworkID       uint64        `json:"networkID"`
+	TD              *hexutil.Big  `json:"td"`
+	Head            common.Hash   `json:"head"`
+	Genesis         common.Hash   `json:"genesis"`
+	ForkHash        hexutil.Bytes `json:"forkHash"`
+	ForkNext        uint64        `json:"forkNext"`
+}
+
+// crawlHistory stores the history of all nodes found by the crawler. Nodes which
+// respond to discovery are probed through RLPx in the background to learn their
+// client version and chain.
+type crawlHistory struct {
+	db            ethdb.KeyValueStore
+	key           *ecdsa.PrivateKey
+	probeInterval time.Duration
+	probe         func(*enode.Node, *ecdsa.PrivateKey) (*probeResult, error)
+
+	lock   sync.Mutex // protects read-modify-write of entries
+	queue  chan *enode.Node
+	queued map[enode.ID]struct{}
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newCrawlHistory(db ethdb.KeyValueStore, probeInterval time.Duration) *crawlHistory {
+	key, _ := crypto.GenerateKey()
+	return &crawlHistory{
+		db:            db,
+		key:           key,
+		probeInterval: probeInterval,
+		probe:         probeNode,
+		queue:         make(chan *enode.Node, probeQueueSize),
+		queued:        make(map[enode.ID]struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+// start launches the given number of RLPx probe workers.
+func (h *crawlHistory) start(workers int) {
+	h.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go h.probeLoop()
+	}
+}
+
+// close stops the probe workers.
+func (h *crawlHistory) close() {
+	close(h.closed)
+	h.wg.Wait()
+}
+
+// get loads the history of a node. It returns nil if the node is unknown.
+func (h *crawlHistory) get(id enode.ID) *nodeHistory {
+	blob, err := h.db.Get(historyKey(id))
+	if err != nil {
+		return nil
+	}
+	var entry nodeHistory
+	if err := json.Unmarshal(blob, &entry); err != nil {
+		log.Warn("Invalid node history entry", "id", id, "err", err)
+		return nil
+	}
+	return &entry
+}
+
+func (h *crawlHistory) put(entry *nodeHistory) {
+	blob, err := json.Marshal(entry)
+	if err != nil {
+		panic(err)
+	}
+	if err := h.db.Put(historyKey(entry.N.ID()), blob); err != nil {
+		log.Error("Failed to store node history", "id", entry.N.ID(), "err", err)
+	}
+}
+
+func historyKey(id enode.ID) []byte {
+	return append(append([]byte{}, historyKeyPrefix...), id[:]...)
+}
+
+// seen records that a node responded to discovery and schedules an RLPx probe if
+// the last one is too old.
+func (h *crawlHistory) seen(n *enode.Node, now time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	entry := h.get(n.ID())
+	if entry == nil {
+		entry = &nodeHistory{FirstSeen: now}
+	}
+	entry.N = n
+	entry.LastSeen = now
+	h.put(entry)
+
+	if n.TCP() == 0 || now.Sub(entry.LastProbe) < h.probeInterval {
+		return
+	}
+	if _, ok := h.queued[n.ID()]; ok {
+		return
+	}
+	select {
+	case h.queue <- n:
+		h.queued[n.ID()] = struct{}{}
+	default:
+		log.Debug("Probe queue full, skipping node", "id", n.ID())
+	}
+}
+
+func (h *crawlHistory) probeLoop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case n := <-h.queue:
+			result, err := h.probe(n, h.key)
+			h.update(n, result, err, truncNow())
+		case <-h.closed:
+			return
+		}
+	}
+}
+
+// update stores the result of an RLPx probe.
+func (h *crawlHistory) update(n *enode.Node, result *probeResult, err error, now time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.queued, n.ID())
+	entry := h.get(n.ID())
+	if entry == nil {
+		entry = &nodeHistory{N: n, FirstSeen: now, LastSeen: now}
+	}
+	entry.LastProbe = now
+	entry.LastError = ""
+	if err != nil {
+		entry.LastError = err.Error()
+	}
+	if result != nil {
+		entry.LastConnected = now
+		entry.ClientName = result.name
+		entry.Caps = result.caps
+		if result.status != nil {
+			entry.Status = result.status
+		}
+	}
+	log.Debug("Probed node", "id", n.ID(), "client", entry.ClientName, "err", err)
+	h.put(entry)
+}
+
+// iterate calls fn for all nodes in the database.
+func (h *crawlHistory) iterate(fn func(*nodeHistory)) {
+	it := h.db.NewIterator(historyKeyPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		var entry nodeHistory
+		if err := json.Unmarshal(it.Value(), &entry); err != nil {
+			log.Warn("Invalid node history entry", "key", fmt.Sprintf("%x", it.Key()), "err", err)
+			continue
+		}
+		fn(&entry)
+	}
+}
+
+// nodeSet returns the nodes seen after the given time, for revalidation by the crawler.
+func (h *crawlHistory) nodeSet(since time.Time) nodeSet {
+	ns := make(nodeSet)
+	h.iterate(func(entry *nodeHistory) {
+		if entry.LastSeen.Before(since) {
+			return
+		}
+		ns[entry.N.ID()] = nodeJSON{
+			Seq:           entry.N.Seq(),
+			N:             entry.N,
+			Score:         1,
+			FirstResponse: entry.FirstSeen,
+			LastResponse:  entry.LastSeen,
+			LastCheck:     entry.LastSeen,
+		}
+	})
+	return ns
+}
+
+// probeResult is the outcome of connecting to a node.
+type probeResult struct {
+	name   string
+	caps   []string
+	status *nodeStatus // nil if the node didn't send its eth status
+}
+
+// probeNode connects to a node through RLPx and reads its protocol handshake and
+// eth status. Clients send their status right after the handshake, so there is no
+// need to follow the chain ourselves.
+func probeNode(n *enode.Node, key *ecdsa.PrivateKey) (*probeResult, error) {
+	fd, err := net.DialTimeout("tcp", fmt.Sprintf("%v:%d", n.IP(), n.TCP()), probeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := rlpx.NewConn(fd, n.Pubkey())
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(probeTimeout))
+	if _, err := conn.Handshake(key); err != nil {
+		return nil, err
+	}
+	hello := &ethtest.Hello{
+		Version: 5,
+		Name:    "devp2p-crawler",
+		Caps: []p2p.Cap{
+			{Name: "eth", Version: 64},
+			{Name: "eth", Version: 65},
+			{Name: "eth", Version: 66},
+		},
+		ID: crypto.FromECDSAPub(&key.PublicKey)[1:],
+	}
+	payload, _ := rlp.EncodeToBytes(hello)
+	if _, err := conn.Write(uint64(hello.Code()), payload); err != nil {
+		return nil, err
+	}
+
+	var result *probeResult
+	for {
+		code, data, _, err := conn.Read()
+		if err != nil {
+			return result, err
+		}
+		switch {
+		case code == uint64((ethtest.Hello{}).Code()) && result == nil:
+			var h ethtest.Hello
+			if err := rlp.DecodeBytes(data, &h); err != nil {
+				return nil, fmt.Errorf("invalid handshake: %v", err)
+			}
+			result = &probeResult{name: h.Name}
+			for _, cap := range h.Caps {
+				result.caps = append(result.caps, cap.String())
+			}
+			if h.Version >= 5 {
+				conn.SetSnappy(true)
+			}
+		case code == uint64((ethtest.Disconnect{}).Code()):
+			var msg []p2p.DiscReason
+			if rlp.DecodeBytes(data, &msg); len(msg) == 0 {
+				return result, fmt.Errorf("invalid disconnect message")
+			}
+			return result, fmt.Errorf("disconnected: %v", msg[0])
+		case code == uint64((ethtest.Status{}).Code()) && result != nil:
+			var status eth.StatusPacket
+			if err := rlp.DecodeBytes(data, &status); err != nil {
+				return result, fmt.Errorf("invalid status: %v", err)
+			}
+			result.status = &nodeStatus{
+				ProtocolVersion: status.ProtocolVersion,
+				NetworkID:       status.NetworkID,
+				TD:              (*hexutil.Big)(status.TD),
+				Head:            status.Head,
+				Genesis:         status.Genesis,
+				ForkHash:        status.ForkID.Hash[:],
+				ForkNext:        status.ForkID.Next,
+			}
+			return result, nil
+		case code == uint64((ethtest.Ping{}).Code()):
+			payload, _ := rlp.EncodeToBytes([]interface{}{})
+			conn.Write(uint64((ethtest.Pong{}).Code()), payload)
+		case result == nil:
+			return nil, fmt.Errorf("invalid message code %d, expected handshake", code)
+		}
+	}
+}
+
+// networkReport summarizes the composition of a network.
+type networkReport struct {
+	Time          time.Time     `json:"time"`
+	Range         string        `json:"range"`
+	Genesis       common.Hash   `json:"genesis"`
+	Nodes         int           `json:"nodes"`
+	Probed        int           `json:"probed"`
+	OtherNetworks int           `json:"otherNetworks"`
+	Clients       []reportCount `json:"clients"`
+	Versions      []reportCount `json:"versions"`
+	ForkIDs       []forkCount   `json:"forkIDs"`
+}
+
+type reportCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type forkCount struct {
+	Hash       hexutil.Bytes `json:"hash"`
+	Next       uint64        `json:"next"`
+	Compatible bool          `json:"compatible"`
+	Count      int           `json:"count"`
+}
+
+// report creates a network report of the nodes seen in the given time range
+// before now. Clients, versions and fork IDs are only counted for nodes running
+// the chain with the given genesis.
+func (h *crawlHistory) report(now time.Time, rng time.Duration, genesis common.Hash, filter forkid.Filter) *networkReport {
+	var (
+		r        = &networkReport{Time: now, Range: rng.String(), Genesis: genesis}
+		clients  = make(map[string]int)
+		versions = make(map[string]int)
+		forks    = make(map[forkid.ID]int)
+	)
+	h.iterate(func(entry *nodeHistory) {
+		if now.Sub(entry.LastSeen) > rng {
+			return
+		}
+		r.Nodes++
+		if entry.Status == nil {
+			return
+		}
+		if entry.Status.Genesis != genesis {
+			r.OtherNetworks++
+			return
+		}
+		r.Probed++
+		name, version := splitClientName(entry.ClientName)
+		clients[name]++
+		versions[name+"/"+version]++
+
+		var id forkid.ID
+		copy(id.Hash[:], entry.Status.ForkHash)
+		id.Next = entry.Status.ForkNext
+		forks[id]++
+	})
+	r.Clients = sortedCounts(clients)
+	r.Versions = sortedCounts(versions)
+	r.ForkIDs = make([]forkCount, 0, len(forks))
+	for id, count := range forks {
+		r.ForkIDs = append(r.ForkIDs, forkCount{
+			Hash:       common.CopyBytes(id.Hash[:]),
+			Next:       id.Next,
+			Compatible: filter == nil || filter(id) == nil,
+			Count:      count,
+		})
+	}
+	sort.Slice(r.ForkIDs, func(i, j int) bool {
+		if r.ForkIDs[i].Count != r.ForkIDs[j].Count {
+			return r.ForkIDs[i].Count > r.ForkIDs[j].Count
+		}
+		return r.ForkIDs[i].Hash.String() < r.ForkIDs[j].Hash.String()
+	})
+	return r
+}
+
+// splitClientName splits a client name like "Gubiq/v7.0.0-stable/linux-amd64/go1.17"
+// into name and version.
+func splitClientName(s string) (name, version string) {
+	parts := strings.Split(s, "/")
+	name = parts[0]
+	if name == "" {
+		name = "unknown"
+	}
+	if len(parts) > 1 {
+		version = parts[1]
+	}
+	return name, version
+}
+
+// sortedCounts returns the entries of a counter map, most common first.
+func sortedCounts(m map[string]int) []reportCount {
+	counts := make([]reportCount, 0, len(m))
+	for name, count := range m {
+		counts = append(counts, reportCount{name, count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// historyServer serves the crawl history over HTTP.
+//
+//	/report    -- network report, the time range can be set with ?range=<duration>
+//	/nodes     -- all nodes seen in the time range
+type historyServer struct {
+	history *crawlHistory
+	genesis common.Hash
+	filter  forkid.Filter
+}
+
+func (s *historyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rng := defaultReportRange
+	if v := r.URL.Query().Get("range"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid range", http.StatusBadRequest)
+			return
+		}
+		rng = d
+	}
+	now := time.Now().UTC()
+
+	var result interface{}
+	switch r.URL.Path {
+	case "/report":
+		result = s.history.report(now, rng, s.genesis, s.filter)
+	case "/nodes":
+		nodes := []*nodeHistory{}
+		s.history.iterate(func(entry *nodeHistory) {
+			if now.Sub(entry.LastSeen) <= rng {
+				nodes = append(nodes, entry)
+			}
+		})
+		result = nodes
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", jsonIndent)
+	enc.Encode(result)
+}
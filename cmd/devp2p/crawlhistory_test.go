@@ -0,0 +1,234 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/core/forkid"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/eth/protocols/eth"
+	"github.com/ubiq/go-ubiq/v7/ethdb/memorydb"
+	"github.com/ubiq/go-ubiq/v7/p2p"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+	"github.com/ubiq/go-ubiq/v7/p2p/enr"
+	"github.com/ubiq/go-ubiq/v7/params"
+)
+
+// This test checks that probing a node yields its client name and eth status.
+func TestProbeNode(t *testing.T) {
+	status := &eth.StatusPacket{
+		ProtocolVersion: eth.ETH66,
+		NetworkID:       params.MainnetChainConfig.ChainID.Uint64(),
+		TD:              big.NewInt(1000),
+		Head:            common.HexToHash("0x01"),
+		Genesis:         params.MainnetGenesisHash,
+		ForkID:          forkid.ID{Hash: [4]byte{1, 2, 3, 4}, Next: 1981337},
+	}
+	key, _ := crypto.GenerateKey()
+	srv := &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		MaxPeers:    10,
+		Name:        "Gubiq/v7.1.0-stable/linux-amd64/go1.17",
+		ListenAddr:  "127.0.0.1:0",
+		NoDiscovery: true,
+		NoDial:      true,
+		Protocols: []p2p.Protocol{{
+			Name:    "eth",
+			Version: eth.ETH66,
+			Length:  17,
+			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+				if err := p2p.Send(rw, eth.StatusMsg, status); err != nil {
+					return err
+				}
+				for {
+					msg, err := rw.ReadMsg()
+					if err != nil {
+						return err
+					}
+					msg.Discard()
+				}
+			},
+		}},
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	probeKey, _ := crypto.GenerateKey()
+	result, err := probeNode(srv.Self(), probeKey)
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if result.name != srv.Name {
+		t.Errorf("wrong client name %q, want %q", result.name, srv.Name)
+	}
+	if !reflect.DeepEqual(result.caps, []string{"eth/66"}) {
+		t.Errorf("wrong caps %v", result.caps)
+	}
+	want := &nodeStatus{
+		ProtocolVersion: status.ProtocolVersion,
+		NetworkID:       status.NetworkID,
+		TD:              (*hexutil.Big)(status.TD),
+		Head:            status.Head,
+		Genesis:         status.Genesis,
+		ForkHash:        status.ForkID.Hash[:],
+		ForkNext:        status.ForkID.Next,
+	}
+	if !reflect.DeepEqual(result.status, want) {
+		t.Errorf("wrong status %+v, want %+v", result.status, want)
+	}
+}
+
+// This test checks that the crawl history is recorded and reported correctly.
+func TestCrawlHistoryReport(t *testing.T) {
+	var (
+		history = newCrawlHistory(memorydb.New(), time.Hour)
+		start   = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		nodes   = make([]*enode.Node, 5)
+		genesis = params.MainnetGenesisHash
+		oldFork = forkid.ID{Hash: [4]byte{1}, Next: 1981337}
+		newFork = forkid.ID{Hash: [4]byte{2}}
+	)
+	for i := range nodes {
+		nodes[i] = testHistoryNode(t, i)
+		history.seen(nodes[i], start)
+	}
+	if len(history.queue) != len(nodes) {
+		t.Fatalf("wrong number of queued probes %d, want %d", len(history.queue), len(nodes))
+	}
+	probe := func(name string, genesis common.Hash, fork forkid.ID) *probeResult {
+		return &probeResult{name: name, status: &nodeStatus{
+			TD:       (*hexutil.Big)(big.NewInt(1)),
+			Genesis:  genesis,
+			ForkHash: common.CopyBytes(fork.Hash[:]),
+			ForkNext: fork.Next,
+		}}
+	}
+	history.update(nodes[0], probe("Gubiq/v7.0.0/linux", genesis, oldFork), nil, start)
+	history.update(nodes[1], probe("Gubiq/v7.1.0/linux", genesis, newFork), nil, start)
+	history.update(nodes[2], probe("Gubiq/v7.1.0/darwin", genesis, newFork), nil, start)
+	history.update(nodes[3], probe("Geth/v1.10.0/linux", common.Hash{1}, newFork), nil, start)
+	history.update(nodes[4], nil, errors.New("connection refused"), start)
+
+	// Seeing the nodes again shortly after doesn't cause another probe.
+	for len(history.queue) > 0 {
+		<-history.queue
+	}
+	history.seen(nodes[0], start.Add(time.Minute))
+	if len(history.queue) != 0 {
+		t.Fatal("node probed again before probe interval")
+	}
+	entry := history.get(nodes[0].ID())
+	if !entry.FirstSeen.Equal(start) || !entry.LastSeen.Equal(start.Add(time.Minute)) || entry.ClientName != "Gubiq/v7.0.0/linux" {
+		t.Fatalf("wrong history entry %+v", entry)
+	}
+	if entry := history.get(nodes[4].ID()); entry.LastError != "connection refused" || entry.Status != nil {
+		t.Fatalf("wrong history entry for failed probe %+v", entry)
+	}
+
+	filter := func(id forkid.ID) error {
+		if id != newFork {
+			return forkid.ErrLocalIncompatibleOrStale
+		}
+		return nil
+	}
+	report := history.report(start.Add(time.Hour), 24*time.Hour, genesis, filter)
+	want := &networkReport{
+		Time:          start.Add(time.Hour),
+		Range:         "24h0m0s",
+		Genesis:       genesis,
+		Nodes:         5,
+		Probed:        3,
+		OtherNetworks: 1,
+		Clients:       []reportCount{{"Gubiq", 3}},
+		Versions:      []reportCount{{"Gubiq/v7.1.0", 2}, {"Gubiq/v7.0.0", 1}},
+		ForkIDs: []forkCount{
+			{Hash: newFork.Hash[:], Next: 0, Compatible: true, Count: 2},
+			{Hash: oldFork.Hash[:], Next: 1981337, Compatible: false, Count: 1},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("wrong report:\nhave %+v\nwant %+v", report, want)
+	}
+
+	// Nodes outside of the time range are ignored.
+	report = history.report(start.Add(24*time.Hour+30*time.Second), 24*time.Hour, genesis, filter)
+	if report.Nodes != 1 || report.Probed != 1 {
+		t.Fatalf("wrong node count %d/%d in report after expiry", report.Nodes, report.Probed)
+	}
+	if ns := history.nodeSet(start.Add(time.Second)); len(ns) != 1 || ns[nodes[0].ID()].N == nil {
+		t.Fatalf("wrong revalidation set %v", ns)
+	}
+}
+
+func TestHistoryServer(t *testing.T) {
+	history := newCrawlHistory(memorydb.New(), time.Hour)
+	history.seen(testHistoryNode(t, 0), truncNow())
+	srv := &historyServer{history: history, genesis: params.MainnetGenesisHash}
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest("GET", "/report?range=1h", nil))
+	var report networkReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if report.Nodes != 1 || report.Range != "1h0m0s" {
+		t.Fatalf("wrong report %+v", report)
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest("GET", "/nodes", nil))
+	var nodes []*nodeHistory
+	if err := json.Unmarshal(w.Body.Bytes(), &nodes); err != nil {
+		t.Fatalf("invalid node list: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("wrong number of nodes %d in node list", len(nodes))
+	}
+
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest("GET", "/report?range=foo", nil))
+	if w.Code != 400 {
+		t.Fatalf("wrong status code %d for invalid range", w.Code)
+	}
+}
+
+func testHistoryNode(t *testing.T, i int) *enode.Node {
+	var r enr.Record
+	r.Set(enr.IP(net.IP{127, 0, 0, byte(i)}))
+	r.Set(enr.TCP(30303))
+	key, _ := crypto.GenerateKey()
+	if err := enode.SignV4(&r, key); err != nil {
+		t.Fatal(err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
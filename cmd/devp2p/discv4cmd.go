@@ -41,6 +41,7 @@ var (
 			discv4ResolveCommand,
 			discv4ResolveJSONCommand,
 			discv4CrawlCommand,
+			discv4MonitorCommand,
 			discv4TestCommand,
 		},
 	}
@@ -76,6 +77,13 @@ var (
 		Action: discv4Crawl,
 		Flags:  []cli.Flag{bootnodesFlag, crawlTimeoutFlag},
 	}
+	discv4MonitorCommand = cli.Command{
+		Name:      "monitor",
+		Usage:     "Continuously crawls the DHT, recording node history and serving a network report",
+		ArgsUsage: "<database-directory>",
+		Action:    discv4Monitor,
+		Flags:     []cli.Flag{bootnodesFlag, monitorHTTPFlag, monitorProbeIntervalFlag},
+	}
 	discv4TestCommand = cli.Command{
 		Name:   "test",
 		Usage:  "Runs tests against a node",
@@ -203,6 +211,15 @@ func discv4Crawl(ctx *cli.Context) error {
 	return nil
 }
 
+func discv4Monitor(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return fmt.Errorf("need database directory as argument")
+	}
+	disc := startV4(ctx)
+	defer disc.Close()
+	return monitorNetwork(ctx, disc, disc.RandomNodes())
+}
+
 // discv4Test runs the protocol test suite.
 func discv4Test(ctx *cli.Context) error {
 	// Configure test package globals.
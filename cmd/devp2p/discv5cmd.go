@@ -34,6 +34,7 @@ var (
 			discv5PingCommand,
 			discv5ResolveCommand,
 			discv5CrawlCommand,
+			discv5MonitorCommand,
 			discv5TestCommand,
 			discv5ListenCommand,
 		},
@@ -55,6 +56,13 @@ var (
 		Action: discv5Crawl,
 		Flags:  []cli.Flag{bootnodesFlag, crawlTimeoutFlag},
 	}
+	discv5MonitorCommand = cli.Command{
+		Name:      "monitor",
+		Usage:     "Continuously crawls the DHT, recording node history and serving a network report",
+		ArgsUsage: "<database-directory>",
+		Action:    discv5Monitor,
+		Flags:     []cli.Flag{bootnodesFlag, monitorHTTPFlag, monitorProbeIntervalFlag},
+	}
 	discv5TestCommand = cli.Command{
 		Name:   "test",
 		Usage:  "Runs protocol tests against a node",
@@ -116,6 +124,15 @@ func discv5Crawl(ctx *cli.Context) error {
 	return nil
 }
 
+func discv5Monitor(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return fmt.Errorf("need database directory as argument")
+	}
+	disc := startV5(ctx)
+	defer disc.Close()
+	return monitorNetwork(ctx, disc, disc.RandomNodes())
+}
+
 // discv5Test runs the protocol test suite.
 func discv5Test(ctx *cli.Context) error {
 	suite := &v5test.Suite{
@@ -0,0 +1,719 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/log"
+	"github.com/ubiq/go-ubiq/v7/p2p/dnsdisc"
+	"golang.org/x/net/dns/dnsmessage"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	// DNS UPDATE messages are sent over TCP, which limits them to 64k. Keep change
+	// batches well below that to leave room for the question and TSIG records.
+	rfc2136ChangeSizeLimit = 32000
+	rfc2136Timeout         = 30 * time.Second
+
+	dnsOpCodeUpdate dnsmessage.OpCode = 5   // RFC 2136
+	dnsTypeTSIG     dnsmessage.Type   = 250 // RFC 8945
+	tsigFudge                         = 300 // permitted clock skew in seconds
+)
+
+var (
+	rfc2136ServerFlag = cli.StringFlag{
+		Name:  "server",
+		Usage: "Address of the authoritative DNS server (host:port)",
+	}
+	rfc2136ZoneFlag = cli.StringFlag{
+		Name:  "zone",
+		Usage: "DNS zone containing the tree (defaults to the tree domain)",
+	}
+	rfc2136TSIGKeyFlag = cli.StringFlag{
+		Name:   "tsig-key",
+		Usage:  "Name of the TSIG key authorizing updates and zone transfers",
+		EnvVar: "RFC2136_TSIG_KEY",
+	}
+	rfc2136TSIGSecretFlag = cli.StringFlag{
+		Name:   "tsig-secret",
+		Usage:  "Base64 encoded secret of the TSIG key",
+		EnvVar: "RFC2136_TSIG_SECRET",
+	}
+	rfc2136TSIGAlgorithmFlag = cli.StringFlag{
+		Name:  "tsig-algorithm",
+		Usage: "TSIG algorithm (hmac-sha1, hmac-sha256, hmac-sha512)",
+		Value: "hmac-sha256",
+	}
+)
+
+// rfc2136Client deploys trees to an authoritative DNS server using DNS UPDATE
+// messages. The existing records are read through a zone transfer.
+type rfc2136Client struct {
+	server string
+	zone   string
+	tsig   *tsigKey // nil if messages aren't signed
+}
+
+// rfc2136Change is a change of a TXT record set.
+type rfc2136Change struct {
+	action string // "CREATE", "UPDATE" or "DELETE"
+	name   string
+	ttl    uint32
+	value  string
+}
+
+// size returns the encoded size of the records making up the change in an
+// UPDATE message, without name compression.
+func (ch *rfc2136Change) size() int {
+	// Every record consists of the name in wire format, the type, class, TTL and
+	// RDLENGTH fields, followed by its RDATA.
+	var (
+		header = len(fqdn(ch.name)) + 1 + 10
+		size   int
+	)
+	if ch.action == "UPDATE" || ch.action == "DELETE" {
+		size += header
+	}
+	if ch.action == "CREATE" || ch.action == "UPDATE" {
+		size += header
+		for _, str := range txtStrings(ch.value) {
+			size += 1 + len(str)
+		}
+	}
+	return size
+}
+
+// newRFC2136Client sets up a DNS UPDATE client from command line flags.
+func newRFC2136Client(ctx *cli.Context, domain string) *rfc2136Client {
+	c := &rfc2136Client{
+		server: ctx.String(rfc2136ServerFlag.Name),
+		zone:   ctx.String(rfc2136ZoneFlag.Name),
+	}
+	if c.server == "" {
+		exit(fmt.Errorf("need DNS server address to proceed"))
+	}
+	if _, _, err := net.SplitHostPort(c.server); err != nil {
+		c.server = net.JoinHostPort(c.server, "53")
+	}
+	if c.zone == "" {
+		c.zone = domain
+	}
+	if !isSubdomain(domain, c.zone) {
+		exit(fmt.Errorf("domain %s is not part of zone %s", domain, c.zone))
+	}
+	if name := ctx.String(rfc2136TSIGKeyFlag.Name); name != "" {
+		key, err := newTSIGKey(name, ctx.String(rfc2136TSIGAlgorithmFlag.Name), ctx.String(rfc2136TSIGSecretFlag.Name))
+		if err != nil {
+			exit(err)
+		}
+		c.tsig = key
+	} else {
+		log.Warn("No TSIG key configured, sending unsigned updates")
+	}
+	return c
+}
+
+// deploy uploads the given tree to the DNS server.
+func (c *rfc2136Client) deploy(name string, t *dnsdisc.Tree) error {
+	existing, err := c.collectRecords(name)
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Found %d TXT records", len(existing)))
+	records := t.ToTXT(name)
+	changes := c.computeChanges(name, records, existing)
+	return c.submitChanges(changes)
+}
+
+// computeChanges creates DNS changes for the given set of DNS discovery records.
+// The 'existing' arg is the set of records that already exist on the server.
+func (c *rfc2136Client) computeChanges(name string, records map[string]string, existing map[string]recordSet) []rfc2136Change {
+	// Convert all names to lowercase.
+	lrecords := make(map[string]string, len(records))
+	for name, r := range records {
+		lrecords[strings.ToLower(name)] = r
+	}
+	records = lrecords
+
+	var changes []rfc2136Change
+	for path, newValue := range records {
+		prev, exists := existing[path]
+
+		// Assign TTL.
+		ttl := uint32(rootTTL)
+		if path != name {
+			ttl = uint32(treeNodeTTL)
+		}
+
+		if !exists {
+			// Entry is unknown, push a new one
+			log.Info(fmt.Sprintf("Creating %s = %q", path, newValue))
+			changes = append(changes, rfc2136Change{"CREATE", path, ttl, newValue})
+		} else if len(prev.values) != 1 || prev.values[0] != newValue || prev.ttl != int64(ttl) {
+			// Entry already exists, only change its content.
+			log.Info(fmt.Sprintf("Updating %s from %q to %q", path, strings.Join(prev.values, ""), newValue))
+			changes = append(changes, rfc2136Change{"UPDATE", path, ttl, newValue})
+		} else {
+			log.Debug(fmt.Sprintf("Skipping %s = %q", path, newValue))
+		}
+	}
+
+	// Iterate over the old records and delete anything stale.
+	for path, set := range existing {
+		if _, ok := records[path]; ok {
+			continue
+		}
+		log.Info(fmt.Sprintf("Deleting %s = %q", path, strings.Join(set.values, "")))
+		changes = append(changes, rfc2136Change{action: "DELETE", name: path})
+	}
+
+	// Ensure changes are in leaf-added -> root-changed -> leaf-deleted order.
+	score := map[string]int{"CREATE": 1, "UPDATE": 2, "DELETE": 3}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].action == changes[j].action {
+			return changes[i].name < changes[j].name
+		}
+		return score[changes[i].action] < score[changes[j].action]
+	})
+	return changes
+}
+
+// submitChanges sends the given changes to the DNS server, split into as many
+// UPDATE messages as needed. Every message is applied atomically by the server.
+func (c *rfc2136Client) submitChanges(changes []rfc2136Change) error {
+	if len(changes) == 0 {
+		log.Info("No DNS changes needed")
+		return nil
+	}
+	batches := batchChanges(changes)
+	for i, batch := range batches {
+		log.Info(fmt.Sprintf("Submitting %d changes to %s (%d/%d)", len(batch), c.server, i+1, len(batches)))
+		if err := c.update(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchChanges splits the given changes into batches, each of which fits into a
+// single UPDATE message.
+func batchChanges(changes []rfc2136Change) [][]rfc2136Change {
+	var (
+		batches   [][]rfc2136Change
+		batchSize int
+	)
+	for _, ch := range changes {
+		size := ch.size()
+		if len(batches) == 0 || batchSize+size > rfc2136ChangeSizeLimit {
+			batches = append(batches, nil)
+			batchSize = 0
+		}
+		batches[len(batches)-1] = append(batches[len(batches)-1], ch)
+		batchSize += size
+	}
+	return batches
+}
+
+// update sends a single UPDATE message and waits for the response.
+func (c *rfc2136Client) update(changes []rfc2136Change) error {
+	zone, err := dnsmessage.NewName(fqdn(c.zone))
+	if err != nil {
+		return err
+	}
+	msg := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: randomDNSID(), OpCode: dnsOpCodeUpdate})
+	msg.EnableCompression()
+	msg.StartQuestions()
+	msg.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET})
+
+	// The prerequisite section is empty, changes go into the update section.
+	msg.StartAnswers()
+	msg.StartAuthorities()
+	for _, ch := range changes {
+		if err := appendChange(&msg, ch); err != nil {
+			return err
+		}
+	}
+	query, err := msg.Finish()
+	if err != nil {
+		return err
+	}
+	return c.exchange(query, func(resp dnsmessage.Header, p *dnsmessage.Parser) (bool, error) {
+		if resp.RCode != dnsmessage.RCodeSuccess {
+			return true, fmt.Errorf("DNS update failed: %v", resp.RCode)
+		}
+		return true, nil
+	})
+}
+
+// appendChange adds the records making up a change to the update section of an
+// UPDATE message.
+func appendChange(msg *dnsmessage.Builder, ch rfc2136Change) error {
+	name, err := dnsmessage.NewName(fqdn(ch.name))
+	if err != nil {
+		return err
+	}
+	if ch.action == "UPDATE" || ch.action == "DELETE" {
+		// Delete the RRset, signalled by class ANY and empty RDATA.
+		h := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassANY}
+		if err := msg.UnknownResource(h, dnsmessage.UnknownResource{Type: dnsmessage.TypeTXT}); err != nil {
+			return err
+		}
+	}
+	if ch.action == "CREATE" || ch.action == "UPDATE" {
+		h := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: ch.ttl}
+		if err := msg.TXTResource(h, dnsmessage.TXTResource{TXT: txtStrings(ch.value)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectRecords collects all TXT records below the given name through a zone
+// transfer. Unlike the records collected from Route53, the values are unquoted.
+func (c *rfc2136Client) collectRecords(name string) (map[string]recordSet, error) {
+	log.Info("Loading existing TXT records", "name", name, "zone", c.zone, "server", c.server)
+	zone, err := dnsmessage.NewName(fqdn(c.zone))
+	if err != nil {
+		return nil, err
+	}
+	msg := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: randomDNSID()})
+	msg.StartQuestions()
+	msg.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeAXFR, Class: dnsmessage.ClassINET})
+	query, err := msg.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	// The transfer is framed by the SOA record of the zone.
+	var (
+		existing = make(map[string]recordSet)
+		soas     int
+	)
+	err = c.exchange(query, func(resp dnsmessage.Header, p *dnsmessage.Parser) (bool, error) {
+		if resp.RCode != dnsmessage.RCodeSuccess {
+			return true, fmt.Errorf("zone transfer failed: %v", resp.RCode)
+		}
+		for {
+			h, err := p.AnswerHeader()
+			if err == dnsmessage.ErrSectionDone {
+				return soas >= 2, nil
+			} else if err != nil {
+				return true, err
+			}
+			rname := strings.ToLower(strings.TrimSuffix(h.Name.String(), "."))
+			switch {
+			case h.Type == dnsmessage.TypeSOA:
+				soas++
+				err = p.SkipAnswer()
+			case h.Type == dnsmessage.TypeTXT && isSubdomain(rname, name):
+				var txt dnsmessage.TXTResource
+				if txt, err = p.TXTResource(); err == nil {
+					set := existing[rname]
+					set.ttl = int64(h.TTL)
+					set.values = append(set.values, strings.Join(txt.TXT, ""))
+					existing[rname] = set
+				}
+			default:
+				err = p.SkipAnswer()
+			}
+			if err != nil {
+				return true, err
+			}
+		}
+	})
+	return existing, err
+}
+
+// exchange sends a query to the server and passes all responses to the handler,
+// until it reports being done. Signatures of the responses are verified if the
+// client has a TSIG key.
+func (c *rfc2136Client) exchange(query []byte, handle func(dnsmessage.Header, *dnsmessage.Parser) (bool, error)) error {
+	conn, err := net.DialTimeout("tcp", c.server, rfc2136Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(rfc2136Timeout))
+
+	var verifier *tsigVerifier
+	if c.tsig != nil {
+		var mac []byte
+		query, mac = c.tsig.sign(query, nil, false, time.Now())
+		verifier = &tsigVerifier{key: c.tsig, prior: mac}
+	}
+	if err := writeDNSMessage(conn, query); err != nil {
+		return err
+	}
+	id := binary.BigEndian.Uint16(query)
+	for {
+		resp, err := readDNSMessage(conn)
+		if err != nil {
+			return err
+		}
+		if verifier != nil {
+			if resp, err = verifier.verify(resp, time.Now()); err != nil {
+				return err
+			}
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(resp)
+		if err != nil {
+			return err
+		}
+		if h.ID != id {
+			return fmt.Errorf("DNS response ID mismatch: have %d, want %d", h.ID, id)
+		}
+		if err := p.SkipAllQuestions(); err != nil {
+			return err
+		}
+		done, err := handle(h, &p)
+		if err != nil {
+			return err
+		}
+		if !done {
+			continue
+		}
+		if verifier != nil && len(verifier.unsigned) > 0 {
+			return errors.New("last DNS response not signed")
+		}
+		return nil
+	}
+}
+
+// writeDNSMessage sends a length-prefixed DNS message over a TCP connection.
+func writeDNSMessage(w io.Writer, msg []byte) error {
+	if len(msg) > 0xffff {
+		return fmt.Errorf("DNS message too large (%d bytes)", len(msg))
+	}
+	frame := make([]byte, 2, 2+len(msg))
+	binary.BigEndian.PutUint16(frame, uint16(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+// readDNSMessage reads a length-prefixed DNS message from a TCP connection.
+func readDNSMessage(r io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func randomDNSID() uint16 {
+	var id [2]byte
+	crand.Read(id[:])
+	return binary.BigEndian.Uint16(id[:])
+}
+
+// fqdn returns the fully qualified form of a domain name.
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+// txtStrings splits a TXT record value into character strings of up to 255 bytes.
+func txtStrings(value string) []string {
+	var strs []string
+	for len(value) > 255 {
+		strs = append(strs, value[:255])
+		value = value[255:]
+	}
+	return append(strs, value)
+}
+
+// tsigKey is a shared secret authenticating DNS messages (RFC 8945).
+type tsigKey struct {
+	name      []byte // key name in canonical wire format
+	algorithm []byte // algorithm name in canonical wire format
+	hash      func() hash.Hash
+	secret    []byte
+}
+
+// tsigRecord is the content of a TSIG resource record.
+type tsigRecord struct {
+	algorithm  []byte
+	timeSigned uint64
+	fudge      uint16
+	mac        []byte
+	originalID uint16
+	err        uint16
+}
+
+// newTSIGKey creates a TSIG key from its name, algorithm and base64 encoded secret.
+func newTSIGKey(name, algorithm, secret string) (*tsigKey, error) {
+	var key tsigKey
+	switch strings.ToLower(strings.TrimSuffix(algorithm, ".")) {
+	case "hmac-sha1":
+		key.hash = sha1.New
+	case "hmac-sha256":
+		key.hash = sha256.New
+	case "hmac-sha512":
+		key.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported TSIG algorithm %q", algorithm)
+	}
+	var err error
+	if key.secret, err = base64.StdEncoding.DecodeString(secret); err != nil || len(key.secret) == 0 {
+		return nil, fmt.Errorf("invalid TSIG secret: %v", err)
+	}
+	if key.name, err = wireName(name); err != nil {
+		return nil, fmt.Errorf("invalid TSIG key name: %v", err)
+	}
+	key.algorithm, _ = wireName(algorithm)
+	return &key, nil
+}
+
+// wireName encodes a domain name in lowercase, uncompressed wire format.
+func wireName(name string) ([]byte, error) {
+	var enc []byte
+	for _, label := range strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, fmt.Errorf("invalid label in %q", name)
+		}
+		enc = append(enc, byte(len(label)))
+		enc = append(enc, label...)
+	}
+	return append(enc, 0), nil
+}
+
+// variables returns the TSIG variables covered by the MAC. Messages following the
+// first one of a multi-message response only cover the timers.
+func (k *tsigKey) variables(rec *tsigRecord, timersOnly bool) []byte {
+	var b []byte
+	if !timersOnly {
+		b = append(b, k.name...)
+		b = append(b, 0, 255, 0, 0, 0, 0) // class ANY, TTL 0
+		b = append(b, k.algorithm...)
+	}
+	b = append(b, byte(rec.timeSigned>>40), byte(rec.timeSigned>>32))
+	b = appendUint32(b, uint32(rec.timeSigned))
+	b = appendUint16(b, rec.fudge)
+	if !timersOnly {
+		b = appendUint16(b, rec.err)
+		b = append(b, 0, 0) // no other data
+	}
+	return b
+}
+
+// mac computes the MAC of a message without its TSIG record.
+func (k *tsigKey) mac(msg, prior []byte, rec *tsigRecord, timersOnly bool) []byte {
+	h := hmac.New(k.hash, k.secret)
+	if prior != nil {
+		h.Write([]byte{byte(len(prior) >> 8), byte(len(prior))})
+		h.Write(prior)
+	}
+	h.Write(msg)
+	h.Write(k.variables(rec, timersOnly))
+	return h.Sum(nil)
+}
+
+// sign appends a TSIG record to the message, returning the signed message and its
+// MAC. Responses are signed with the MAC of the request or previous response as
+// prior.
+func (k *tsigKey) sign(msg, prior []byte, timersOnly bool, now time.Time) ([]byte, []byte) {
+	rec := &tsigRecord{
+		algorithm:  k.algorithm,
+		timeSigned: uint64(now.Unix()),
+		fudge:      tsigFudge,
+		originalID: binary.BigEndian.Uint16(msg),
+	}
+	rec.mac = k.mac(msg, prior, rec, timersOnly)
+
+	rdata := append([]byte{}, rec.algorithm...)
+	rdata = append(rdata, byte(rec.timeSigned>>40), byte(rec.timeSigned>>32))
+	rdata = appendUint32(rdata, uint32(rec.timeSigned))
+	rdata = appendUint16(rdata, rec.fudge)
+	rdata = appendUint16(rdata, uint16(len(rec.mac)))
+	rdata = append(rdata, rec.mac...)
+	rdata = appendUint16(rdata, rec.originalID)
+	rdata = appendUint16(rdata, rec.err)
+	rdata = append(rdata, 0, 0) // no other data
+
+	signed := append(append([]byte{}, msg...), k.name...)
+	signed = appendUint16(signed, uint16(dnsTypeTSIG))
+	signed = append(signed, 0, 255, 0, 0, 0, 0) // class ANY, TTL 0
+	signed = appendUint16(signed, uint16(len(rdata)))
+	signed = append(signed, rdata...)
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])+1)
+	return signed, rec.mac
+}
+
+// split separates the TSIG record from a message, returning the message as it was
+// before signing. The record is nil if the message isn't signed.
+func (k *tsigKey) split(msg []byte) ([]byte, *tsigRecord, error) {
+	var p dnsmessage.Parser
+	if _, err := p.Start(msg); err != nil {
+		return nil, nil, err
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, nil, err
+	}
+	if err := p.SkipAllAnswers(); err != nil {
+		return nil, nil, err
+	}
+	if err := p.SkipAllAuthorities(); err != nil {
+		return nil, nil, err
+	}
+	var rdata []byte
+	for {
+		h, err := p.AdditionalHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if rdata != nil {
+			return nil, nil, errors.New("TSIG record not last in message")
+		}
+		if h.Type != dnsTypeTSIG {
+			if err := p.SkipAdditional(); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		name, _ := wireName(h.Name.String())
+		if !bytes.Equal(name, k.name) {
+			return nil, nil, fmt.Errorf("TSIG key mismatch: %v", h.Name)
+		}
+		r, err := p.UnknownResource()
+		if err != nil {
+			return nil, nil, err
+		}
+		rdata = r.Data
+	}
+	if rdata == nil {
+		return msg, nil, nil
+	}
+	rec, err := parseTSIG(rdata)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The record is the last one in the message. Its owner name is either the
+	// uncompressed key name, or a compression pointer.
+	end := len(msg) - len(rdata) - 10
+	start := end - 2
+	if end >= len(k.name) && bytes.EqualFold(msg[end-len(k.name):end], k.name) {
+		start = end - len(k.name)
+	}
+	if start < 12 {
+		return nil, nil, errors.New("invalid TSIG record")
+	}
+	unsigned := append([]byte{}, msg[:start]...)
+	binary.BigEndian.PutUint16(unsigned, rec.originalID)
+	binary.BigEndian.PutUint16(unsigned[10:], binary.BigEndian.Uint16(unsigned[10:])-1)
+	return unsigned, rec, nil
+}
+
+// parseTSIG decodes the RDATA of a TSIG record.
+func parseTSIG(rdata []byte) (*tsigRecord, error) {
+	var (
+		rec = new(tsigRecord)
+		pos = 0
+	)
+	for pos < len(rdata) && rdata[pos] != 0 {
+		pos += int(rdata[pos]) + 1
+	}
+	pos++
+	if pos+10 > len(rdata) {
+		return nil, errors.New("invalid TSIG record")
+	}
+	rec.algorithm = bytes.ToLower(rdata[:pos])
+	rec.timeSigned = uint64(binary.BigEndian.Uint16(rdata[pos:]))<<32 | uint64(binary.BigEndian.Uint32(rdata[pos+2:]))
+	rec.fudge = binary.BigEndian.Uint16(rdata[pos+6:])
+	macSize := int(binary.BigEndian.Uint16(rdata[pos+8:]))
+	pos += 10
+	if pos+macSize+6 > len(rdata) {
+		return nil, errors.New("invalid TSIG record")
+	}
+	rec.mac = rdata[pos : pos+macSize]
+	rec.originalID = binary.BigEndian.Uint16(rdata[pos+macSize:])
+	rec.err = binary.BigEndian.Uint16(rdata[pos+macSize+2:])
+	return rec, nil
+}
+
+// tsigVerifier verifies the signatures of the responses to a signed request. Only
+// the first response and every 100th one of multi-message responses need to be
+// signed, the others are covered by the next signature.
+type tsigVerifier struct {
+	key      *tsigKey
+	prior    []byte // MAC of the request or the last signed response
+	unsigned []byte // unsigned responses since the last signed one
+	signed   int    // number of signed responses
+}
+
+// verify checks the signature of a response, returning it without its TSIG record.
+func (v *tsigVerifier) verify(msg []byte, now time.Time) ([]byte, error) {
+	unsigned, rec, err := v.key.split(msg)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		if v.signed == 0 {
+			return nil, errors.New("DNS response not signed")
+		}
+		if len(v.unsigned) > 99*0xffff {
+			return nil, errors.New("too many unsigned DNS responses")
+		}
+		v.unsigned = append(v.unsigned, unsigned...)
+		return unsigned, nil
+	}
+	if !bytes.Equal(rec.algorithm, v.key.algorithm) {
+		return nil, errors.New("TSIG algorithm mismatch")
+	}
+	if rec.err != 0 {
+		return nil, fmt.Errorf("TSIG error %d", rec.err)
+	}
+	if skew := now.Unix() - int64(rec.timeSigned); skew > int64(rec.fudge) || -skew > int64(rec.fudge) {
+		return nil, errors.New("TSIG time outside of permitted skew")
+	}
+	mac := v.key.mac(append(v.unsigned, unsigned...), v.prior, rec, v.signed > 0)
+	if !hmac.Equal(mac, rec.mac) {
+		return nil, errors.New("TSIG signature mismatch")
+	}
+	v.prior, v.unsigned = rec.mac, nil
+	v.signed++
+	return unsigned, nil
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
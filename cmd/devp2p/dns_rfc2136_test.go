@@ -0,0 +1,461 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/p2p/dnsdisc"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+	"github.com/ubiq/go-ubiq/v7/p2p/enr"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// This test checks that computeChanges creates DNS changes in
+// leaf-added -> root-changed -> leaf-deleted order.
+func TestRFC2136ChangeSort(t *testing.T) {
+	existing := map[string]recordSet{
+		"n":      {ttl: rootTTL, values: []string{"enrtree-root:v1 seq=0"}},
+		"aaaa.n": {ttl: treeNodeTTL, values: []string{"enrtree-branch:"}},
+		"bbbb.n": {ttl: treeNodeTTL, values: []string{"enr:old"}},
+		"cccc.n": {ttl: 3333, values: []string{"enr:same"}},
+	}
+	records := map[string]string{
+		"n":      "enrtree-root:v1 seq=1",
+		"BBBB.n": "enr:old",
+		"CCCC.n": "enr:same",
+		"DDDD.n": "enr:new",
+	}
+	want := []rfc2136Change{
+		{"CREATE", "dddd.n", treeNodeTTL, "enr:new"},
+		{"UPDATE", "cccc.n", treeNodeTTL, "enr:same"},
+		{"UPDATE", "n", rootTTL, "enrtree-root:v1 seq=1"},
+		{"DELETE", "aaaa.n", 0, ""},
+	}
+	var c rfc2136Client
+	if changes := c.computeChanges("n", records, existing); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("wrong changes:\nhave %v\nwant %v", changes, want)
+	}
+}
+
+// This test deploys trees to a DNS server, checking that its zone contains
+// exactly the records of the tree afterwards.
+func TestRFC2136Deploy(t *testing.T) {
+	key, err := newTSIGKey("update-key.", "hmac-sha256", "c2VjcmV0IGtleSBmb3IgdGVzdGluZw==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestDNSServer(t, "example.org", key)
+	defer srv.close()
+
+	// Records outside of the tree must not be touched.
+	srv.zone["example.org"] = recordSet{ttl: 3600, values: []string{"v=spf1 -all"}}
+	srv.zone["stale.nodes.example.org"] = recordSet{ttl: 60, values: []string{"enr:stale"}}
+
+	client := &rfc2136Client{server: srv.addr(), zone: "example.org", tsig: key}
+	var (
+		domain = "nodes.example.org"
+		tree   *dnsdisc.Tree
+	)
+	for i, n := range []int{20, 5} {
+		tree = makeTestTree(t, uint(i), n, domain)
+		if err := client.deploy(domain, tree); err != nil {
+			t.Fatalf("deploy %d failed: %v", i, err)
+		}
+		want := map[string]recordSet{"example.org": {ttl: 3600, values: []string{"v=spf1 -all"}}}
+		for name, value := range tree.ToTXT(domain) {
+			ttl := int64(treeNodeTTL)
+			if name == domain {
+				ttl = rootTTL
+			}
+			want[strings.ToLower(name)] = recordSet{ttl: ttl, values: []string{value}}
+		}
+		if !reflect.DeepEqual(srv.records(), want) {
+			t.Fatalf("wrong zone content after deploy %d:\nhave %v\nwant %v", i, srv.records(), want)
+		}
+	}
+
+	// Deploying the same tree again doesn't send any updates.
+	updates := srv.updates
+	if err := client.deploy(domain, tree); err != nil {
+		t.Fatal(err)
+	}
+	if srv.updates != updates {
+		t.Fatalf("unchanged tree caused %d updates", srv.updates-updates)
+	}
+
+	// Requests with a wrong key are rejected.
+	badKey, _ := newTSIGKey("update-key.", "hmac-sha256", "d3Jvbmcga2V5")
+	client.tsig = badKey
+	if err := client.deploy(domain, makeTestTree(t, 2, 5, domain)); err == nil {
+		t.Fatal("deploy with wrong TSIG key succeeded")
+	}
+}
+
+// This test checks that a refused update fails the deploy right away, even if the
+// server keeps the connection open.
+func TestRFC2136Refused(t *testing.T) {
+	key, err := newTSIGKey("update-key.", "hmac-sha256", "c2VjcmV0IGtleSBmb3IgdGVzdGluZw==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestDNSServer(t, "example.org", key)
+	srv.refuse = true
+	defer srv.close()
+
+	client := &rfc2136Client{server: srv.addr(), zone: "example.org", tsig: key}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.update([]rfc2136Change{{"CREATE", "nodes.example.org", rootTTL, "enrtree-root:v1"}})
+	}()
+	select {
+	case err := <-errc:
+		if err == nil || !strings.Contains(err.Error(), "RCodeRefused") {
+			t.Fatalf("wrong error for refused update: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("refused update didn't fail")
+	}
+}
+
+// This test checks that change batches are sized by their encoded records.
+func TestRFC2136ChangeBatches(t *testing.T) {
+	name := strings.Repeat("a", 52) + ".nodes.example.org"
+	for _, ch := range []rfc2136Change{
+		{"CREATE", name, treeNodeTTL, strings.Repeat("b", 300)},
+		{"UPDATE", name, treeNodeTTL, "enr:new"},
+		{"DELETE", name, 0, ""},
+	} {
+		msg := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+		msg.StartAuthorities()
+		if err := appendChange(&msg, ch); err != nil {
+			t.Fatal(err)
+		}
+		encoded, _ := msg.Finish()
+		if size := len(encoded) - 12; ch.size() != size {
+			t.Errorf("wrong %s change size %d, want %d", ch.action, ch.size(), size)
+		}
+	}
+	// Deletions carry no value, but must still be split into multiple batches.
+	changes := make([]rfc2136Change, 1000)
+	for i := range changes {
+		changes[i] = rfc2136Change{action: "DELETE", name: name}
+	}
+	batches := batchChanges(changes)
+	if len(batches) < 2 {
+		t.Fatalf("%d deletions not split into batches", len(changes))
+	}
+	var total int
+	for i, batch := range batches {
+		var size int
+		for _, ch := range batch {
+			size += ch.size()
+		}
+		if size > rfc2136ChangeSizeLimit {
+			t.Errorf("batch %d too large: %d bytes", i, size)
+		}
+		total += len(batch)
+	}
+	if total != len(changes) {
+		t.Fatalf("wrong number of batched changes %d, want %d", total, len(changes))
+	}
+}
+
+func TestWriteZonefile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "zone")
+	writeZonefile(file, "n", map[string]string{
+		"n":      "enrtree-root:v1",
+		"BBBB.n": strings.Repeat("b", 300),
+		"AAAA.n": "enrtree-branch:",
+	})
+	want := "; DNS discovery tree n\n" +
+		"n.\t1800\tIN\tTXT\t\"enrtree-root:v1\"\n" +
+		"AAAA.n.\t2419200\tIN\tTXT\t\"enrtree-branch:\"\n" +
+		"BBBB.n.\t2419200\tIN\tTXT\t\"" + strings.Repeat("b", 255) + "\" \"" + strings.Repeat("b", 45) + "\"\n"
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != want {
+		t.Fatalf("wrong zone file content:\n%s\nwant:\n%s", content, want)
+	}
+}
+
+func TestTSIGSplit(t *testing.T) {
+	key, _ := newTSIGKey("key", "hmac-sha1", "a2V5")
+	msg := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 1234})
+	msg.StartQuestions()
+	msg.Question(dnsmessage.Question{Name: dnsmessage.MustNewName("example.org."), Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET})
+	unsigned, _ := msg.Finish()
+
+	signed, mac := key.sign(unsigned, nil, false, time.Now())
+	binary := append([]byte{}, signed...)
+	binary[0], binary[1] = 0, 1 // ID changed by forwarding server
+	stripped, rec, err := key.split(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stripped, unsigned) {
+		t.Fatalf("wrong message after split:\nhave %x\nwant %x", stripped, unsigned)
+	}
+	if !reflect.DeepEqual(rec.mac, mac) || rec.originalID != 1234 || rec.fudge != tsigFudge {
+		t.Fatalf("wrong TSIG record %+v", rec)
+	}
+	v := &tsigVerifier{key: key}
+	if _, err := v.verify(binary, time.Now()); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if _, err := v.verify(binary, time.Now().Add(2*tsigFudge*time.Second)); err == nil {
+		t.Fatal("signature with excessive time skew accepted")
+	}
+}
+
+func makeTestTree(t *testing.T, seq uint, n int, domain string) *dnsdisc.Tree {
+	var nodes []*enode.Node
+	for i := 0; i < n; i++ {
+		var r enr.Record
+		r.Set(enr.IP(net.IP{127, 0, 0, byte(i)}))
+		r.Set(enr.UDP(30303))
+		r.SetSeq(1)
+		key, _ := crypto.GenerateKey()
+		if err := enode.SignV4(&r, key); err != nil {
+			t.Fatal(err)
+		}
+		node, err := enode.New(enode.ValidSchemes, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, node)
+	}
+	tree, err := dnsdisc.MakeTree(seq, nodes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.HexToECDSA("45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
+	if _, err := tree.Sign(key, domain); err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// testDNSServer is a minimal authoritative DNS server supporting zone transfers
+// and dynamic updates over TCP.
+type testDNSServer struct {
+	t        *testing.T
+	listener net.Listener
+	origin   dnsmessage.Name
+	key      *tsigKey
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	zone    map[string]recordSet
+	updates int
+	refuse  bool // refuse all updates, keeping the connection open
+}
+
+func newTestDNSServer(t *testing.T, origin string, key *tsigKey) *testDNSServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &testDNSServer{
+		t:        t,
+		listener: l,
+		origin:   dnsmessage.MustNewName(fqdn(origin)),
+		key:      key,
+		zone:     make(map[string]recordSet),
+	}
+	srv.wg.Add(1)
+	go srv.serve()
+	return srv
+}
+
+func (srv *testDNSServer) addr() string {
+	return srv.listener.Addr().String()
+}
+
+func (srv *testDNSServer) close() {
+	srv.listener.Close()
+	srv.wg.Wait()
+}
+
+func (srv *testDNSServer) records() map[string]recordSet {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	cpy := make(map[string]recordSet, len(srv.zone))
+	for name, set := range srv.zone {
+		cpy[name] = set
+	}
+	return cpy
+}
+
+func (srv *testDNSServer) serve() {
+	defer srv.wg.Done()
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+		srv.handleConn(conn)
+		conn.Close()
+	}
+}
+
+func (srv *testDNSServer) handleConn(conn net.Conn) {
+	req, err := readDNSMessage(conn)
+	if err != nil {
+		return
+	}
+	v := &tsigVerifier{key: srv.key}
+	unsigned, err := v.verify(req, time.Now())
+	if err != nil {
+		// Reject the request without signing the response.
+		srv.reply(conn, req, nil, dnsmessage.RCodeRefused)
+		return
+	}
+	var p dnsmessage.Parser
+	h, err := p.Start(unsigned)
+	if err != nil {
+		srv.t.Error("invalid request:", err)
+		return
+	}
+	q, err := p.Question()
+	if err != nil || q.Name != srv.origin {
+		srv.t.Errorf("invalid question %v: %v", q, err)
+		return
+	}
+	p.SkipAllQuestions()
+	switch {
+	case h.OpCode == dnsOpCodeUpdate && srv.refuse:
+		srv.reply(conn, unsigned, v.prior, dnsmessage.RCodeRefused)
+		readDNSMessage(conn) // wait for the client to hang up
+	case h.OpCode == dnsOpCodeUpdate:
+		srv.handleUpdate(conn, unsigned, &p, v.prior)
+	case q.Type == dnsmessage.TypeAXFR:
+		srv.handleTransfer(conn, unsigned, v.prior)
+	default:
+		srv.reply(conn, unsigned, v.prior, dnsmessage.RCodeNotImplemented)
+	}
+}
+
+func (srv *testDNSServer) handleUpdate(conn net.Conn, req []byte, p *dnsmessage.Parser, mac []byte) {
+	p.SkipAllAnswers()
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	for {
+		h, err := p.AuthorityHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		} else if err != nil {
+			srv.t.Error("invalid update:", err)
+			return
+		}
+		name := strings.TrimSuffix(h.Name.String(), ".")
+		switch {
+		case h.Type == dnsmessage.TypeTXT && h.Class == dnsmessage.ClassANY:
+			delete(srv.zone, name)
+			p.SkipAuthority()
+		case h.Type == dnsmessage.TypeTXT && h.Class == dnsmessage.ClassINET:
+			txt, err := p.TXTResource()
+			if err != nil {
+				srv.t.Error("invalid TXT record:", err)
+				return
+			}
+			set := srv.zone[name]
+			set.ttl = int64(h.TTL)
+			set.values = append(set.values, strings.Join(txt.TXT, ""))
+			srv.zone[name] = set
+		default:
+			srv.t.Errorf("unexpected update record %v", h)
+			return
+		}
+	}
+	srv.updates++
+	srv.reply(conn, req, mac, dnsmessage.RCodeSuccess)
+}
+
+// handleTransfer sends the zone in multiple messages, each containing one record.
+func (srv *testDNSServer) handleTransfer(conn net.Conn, req []byte, mac []byte) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	soa := func(b *dnsmessage.Builder) {
+		h := dnsmessage.ResourceHeader{Name: srv.origin, Class: dnsmessage.ClassINET, TTL: 3600}
+		b.SOAResource(h, dnsmessage.SOAResource{NS: srv.origin, MBox: srv.origin, Serial: 1})
+	}
+	msgs := []func(*dnsmessage.Builder){soa}
+	for name, set := range srv.zone {
+		h := dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(fqdn(name)), Class: dnsmessage.ClassINET, TTL: uint32(set.ttl)}
+		for _, value := range set.values {
+			txt := dnsmessage.TXTResource{TXT: txtStrings(value)}
+			msgs = append(msgs, func(b *dnsmessage.Builder) { b.TXTResource(h, txt) })
+		}
+	}
+	msgs = append(msgs, soa)
+
+	var (
+		id       = req[0:2]
+		question dnsmessage.Question
+		p        dnsmessage.Parser
+	)
+	p.Start(req)
+	question, _ = p.Question()
+	for i, add := range msgs {
+		b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: uint16(id[0])<<8 | uint16(id[1]), Response: true, Authoritative: true})
+		b.EnableCompression()
+		b.StartQuestions()
+		if i == 0 {
+			b.Question(question)
+		}
+		b.StartAnswers()
+		add(&b)
+		resp, err := b.Finish()
+		if err != nil {
+			srv.t.Error("can't build response:", err)
+			return
+		}
+		resp, mac = srv.key.sign(resp, mac, i > 0, time.Now())
+		if err := writeDNSMessage(conn, resp); err != nil {
+			return
+		}
+	}
+}
+
+func (srv *testDNSServer) reply(conn net.Conn, req []byte, mac []byte, rcode dnsmessage.RCode) {
+	var p dnsmessage.Parser
+	h, _ := p.Start(req)
+	q, _ := p.Question()
+	h.Response, h.RCode = true, rcode
+	b := dnsmessage.NewBuilder(nil, h)
+	b.StartQuestions()
+	b.Question(q)
+	resp, _ := b.Finish()
+	if mac != nil {
+		resp, _ = srv.key.sign(resp, mac, false, time.Now())
+	}
+	writeDNSMessage(conn, resp)
+}
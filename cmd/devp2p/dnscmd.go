@@ -17,12 +17,16 @@
 package main
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ubiq/go-ubiq/v7/accounts/keystore"
@@ -44,6 +48,8 @@ var (
 			dnsCloudflareCommand,
 			dnsRoute53Command,
 			dnsRoute53NukeCommand,
+			dnsRFC2136Command,
+			dnsZonefileCommand,
 		},
 	}
 	dnsSyncCommand = cli.Command{
@@ -97,6 +103,25 @@ var (
 			route53RegionFlag,
 		},
 	}
+	dnsRFC2136Command = cli.Command{
+		Name:      "to-rfc2136",
+		Usage:     "Deploy DNS TXT records to a DNS server using dynamic updates (RFC 2136)",
+		ArgsUsage: "<tree-directory>",
+		Action:    dnsToRFC2136,
+		Flags: []cli.Flag{
+			rfc2136ServerFlag,
+			rfc2136ZoneFlag,
+			rfc2136TSIGKeyFlag,
+			rfc2136TSIGSecretFlag,
+			rfc2136TSIGAlgorithmFlag,
+		},
+	}
+	dnsZonefileCommand = cli.Command{
+		Name:      "to-zonefile",
+		Usage:     "Create a DNS zone file fragment for a discovery tree",
+		ArgsUsage: "<tree-directory> <output-file>",
+		Action:    dnsToZonefile,
+	}
 )
 
 var (
@@ -251,6 +276,36 @@ func dnsNukeRoute53(ctx *cli.Context) error {
 	return client.deleteDomain(ctx.Args().First())
 }
 
+// dnsToRFC2136 performs dnsRFC2136Command.
+func dnsToRFC2136(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("need tree definition directory as argument")
+	}
+	domain, t, err := loadTreeDefinitionForExport(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	client := newRFC2136Client(ctx, domain)
+	return client.deploy(domain, t)
+}
+
+// dnsToZonefile performs dnsZonefileCommand.
+func dnsToZonefile(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return fmt.Errorf("need tree definition directory as argument")
+	}
+	output := ctx.Args().Get(1)
+	if output == "" {
+		output = "-" // default to stdout
+	}
+	domain, t, err := loadTreeDefinitionForExport(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	writeZonefile(output, domain, t.ToTXT(domain))
+	return nil
+}
+
 // loadSigningKey loads a private key in Ethereum keystore format.
 func loadSigningKey(keyfile string) *ecdsa.PrivateKey {
 	keyjson, err := ioutil.ReadFile(keyfile)
@@ -415,3 +470,40 @@ func writeTXTJSON(file string, txt map[string]string) {
 		exit(err)
 	}
 }
+
+// writeZonefile writes TXT records in DNS zone file format. The output contains
+// only the records of the tree and is meant to be included into the zone file of
+// the parent domain using $INCLUDE.
+func writeZonefile(file, domain string, txt map[string]string) {
+	var (
+		buf   bytes.Buffer
+		names = make([]string, 0, len(txt))
+	)
+	for name := range txt {
+		if name != domain {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{domain}, names...)
+
+	fmt.Fprintf(&buf, "; DNS discovery tree %s\n", domain)
+	for _, name := range names {
+		ttl := treeNodeTTL
+		if name == domain {
+			ttl = rootTTL
+		}
+		var strs []string
+		for _, s := range txtStrings(txt[name]) {
+			strs = append(strs, strconv.Quote(s))
+		}
+		fmt.Fprintf(&buf, "%s\t%d\tIN\tTXT\t%s\n", fqdn(name), ttl, strings.Join(strs, " "))
+	}
+	if file == "-" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		exit(err)
+	}
+}
@@ -18,11 +18,14 @@ package v5test
 
 import (
 	"bytes"
+	crand "crypto/rand"
+	"fmt"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/ubiq/go-ubiq/v7/internal/utesting"
+	"github.com/ubiq/go-ubiq/v7/p2p/discover"
 	"github.com/ubiq/go-ubiq/v7/p2p/discover/v5wire"
 	"github.com/ubiq/go-ubiq/v7/p2p/enode"
 	"github.com/ubiq/go-ubiq/v7/p2p/netutil"
@@ -55,6 +58,9 @@ func (s *Suite) AllTests() []utesting.Test {
 		{Name: "TalkRequest", Fn: s.TestTalkRequest},
 		{Name: "FindnodeZeroDistance", Fn: s.TestFindnodeZeroDistance},
 		{Name: "FindnodeResults", Fn: s.TestFindnodeResults},
+		{Name: "TopicRegistration", Fn: s.TestTopicRegistration},
+		{Name: "TopicRegistrationInvalidTicket", Fn: s.TestTopicRegistrationInvalidTicket},
+		{Name: "TopicQueryEmpty", Fn: s.TestTopicQueryEmpty},
 	}
 }
 
@@ -291,6 +297,98 @@ func (s *Suite) TestFindnodeResults(t *utesting.T) {
 	}
 }
 
+// maxTicketWait is the longest ticket wait time the topic tests accept.
+const maxTicketWait = 20 * time.Second
+
+// randomTopic creates a topic which isn't advertised by anyone yet.
+func randomTopic() discover.Topic {
+	name := make([]byte, 16)
+	crand.Read(name)
+	return discover.NewTopic(fmt.Sprintf("devp2p-test-%x", name))
+}
+
+// This test requests a ticket for a new topic, registers with it and checks that the
+// node is returned by TOPICQUERY for the topic.
+func (s *Suite) TestTopicRegistration(t *utesting.T) {
+	conn, l1 := s.listen1(t)
+	conn.setEndpoint(l1) // registrants need IP/port in their record
+	defer conn.close()
+
+	topic := randomTopic()
+	var ticket *v5wire.Ticket
+	switch resp := conn.reqresp(l1, &v5wire.RequestTicket{ReqID: conn.nextReqID(), Topic: topic[:]}).(type) {
+	case *v5wire.Ticket:
+		ticket = resp
+	default:
+		t.Fatal("expected TICKET, got", resp.Name())
+	}
+	wait := time.Duration(ticket.WaitTime) * time.Second
+	if wait > maxTicketWait {
+		t.Fatalf("ticket wait time %v for new topic exceeds %v", wait, maxTicketWait)
+	}
+	t.Logf("got ticket with wait time %v", wait)
+	time.Sleep(wait)
+
+	regtopic := &v5wire.Regtopic{ReqID: conn.nextReqID(), Ticket: ticket.Ticket, ENR: conn.localNode.Node().Record()}
+	switch resp := conn.reqresp(l1, regtopic).(type) {
+	case *v5wire.Regconfirmation:
+		if !bytes.Equal(resp.ReqID, regtopic.ReqID) {
+			t.Fatalf("wrong request ID %x in REGCONFIRMATION, want %x", resp.ReqID, regtopic.ReqID)
+		}
+		if !resp.Registered {
+			t.Fatal("registration rejected")
+		}
+	default:
+		t.Fatal("expected REGCONFIRMATION, got", resp.Name())
+	}
+
+	// Query the topic from another node.
+	conn2, l2 := s.listen1(t)
+	defer conn2.close()
+	nodes, err := conn2.topicQuery(l2, topic[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].ID() != conn.localNode.ID() {
+		t.Fatalf("remote returned %d nodes for topic, want only the registered node", len(nodes))
+	}
+}
+
+// This test sends REGTOPIC with a made up ticket. The remote node should reject the
+// registration.
+func (s *Suite) TestTopicRegistrationInvalidTicket(t *utesting.T) {
+	conn, l1 := s.listen1(t)
+	conn.setEndpoint(l1)
+	defer conn.close()
+
+	ticket := make([]byte, 64)
+	crand.Read(ticket)
+	regtopic := &v5wire.Regtopic{ReqID: conn.nextReqID(), Ticket: ticket, ENR: conn.localNode.Node().Record()}
+	switch resp := conn.reqresp(l1, regtopic).(type) {
+	case *v5wire.Regconfirmation:
+		if resp.Registered {
+			t.Fatal("registration with invalid ticket accepted")
+		}
+	default:
+		t.Fatal("expected REGCONFIRMATION, got", resp.Name())
+	}
+}
+
+// This test checks that the remote node returns no nodes for a topic nobody registered.
+func (s *Suite) TestTopicQueryEmpty(t *utesting.T) {
+	conn, l1 := s.listen1(t)
+	defer conn.close()
+
+	topic := randomTopic()
+	nodes, err := conn.topicQuery(l1, topic[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) > 0 {
+		t.Fatalf("remote returned %d nodes for unknown topic", len(nodes))
+	}
+}
+
 // A bystander is a node whose only purpose is filling a spot in the remote table.
 type bystander struct {
 	dest *enode.Node
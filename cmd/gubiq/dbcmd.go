@@ -36,6 +36,7 @@ import (
 	"github.com/ubiq/go-ubiq/v7/core/rawdb"
 	"github.com/ubiq/go-ubiq/v7/ethdb"
 	"github.com/ubiq/go-ubiq/v7/log"
+	"github.com/ubiq/go-ubiq/v7/params"
 	"github.com/ubiq/go-ubiq/v7/trie"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -69,6 +70,7 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbPruneHistoryCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -215,6 +217,29 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	historyKeepFlag = cli.Uint64Flag{
+		Name:  "keep",
+		Usage: "Number of recent blocks to retain the bodies and receipts of",
+		Value: params.FullImmutabilityThreshold,
+	}
+	dbPruneHistoryCmd = cli.Command{
+		Action: utils.MigrateFlags(pruneHistory),
+		Name:   "prune-history",
+		Usage:  "Prune ancient block bodies and receipts older than the given number of blocks",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.SyncModeFlag,
+			utils.MainnetFlag,
+			utils.RinkebyFlag,
+			utils.GoerliFlag,
+			historyKeepFlag,
+		},
+		Description: `This command discards the block bodies and receipts of all blocks older
+than the most recent --keep blocks from the ancient store, retaining the headers.
+Only data already moved into the ancient store is pruned. Transaction lookup
+entries of the pruned blocks are removed too.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -529,6 +554,59 @@ func freezerInspect(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneHistory discards the ancient block bodies and receipts older than the
+// configured number of recent blocks.
+func pruneHistory(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	head := rawdb.ReadHeadBlockHash(db)
+	if head == (common.Hash{}) {
+		return errors.New("head block missing")
+	}
+	number := rawdb.ReadHeaderNumber(db, head)
+	if number == nil {
+		return fmt.Errorf("head block number missing: %x", head)
+	}
+	frozen, err := db.Ancients()
+	if err != nil {
+		return err
+	}
+	tail, err := rawdb.ReadHistoryTail(db)
+	if err != nil {
+		return err
+	}
+	keep := ctx.Uint64(historyKeepFlag.Name)
+	if *number < keep {
+		log.Info("Chain shorter than retention limit, nothing to prune", "head", *number, "keep", keep)
+		return nil
+	}
+	target := *number + 1 - keep
+	if target > frozen {
+		log.Warn("Limiting pruning to the ancient store", "target", target, "frozen", frozen)
+		target = frozen
+	}
+	if target <= tail {
+		log.Info("History already pruned", "tail", tail, "target", target)
+		return nil
+	}
+	start := time.Now()
+
+	// Drop the transaction lookup entries first, they can't be resolved without
+	// the block bodies anymore.
+	if txTail := rawdb.ReadTxIndexTail(db); txTail != nil && *txTail < target {
+		rawdb.UnindexTransactions(db, *txTail, target, nil)
+	}
+	if err := rawdb.PruneHistory(db, target); err != nil {
+		return err
+	}
+	log.Info("Pruned chain history", "from", tail, "to", target, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // ParseHexOrString tries to hexdecode b, but if the prefix is missing, it instead just returns the raw bytes
 func parseHexOrString(str string) ([]byte, error) {
 	b, err := hexutil.Decode(str)
@@ -37,6 +37,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.DataDirFlag,
 			utils.AncientFlag,
 			utils.MinFreeDiskSpaceFlag,
+			utils.DBEngineFlag,
 			utils.KeyStoreDirFlag,
 			utils.USBFlag,
 			utils.SmartCardDaemonPathFlag,
@@ -89,6 +90,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolRemoteJournalFlag,
+			utils.TxPoolRemoteJournalAgeFlag,
 		},
 	},
 	{
@@ -124,6 +127,13 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.HTTPPortFlag,
 			utils.HTTPApiFlag,
 			utils.HTTPPathPrefixFlag,
+			utils.BatchRequestLimit,
+			utils.BatchResponseMaxSize,
+			utils.AuthListenFlag,
+			utils.AuthPortFlag,
+			utils.AuthVirtualHostsFlag,
+			utils.AuthApiFlag,
+			utils.JWTSecretFlag,
 			utils.HTTPCORSDomainFlag,
 			utils.HTTPVirtualHostsFlag,
 			utils.WSEnabledFlag,
@@ -156,6 +166,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.NoDiscoverFlag,
 			utils.DiscoveryV5Flag,
 			utils.NetrestrictFlag,
+			utils.BandwidthIngressFlag,
+			utils.BandwidthEgressFlag,
+			utils.BandwidthPeerIngressFlag,
+			utils.BandwidthPeerEgressFlag,
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
 		},
@@ -167,12 +181,16 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerThreadsFlag,
 			utils.MinerNotifyFlag,
 			utils.MinerNotifyFullFlag,
+			utils.MinerStratumFlag,
+			utils.MinerStratumDifficultyFlag,
 			utils.MinerGasPriceFlag,
 			utils.MinerGasLimitFlag,
 			utils.MinerEtherbaseFlag,
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerifyFlag,
+			utils.MinerTxOrderingFlag,
+			utils.MinerCliqueStandbyFlag,
 		},
 	},
 	{
@@ -33,6 +33,7 @@ import (
 	"github.com/ubiq/go-ubiq/v7/common"
 	"github.com/ubiq/go-ubiq/v7/core"
 	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/core/state/snapshot"
 	"github.com/ubiq/go-ubiq/v7/core/types"
 	"github.com/ubiq/go-ubiq/v7/crypto"
 	"github.com/ubiq/go-ubiq/v7/eth/ethconfig"
@@ -365,6 +366,60 @@ func ExportPreimages(db ethdb.Database, fn string) error {
 	return nil
 }
 
+// ExportState exports the state identified by root out of the snapshot tree into
+// the specified file, truncating any data already present in the file.
+func ExportState(snaps *snapshot.Tree, root common.Hash, header *types.Header, fn string) error {
+	log.Info("Exporting state", "file", fn, "root", root, "number", header.Number)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	buffer := bufio.NewWriter(writer)
+	if _, err := snaps.Export(buffer, root, header.Number.Uint64(), header.Hash()); err != nil {
+		return err
+	}
+	if err := buffer.Flush(); err != nil {
+		return err
+	}
+	log.Info("Exported state", "file", fn)
+	return nil
+}
+
+// ImportState imports a state export from the specified file into the database,
+// returning the header of the export identifying the imported state.
+func ImportState(db ethdb.KeyValueStore, fn string) (*snapshot.ExportHeader, error) {
+	log.Info("Importing state", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = bufio.NewReader(fh)
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return nil, err
+		}
+	}
+	header, _, err := snapshot.Import(reader, db)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Imported state", "file", fn, "root", header.Root, "number", header.Number)
+	return header, nil
+}
+
 // exportHeader is used in the export/import flow. When we do an export,
 // the first element we output is the exportHeader.
 // Whenever a backwards-incompatible change is made, the Version header
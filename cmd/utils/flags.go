@@ -121,6 +121,10 @@ var (
 		Name:  "datadir.minfreedisk",
 		Usage: "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
 	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: "Backing database implementation to use ('leveldb' or 'pebble')",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -380,6 +384,15 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: ethconfig.Defaults.TxPool.Lifetime,
 	}
+	TxPoolRemoteJournalFlag = cli.StringFlag{
+		Name:  "txpool.remotejournal",
+		Usage: "Disk journal for remote transactions to survive node restarts (disabled if empty)",
+	}
+	TxPoolRemoteJournalAgeFlag = cli.DurationFlag{
+		Name:  "txpool.remotejournalage",
+		Usage: "Maximum age of remote transactions reloaded from the journal (0 = unlimited)",
+		Value: ethconfig.Defaults.TxPool.RemoteJournalAge,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -442,6 +455,14 @@ var (
 		Name:  "miner.notify.full",
 		Usage: "Notify with pending block headers instead of work packages",
 	}
+	MinerStratumFlag = cli.StringFlag{
+		Name:  "miner.stratum",
+		Usage: "Listening address of the built-in stratum mining server (e.g. 127.0.0.1:8008)",
+	}
+	MinerStratumDifficultyFlag = cli.Uint64Flag{
+		Name:  "miner.stratum.difficulty",
+		Usage: "Share difficulty of stratum workers (0 = block difficulty)",
+	}
 	MinerGasLimitFlag = cli.Uint64Flag{
 		Name:  "miner.gaslimit",
 		Usage: "Target gas ceiling for mined blocks",
@@ -470,6 +491,15 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerTxOrderingFlag = cli.StringFlag{
+		Name:  "miner.txordering",
+		Usage: "Transaction ordering policy used to fill blocks (price, fifo, fair)",
+		Value: miner.TxOrderingPriceAndNonce,
+	}
+	MinerCliqueStandbyFlag = cli.Uint64Flag{
+		Name:  "miner.cliquestandby",
+		Usage: "Run as a standby clique signer, sealing only after the primary holding the same key missed this many in-turn slots (0 = disabled)",
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -565,6 +595,40 @@ var (
 		Usage: "HTTP path path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
 		Value: "",
 	}
+	BatchRequestLimit = cli.IntFlag{
+		Name:  "rpc.batch-request-limit",
+		Usage: "Maximum number of requests in a batch",
+		Value: node.DefaultConfig.BatchRequestLimit,
+	}
+	BatchResponseMaxSize = cli.IntFlag{
+		Name:  "rpc.batch-response-max-size",
+		Usage: "Maximum number of bytes returned from a batched call",
+		Value: node.DefaultConfig.BatchResponseMaxSize,
+	}
+	AuthListenFlag = cli.StringFlag{
+		Name:  "authrpc.addr",
+		Usage: "Listening address for authenticated APIs",
+		Value: node.DefaultConfig.AuthAddr,
+	}
+	AuthPortFlag = cli.IntFlag{
+		Name:  "authrpc.port",
+		Usage: "Listening port for authenticated APIs",
+		Value: node.DefaultConfig.AuthPort,
+	}
+	AuthVirtualHostsFlag = cli.StringFlag{
+		Name:  "authrpc.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.AuthVirtualHosts, ","),
+	}
+	AuthApiFlag = cli.StringFlag{
+		Name:  "authrpc.api",
+		Usage: "API's offered over the authenticated HTTP and WebSocket interface",
+		Value: strings.Join(node.DefaultConfig.AuthModules, ","),
+	}
+	JWTSecretFlag = cli.StringFlag{
+		Name:  "authrpc.jwtsecret",
+		Usage: "Path to a JWT secret to use for authenticated RPC endpoints (enables the endpoint, a new secret is generated if the file is missing)",
+	}
 	GraphQLEnabledFlag = cli.BoolFlag{
 		Name:  "graphql",
 		Usage: "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -667,6 +731,22 @@ var (
 		Name:  "netrestrict",
 		Usage: "Restricts network communication to the given IP networks (CIDR masks)",
 	}
+	BandwidthIngressFlag = cli.IntFlag{
+		Name:  "bandwidth.ingress",
+		Usage: "Maximum bandwidth of messages read from all peers combined, in bytes per second (0 = unlimited)",
+	}
+	BandwidthEgressFlag = cli.IntFlag{
+		Name:  "bandwidth.egress",
+		Usage: "Maximum bandwidth of messages written to all peers combined, in bytes per second (0 = unlimited)",
+	}
+	BandwidthPeerIngressFlag = cli.IntFlag{
+		Name:  "bandwidth.peeringress",
+		Usage: "Maximum bandwidth of messages read from a single peer, in bytes per second (0 = unlimited)",
+	}
+	BandwidthPeerEgressFlag = cli.IntFlag{
+		Name:  "bandwidth.peeregress",
+		Usage: "Maximum bandwidth of messages written to a single peer, in bytes per second (0 = unlimited)",
+	}
 	DNSDiscoveryFlag = cli.StringFlag{
 		Name:  "discovery.dns",
 		Usage: "Sets DNS discovery entry points (use \"\" to disable DNS)",
@@ -945,6 +1025,29 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(HTTPPathPrefixFlag.Name) {
 		cfg.HTTPPathPrefix = ctx.GlobalString(HTTPPathPrefixFlag.Name)
 	}
+
+	if ctx.GlobalIsSet(BatchRequestLimit.Name) {
+		cfg.BatchRequestLimit = ctx.GlobalInt(BatchRequestLimit.Name)
+	}
+	if ctx.GlobalIsSet(BatchResponseMaxSize.Name) {
+		cfg.BatchResponseMaxSize = ctx.GlobalInt(BatchResponseMaxSize.Name)
+	}
+
+	if ctx.GlobalIsSet(AuthListenFlag.Name) {
+		cfg.AuthAddr = ctx.GlobalString(AuthListenFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthPortFlag.Name) {
+		cfg.AuthPort = ctx.GlobalInt(AuthPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthVirtualHostsFlag.Name) {
+		cfg.AuthVirtualHosts = SplitAndTrim(ctx.GlobalString(AuthVirtualHostsFlag.Name))
+	}
+	if ctx.GlobalIsSet(AuthApiFlag.Name) {
+		cfg.AuthModules = SplitAndTrim(ctx.GlobalString(AuthApiFlag.Name))
+	}
+	if ctx.GlobalIsSet(JWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.GlobalString(JWTSecretFlag.Name)
+	}
 	if ctx.GlobalIsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.GlobalBool(AllowUnprotectedTxs.Name)
 	}
@@ -1111,6 +1214,19 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.GlobalIsSet(BandwidthIngressFlag.Name) {
+		cfg.IngressLimit = ctx.GlobalInt(BandwidthIngressFlag.Name)
+	}
+	if ctx.GlobalIsSet(BandwidthEgressFlag.Name) {
+		cfg.EgressLimit = ctx.GlobalInt(BandwidthEgressFlag.Name)
+	}
+	if ctx.GlobalIsSet(BandwidthPeerIngressFlag.Name) {
+		cfg.PeerIngressLimit = ctx.GlobalInt(BandwidthPeerIngressFlag.Name)
+	}
+	if ctx.GlobalIsSet(BandwidthPeerEgressFlag.Name) {
+		cfg.PeerEgressLimit = ctx.GlobalInt(BandwidthPeerEgressFlag.Name)
+	}
+
 	if ctx.GlobalBool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -1132,6 +1248,15 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		dbEngine := ctx.GlobalString(DBEngineFlag.Name)
+		if dbEngine != "leveldb" && dbEngine != "pebble" {
+			Fatalf("Invalid choice for db.engine '%s', allowed 'leveldb' or 'pebble'", dbEngine)
+		}
+		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
+		cfg.DBEngine = dbEngine
+	}
+
 	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
 	}
@@ -1246,6 +1371,12 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolRemoteJournalFlag.Name) {
+		cfg.RemoteJournal = ctx.GlobalString(TxPoolRemoteJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRemoteJournalAgeFlag.Name) {
+		cfg.RemoteJournalAge = ctx.GlobalDuration(TxPoolRemoteJournalAgeFlag.Name)
+	}
 }
 
 func setUbqhash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1283,6 +1414,12 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		cfg.Notify = strings.Split(ctx.GlobalString(MinerNotifyFlag.Name), ",")
 	}
 	cfg.NotifyFull = ctx.GlobalBool(MinerNotifyFullFlag.Name)
+	if ctx.GlobalIsSet(MinerStratumFlag.Name) {
+		cfg.Stratum = ctx.GlobalString(MinerStratumFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumDifficultyFlag.Name) {
+		cfg.StratumDifficulty = ctx.GlobalUint64(MinerStratumDifficultyFlag.Name)
+	}
 	if ctx.GlobalIsSet(MinerExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.GlobalString(MinerExtraDataFlag.Name))
 	}
@@ -1298,6 +1435,16 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerNoVerifyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerifyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerTxOrderingFlag.Name) {
+		ordering := ctx.GlobalString(MinerTxOrderingFlag.Name)
+		if _, err := miner.NewTxOrderingPolicy(ordering); err != nil {
+			Fatalf("Invalid --%s: %v", MinerTxOrderingFlag.Name, err)
+		}
+		cfg.TxOrdering = ordering
+	}
+	if ctx.GlobalIsSet(MinerCliqueStandbyFlag.Name) {
+		cfg.CliqueStandby = ctx.GlobalUint64(MinerCliqueStandbyFlag.Name)
+	}
 	if ctx.GlobalIsSet(LegacyMinerGasTargetFlag.Name) {
 		log.Warn("The generic --miner.gastarget flag is deprecated and will be removed in the future!")
 	}
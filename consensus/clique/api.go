@@ -123,16 +123,96 @@ func (api *API) Discard(address common.Address) {
 	delete(api.clique.proposals, address)
 }
 
+// maxHistoryRange is the maximum number of blocks the voting history can be
+// replayed for in a single request.
+const maxHistoryRange = 65536
+
+// history replays the headers in the given block range (inclusive, up to the
+// current head if to is nil) on top of the snapshot preceding it, collecting
+// all the votes cast and signer changes.
+func (api *API) history(from rpc.BlockNumber, to *rpc.BlockNumber) (*voteHistory, error) {
+	// Resolve the requested range, defaulting to the current head
+	head := api.chain.CurrentHeader().Number.Uint64()
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number < 0 {
+			return head
+		}
+		return uint64(number)
+	}
+	first, last := resolve(from), head
+	if to != nil {
+		last = resolve(*to)
+	}
+	if first == 0 {
+		first = 1 // Genesis carries no votes
+	}
+	if last > head {
+		return nil, errUnknownBlock
+	}
+	if first > last {
+		return nil, fmt.Errorf("invalid block range %d-%d", first, last)
+	}
+	if last-first+1 > maxHistoryRange {
+		return nil, fmt.Errorf("block range too large: %d > %d", last-first+1, maxHistoryRange)
+	}
+	// Retrieve the snapshot preceding the range and replay the headers on top
+	parent := api.chain.GetHeaderByNumber(first - 1)
+	if parent == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.clique.snapshot(api.chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*types.Header, 0, last-first+1)
+	for number := first; number <= last; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("missing block %d", number)
+		}
+		headers = append(headers, header)
+	}
+	history := new(voteHistory)
+	if _, err := snap.applyWithHistory(headers, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetVoteHistory retrieves all the votes cast in the given block range, along
+// with whether they were counted and passed their proposal. If to is omitted,
+// the range ends at the current head.
+func (api *API) GetVoteHistory(from rpc.BlockNumber, to *rpc.BlockNumber) ([]*VoteRecord, error) {
+	history, err := api.history(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return history.votes, nil
+}
+
+// GetSignerChanges retrieves all the changes of the authorized signer set that
+// happened in the given block range. If to is omitted, the range ends at the
+// current head.
+func (api *API) GetSignerChanges(from rpc.BlockNumber, to *rpc.BlockNumber) ([]*SignerChange, error) {
+	history, err := api.history(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return history.changes, nil
+}
+
 type status struct {
 	InturnPercent float64                `json:"inturnPercent"`
 	SigningStatus map[common.Address]int `json:"sealerActivity"`
 	NumBlocks     uint64                 `json:"numBlocks"`
+	Standby       *standbyStatus         `json:"standby,omitempty"`
 }
 
 // Status returns the status of the last N blocks,
 // - the number of active signers,
 // - the number of signers,
 // - the percentage of in-turn blocks
+// - the failover state, if running as a standby signer
 func (api *API) Status() (*status, error) {
 	var (
 		numBlocks = uint64(64)
@@ -172,11 +252,19 @@ func (api *API) Status() (*status, error) {
 		}
 		signStatus[sealer]++
 	}
-	return &status{
+	api.clique.lock.RLock()
+	standby := api.clique.standby
+	api.clique.lock.RUnlock()
+
+	result := &status{
 		InturnPercent: float64(100*optimals) / float64(numBlocks),
 		SigningStatus: signStatus,
 		NumBlocks:     numBlocks,
-	}, nil
+	}
+	if standby != nil {
+		result.Standby = standby.status()
+	}
+	return result, nil
 }
 
 type blockNumberOrHashOrRLP struct {
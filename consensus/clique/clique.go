@@ -178,9 +178,10 @@ type Clique struct {
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
-	signer common.Address // Ethereum address of the signing key
-	signFn SignerFn       // Signer function to authorize hashes with
-	lock   sync.RWMutex   // Protects the signer fields
+	signer  common.Address // Ethereum address of the signing key
+	signFn  SignerFn       // Signer function to authorize hashes with
+	standby *standby       // Failover state if running as a standby signer
+	lock    sync.RWMutex   // Protects the signer fields
 
 	// The fields below are for testing only
 	fakeDiff bool // Skip difficulty verifications
@@ -584,6 +585,21 @@ func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
 	c.signFn = signFn
 }
 
+// SetStandby switches the engine into standby mode, where it only seals blocks
+// once the primary signer holding the same key missed the given number of
+// consecutive in-turn slots, stepping back as soon as the primary returns. A
+// zero threshold disables standby mode.
+func (c *Clique) SetStandby(threshold uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if threshold == 0 {
+		c.standby = nil
+		return
+	}
+	c.standby = newStandby(threshold, c.db)
+}
+
 // Seal implements consensus.Engine, attempting to create a sealed block using
 // the local signing credentials.
 func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
@@ -600,7 +616,7 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 	}
 	// Don't hold the signer fields for the entire sealing procedure
 	c.lock.RLock()
-	signer, signFn := c.signer, c.signFn
+	signer, signFn, standby := c.signer, c.signFn, c.standby
 	c.lock.RUnlock()
 
 	// Bail out if we're unauthorized to sign a block
@@ -620,6 +636,11 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 			}
 		}
 	}
+	// If we're a standby signer, only seal if the primary is gone
+	if standby != nil && !standby.check(chain, snap, header, signer) {
+		log.Trace("Standby signer waiting for primary to fail", "number", number)
+		return nil
+	}
 	// Sweet, the protocol permits us to sign the block, wait for our time
 	delay := time.Unix(int64(header.Time), 0).Sub(time.Now()) // nolint: gosimple
 	if header.Difficulty.Cmp(diffNoTurn) == 0 {
@@ -635,6 +656,9 @@ func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 		return err
 	}
 	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+	if standby != nil {
+		standby.seal(header.Hash())
+	}
 	// Wait until sealing is terminated or delay timeout.
 	log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
 	go func() {
@@ -46,6 +46,36 @@ type Tally struct {
 	Votes     int  `json:"votes"`     // Number of votes until now wanting to pass the proposal
 }
 
+// VoteRecord is a single vote found in a block header while replaying the
+// voting history, along with its effect on the tally.
+type VoteRecord struct {
+	Block     uint64         `json:"block"`     // Block number the vote was cast in
+	Hash      common.Hash    `json:"hash"`      // Block hash the vote was cast in
+	Signer    common.Address `json:"signer"`    // Authorized signer that cast this vote
+	Address   common.Address `json:"address"`   // Account being voted on to change its authorization
+	Authorize bool           `json:"authorize"` // Whether to authorize or deauthorize the voted account
+	Counted   bool           `json:"counted"`   // Whether the vote was meaningful and counted in the tally
+	Votes     int            `json:"votes"`     // Number of votes for the proposal after this one
+	Passed    bool           `json:"passed"`    // Whether this vote passed the proposal
+}
+
+// SignerChange is a change of the authorized signer set resulting from a vote
+// passing.
+type SignerChange struct {
+	Block      uint64           `json:"block"`      // Block number the change happened in
+	Hash       common.Hash      `json:"hash"`       // Block hash the change happened in
+	Address    common.Address   `json:"address"`    // Account whose authorization changed
+	Authorized bool             `json:"authorized"` // Whether the account was authorized or deauthorized
+	Signers    []common.Address `json:"signers"`    // Set of authorized signers after the change
+}
+
+// voteHistory collects the votes and signer changes encountered while applying
+// headers to a snapshot.
+type voteHistory struct {
+	votes   []*VoteRecord
+	changes []*SignerChange
+}
+
 // Snapshot is the state of the authorization voting at a given point in time.
 type Snapshot struct {
 	config   *params.CliqueConfig // Consensus engine parameters to fine tune behavior
@@ -183,6 +213,13 @@ func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
 // apply creates a new authorization snapshot by applying the given headers to
 // the original one.
 func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+	return s.applyWithHistory(headers, nil)
+}
+
+// applyWithHistory creates a new authorization snapshot by applying the given
+// headers to the original one, recording all the votes and resulting signer
+// changes into history if it's non-nil.
+func (s *Snapshot) applyWithHistory(headers []*types.Header, history *voteHistory) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -250,7 +287,8 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 		default:
 			return nil, errInvalidVote
 		}
-		if snap.cast(header.Coinbase, authorize) {
+		counted := snap.cast(header.Coinbase, authorize)
+		if counted {
 			snap.Votes = append(snap.Votes, &Vote{
 				Signer:    signer,
 				Block:     number,
@@ -258,8 +296,24 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 				Authorize: authorize,
 			})
 		}
+		tally := snap.Tally[header.Coinbase]
+		passed := tally.Votes > len(snap.Signers)/2
+
+		// Record the vote if requested, skipping blocks without any vote cast
+		if history != nil && header.Coinbase != (common.Address{}) {
+			history.votes = append(history.votes, &VoteRecord{
+				Block:     number,
+				Hash:      header.Hash(),
+				Signer:    signer,
+				Address:   header.Coinbase,
+				Authorize: authorize,
+				Counted:   counted,
+				Votes:     tally.Votes,
+				Passed:    passed,
+			})
+		}
 		// If the vote passed, update the list of signers
-		if tally := snap.Tally[header.Coinbase]; tally.Votes > len(snap.Signers)/2 {
+		if passed {
 			if tally.Authorize {
 				snap.Signers[header.Coinbase] = struct{}{}
 			} else {
@@ -290,6 +344,16 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 				}
 			}
 			delete(snap.Tally, header.Coinbase)
+
+			if history != nil {
+				history.changes = append(history.changes, &SignerChange{
+					Block:      number,
+					Hash:       header.Hash(),
+					Address:    header.Coinbase,
+					Authorized: tally.Authorize,
+					Signers:    snap.signers(),
+				})
+			}
 		}
 		// If we're taking too much time (ecrecover), notify the user once a while
 		if time.Since(logged) > 8*time.Second {
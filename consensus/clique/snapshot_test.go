@@ -30,6 +30,7 @@ import (
 	"github.com/ubiq/go-ubiq/v7/core/vm"
 	"github.com/ubiq/go-ubiq/v7/crypto"
 	"github.com/ubiq/go-ubiq/v7/params"
+	"github.com/ubiq/go-ubiq/v7/rpc"
 )
 
 // testerAccountPool is a pool to maintain currently active tester accounts,
@@ -504,3 +505,114 @@ func TestClique(t *testing.T) {
 		}
 	}
 }
+
+// newTesterChain creates a clique chain with the given initial signers, made of
+// blocks signed and voted according to the given votes.
+func newTesterChain(t *testing.T, accounts *testerAccountPool, initial []string, votes []testerVote) (*core.BlockChain, *Clique, []*types.Block) {
+	signers := make([]common.Address, len(initial))
+	for i, signer := range initial {
+		signers[i] = accounts.address(signer)
+	}
+	sort.Sort(signersAscending(signers))
+
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength*len(signers)+extraSeal),
+		BaseFee:   big.NewInt(params.InitialBaseFee),
+	}
+	for j, signer := range signers {
+		copy(genesis.ExtraData[extraVanity+j*common.AddressLength:], signer[:])
+	}
+	db := rawdb.NewMemoryDatabase()
+	genesis.Commit(db)
+
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	engine := New(config.Clique, db)
+	engine.fakeDiff = true
+
+	blocks, _ := core.GenerateChain(&config, genesis.ToBlock(db), engine, db, len(votes), func(j int, gen *core.BlockGen) {
+		gen.SetCoinbase(accounts.address(votes[j].voted))
+		if votes[j].auth {
+			var nonce types.BlockNonce
+			copy(nonce[:], nonceAuthVote)
+			gen.SetNonce(nonce)
+		}
+	})
+	for j, block := range blocks {
+		header := block.Header()
+		if j > 0 {
+			header.ParentHash = blocks[j-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Difficulty = diffInTurn
+
+		accounts.sign(header, votes[j].signer)
+		blocks[j] = block.WithSeal(header)
+	}
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create test chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	return chain, engine, blocks
+}
+
+// Tests that the voting history replayed over a block range reports every vote
+// cast along with the resulting signer changes.
+func TestCliqueVoteHistory(t *testing.T) {
+	// Create a chain of two signers voting in a third one
+	accounts := newTesterAccountPool()
+	votes := []testerVote{
+		{signer: "A", voted: "C", auth: true},
+		{signer: "B", voted: "C", auth: true},
+		{signer: "C"},
+		{signer: "A", voted: "C", auth: true},
+	}
+	chain, engine, blocks := newTesterChain(t, accounts, []string{"A", "B"}, votes)
+	defer chain.Stop()
+
+	api := &API{chain: chain, clique: engine}
+
+	// Check the full voting history
+	history, err := api.GetVoteHistory(0, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve vote history: %v", err)
+	}
+	want := []VoteRecord{
+		{Block: 1, Signer: accounts.address("A"), Address: accounts.address("C"), Authorize: true, Counted: true, Votes: 1},
+		{Block: 2, Signer: accounts.address("B"), Address: accounts.address("C"), Authorize: true, Counted: true, Votes: 2, Passed: true},
+		{Block: 4, Signer: accounts.address("A"), Address: accounts.address("C"), Authorize: true},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("vote count mismatch: have %d, want %d", len(history), len(want))
+	}
+	for i, vote := range history {
+		want[i].Hash = blocks[want[i].Block-1].Hash()
+		if *vote != want[i] {
+			t.Errorf("vote %d mismatch: have %+v, want %+v", i, *vote, want[i])
+		}
+	}
+	changes, err := api.GetSignerChanges(0, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve signer changes: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("signer change count mismatch: have %d, want 1", len(changes))
+	}
+	if change := changes[0]; change.Block != 2 || change.Address != accounts.address("C") || !change.Authorized || len(change.Signers) != 3 {
+		t.Errorf("signer change mismatch: have %+v", change)
+	}
+	// Check that a partial range is replayed on top of the preceding snapshot
+	to := rpc.BlockNumber(4)
+	if history, err = api.GetVoteHistory(3, &to); err != nil {
+		t.Fatalf("failed to retrieve partial vote history: %v", err)
+	}
+	if len(history) != 1 || history[0].Block != 4 || history[0].Counted {
+		t.Errorf("partial vote history mismatch: have %v", history)
+	}
+	if _, err := api.GetVoteHistory(3, new(rpc.BlockNumber)); err == nil {
+		t.Errorf("inverted block range accepted")
+	}
+}
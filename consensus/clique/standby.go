@@ -0,0 +1,181 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/consensus"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/ethdb"
+	"github.com/ubiq/go-ubiq/v7/log"
+)
+
+// inmemorySealed is the number of recently sealed block hashes a standby signer
+// keeps in memory on top of the ones persisted in the database.
+const inmemorySealed = 1024
+
+// standby tracks the failover state of a signer running as a hot standby of a
+// primary node holding the same key. The standby only seals once the primary
+// missed a number of consecutive in-turn slots, and steps back as soon as the
+// chain contains a block signed with the shared key that it didn't seal itself.
+//
+// The hashes of the sealed blocks are persisted, so that a restarted standby
+// still recognizes its own blocks and resumes sealing instead of mistaking them
+// for the primary's.
+type standby struct {
+	threshold uint64         // Number of consecutive missed in-turn slots to take over after
+	active    bool           // Whether the standby took over sealing from the primary
+	since     uint64         // Block number the standby took over sealing at
+	missed    uint64         // Number of consecutive missed in-turn slots at the last check
+	sealed    *lru.ARCCache  // Hashes of the blocks recently sealed by the standby
+	db        ethdb.Database // Database to persist the sealed block hashes into
+	lock      sync.Mutex
+}
+
+// newStandby creates a standby tracker taking over after the given number of
+// consecutive missed in-turn slots.
+func newStandby(threshold uint64, db ethdb.Database) *standby {
+	sealed, _ := lru.NewARC(inmemorySealed)
+	return &standby{
+		threshold: threshold,
+		sealed:    sealed,
+		db:        db,
+	}
+}
+
+// check decides whether the standby is allowed to seal the given header, taking
+// over from or stepping back for the primary signer as needed.
+func (s *standby) check(chain consensus.ChainHeaderReader, snap *Snapshot, header *types.Header, signer common.Address) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	number := header.Number.Uint64()
+	if s.active {
+		if s.primaryReturned(chain, snap, header, signer) {
+			log.Warn("Primary clique signer returned, standing by", "signer", signer, "number", number)
+			s.active, s.missed = false, 0
+		}
+	} else {
+		s.missed = s.missedSlots(chain, snap, header, signer)
+		if s.missed >= s.threshold {
+			log.Warn("Primary clique signer missed in-turn slots, taking over", "signer", signer, "number", number, "missed", s.missed)
+			s.active, s.since = true, number
+		}
+	}
+	return s.active
+}
+
+// missedSlots counts the consecutive in-turn slots of the signer preceding the
+// given header which were not sealed by the primary. Slots sealed by the standby
+// itself count as missed, which lets a restarted standby take over again.
+func (s *standby) missedSlots(chain consensus.ChainHeaderReader, snap *Snapshot, header *types.Header, signer common.Address) uint64 {
+	var (
+		missed uint64
+		hash   = header.ParentHash
+		number = header.Number.Uint64() - 1
+		limit  = s.threshold * uint64(len(snap.Signers))
+	)
+	for i := uint64(0); i < limit && number > 0; i++ {
+		parent := chain.GetHeader(hash, number)
+		if parent == nil {
+			break
+		}
+		if snap.inturn(number, signer) {
+			if author, err := ecrecover(parent, snap.sigcache); err == nil && author == signer && !s.sealedBy(parent.Hash()) {
+				break
+			}
+			missed++
+		}
+		hash, number = parent.ParentHash, number-1
+	}
+	return missed
+}
+
+// primaryReturned checks whether any recent block since the standby took over
+// was sealed with the shared key, but not by the standby itself.
+func (s *standby) primaryReturned(chain consensus.ChainHeaderReader, snap *Snapshot, header *types.Header, signer common.Address) bool {
+	var (
+		hash   = header.ParentHash
+		number = header.Number.Uint64() - 1
+		limit  = 2 * uint64(len(snap.Signers))
+	)
+	for i := uint64(0); i < limit && number >= s.since && number > 0; i++ {
+		parent := chain.GetHeader(hash, number)
+		if parent == nil {
+			break
+		}
+		if author, err := ecrecover(parent, snap.sigcache); err == nil && author == signer && !s.sealedBy(parent.Hash()) {
+			return true
+		}
+		hash, number = parent.ParentHash, number-1
+	}
+	return false
+}
+
+// seal records a block sealed by the standby, to avoid mistaking it for one of
+// the primary's.
+func (s *standby) seal(hash common.Hash) {
+	s.sealed.Add(hash, struct{}{})
+	if err := s.db.Put(standbySealedKey(hash), []byte{0x01}); err != nil {
+		log.Warn("Failed to store standby sealed block", "hash", hash, "err", err)
+	}
+}
+
+// standbySealedKey is the database key marking a block sealed by the standby.
+func standbySealedKey(hash common.Hash) []byte {
+	return append([]byte("clique-standby-"), hash[:]...)
+}
+
+// sealedBy checks whether the block with the given hash was sealed by the
+// standby, either during this run or before a restart.
+func (s *standby) sealedBy(hash common.Hash) bool {
+	if s.sealed.Contains(hash) {
+		return true
+	}
+	if ok, _ := s.db.Has(standbySealedKey(hash)); ok {
+		s.sealed.Add(hash, struct{}{})
+		return true
+	}
+	return false
+}
+
+// standbyStatus is the failover state of a standby signer reported over RPC.
+type standbyStatus struct {
+	Threshold uint64 `json:"threshold"`
+	Active    bool   `json:"active"`
+	Since     uint64 `json:"since,omitempty"`
+	Missed    uint64 `json:"missed"`
+}
+
+// status returns the current failover state of the standby.
+func (s *standby) status() *standbyStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status := &standbyStatus{
+		Threshold: s.threshold,
+		Active:    s.active,
+		Missed:    s.missed,
+	}
+	if s.active {
+		status.Since = s.since
+	}
+	return status
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+)
+
+// Tests that a standby signer only takes over sealing once the primary signer
+// missed enough in-turn slots, and steps back when the primary returns.
+func TestStandbyFailover(t *testing.T) {
+	// Create a chain where the primary of signer A is offline for 6 blocks, then
+	// comes back online sealing block 7
+	accounts := newTesterAccountPool()
+	chain, engine, blocks := newTesterChain(t, accounts, []string{"A", "B", "C"}, []testerVote{
+		{signer: "B"}, {signer: "C"}, {signer: "B"}, {signer: "C"}, {signer: "B"}, {signer: "C"},
+		{signer: "A"},
+	})
+	defer chain.Stop()
+
+	signer := accounts.address("A")
+	check := func(s *standby, parent *types.Block) bool {
+		snap, err := engine.snapshot(chain, parent.NumberU64(), parent.Hash(), nil)
+		if err != nil {
+			t.Fatalf("failed to retrieve snapshot: %v", err)
+		}
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), big.NewInt(1)),
+		}
+		return s.check(chain, snap, header, signer)
+	}
+	// Six blocks contain two in-turn slots of A, which is not enough to take
+	// over with a higher threshold
+	s := newStandby(3, rawdb.NewMemoryDatabase())
+	if check(s, blocks[5]) {
+		t.Fatalf("standby took over early")
+	}
+	if status := s.status(); status.Active || status.Missed != 2 {
+		t.Fatalf("standby status mismatch: have %+v, want inactive with 2 missed", status)
+	}
+	// With a threshold of two missed slots, the standby must take over
+	s = newStandby(2, rawdb.NewMemoryDatabase())
+	if !check(s, blocks[5]) {
+		t.Fatalf("standby failed to take over")
+	}
+	if status := s.status(); !status.Active || status.Since != 7 {
+		t.Fatalf("standby status mismatch: have %+v, want active since 7", status)
+	}
+	// Block 7 was not sealed by the standby, so the primary is back
+	if check(s, blocks[6]) {
+		t.Fatalf("standby failed to step back")
+	}
+	if status := s.status(); status.Active {
+		t.Fatalf("standby still active after primary returned")
+	}
+	// Had the standby sealed block 7 itself, it must have stayed active
+	s = newStandby(2, rawdb.NewMemoryDatabase())
+	if !check(s, blocks[5]) {
+		t.Fatalf("standby failed to take over")
+	}
+	s.seal(blocks[6].Hash())
+	if !check(s, blocks[6]) {
+		t.Fatalf("standby stepped back on its own block")
+	}
+	// A restarted standby must recognize its own block from the database and
+	// take over right away
+	db := s.db
+	s = newStandby(2, db)
+	if !check(s, blocks[6]) {
+		t.Fatalf("restarted standby failed to resume")
+	}
+	if status := s.status(); !status.Active || status.Missed != 2 {
+		t.Fatalf("standby status mismatch: have %+v, want active with 2 missed", status)
+	}
+}
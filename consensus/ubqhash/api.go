@@ -106,6 +106,15 @@ func (api *API) SubmitHashrate(rate hexutil.Uint64, id common.Hash) bool {
 	return true
 }
 
+// GetStratumStats returns the share accounting of the workers connected to the
+// built-in stratum mining server.
+func (api *API) GetStratumStats() (map[string]StratumStats, error) {
+	if api.ubqhash.stratum == nil {
+		return nil, errors.New("stratum server not running")
+	}
+	return api.ubqhash.stratum.workerStats(), nil
+}
+
 // GetHashrate returns the current hashrate for local CPU miner and remote miner.
 func (api *API) GetHashrate() uint64 {
 	return uint64(api.ubqhash.Hashrate())
@@ -386,17 +386,32 @@ func (ubqhash *Ubqhash) verifySeal(chain consensus.ChainHeaderReader, header *ty
 		return errInvalidDifficulty
 	}
 	// Recompute the digest and PoW values
-	number := header.Number.Uint64()
+	digest, result := ubqhash.hashimoto(header.Number.Uint64(), ubqhash.SealHash(header).Bytes(), header.Nonce.Uint64(), fulldag)
 
-	var (
-		digest []byte
-		result []byte
-	)
+	// Verify the calculated values against the ones provided in the header
+	if !bytes.Equal(header.MixDigest[:], digest) {
+		return errInvalidMixDigest
+	}
+	target := new(big.Int).Div(two256, header.Difficulty)
+	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
+		return errInvalidPoW
+	}
+	return nil
+}
+
+// hashimoto recomputes the mix digest and PoW result of the given seal hash and
+// nonce. If the fast-but-heavy full dataset is requested but not yet generated,
+// the slow-but-light verification cache is used instead.
+func (ubqhash *Ubqhash) hashimoto(number uint64, sealhash []byte, nonce uint64, fulldag bool) (digest []byte, result []byte) {
+	// If we're running a shared PoW, delegate hashing to it
+	if ubqhash.shared != nil {
+		return ubqhash.shared.hashimoto(number, sealhash, nonce, fulldag)
+	}
 	// If fast-but-heavy PoW verification was requested, use an ethash dataset
 	if fulldag {
 		dataset := ubqhash.dataset(number, true)
 		if dataset.generated() {
-			digest, result = hashimotoFull(dataset.dataset, ubqhash.SealHash(header).Bytes(), header.Nonce.Uint64())
+			digest, result = hashimotoFull(dataset.dataset, sealhash, nonce)
 
 			// Datasets are unmapped in a finalizer. Ensure that the dataset stays alive
 			// until after the call to hashimotoFull so it's not unmapped while being used.
@@ -414,21 +429,13 @@ func (ubqhash *Ubqhash) verifySeal(chain consensus.ChainHeaderReader, header *ty
 		if ubqhash.config.PowMode == ModeTest {
 			size = 32 * 1024
 		}
-		digest, result = hashimotoLight(size, cache.cache, ubqhash.SealHash(header).Bytes(), header.Nonce.Uint64())
+		digest, result = hashimotoLight(size, cache.cache, sealhash, nonce)
 
 		// Caches are unmapped in a finalizer. Ensure that the cache stays alive
 		// until after the call to hashimotoLight so it's not unmapped while being used.
 		runtime.KeepAlive(cache)
 	}
-	// Verify the calculated values against the ones provided in the header
-	if !bytes.Equal(header.MixDigest[:], digest) {
-		return errInvalidMixDigest
-	}
-	target := new(big.Int).Div(two256, header.Difficulty)
-	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
-		return errInvalidPoW
-	}
-	return nil
+	return digest, result
 }
 
 // Prepare implements consensus.Engine, initializing the difficulty field of a
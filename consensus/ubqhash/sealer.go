@@ -34,6 +34,7 @@ import (
 	"github.com/ubiq/go-ubiq/v7/common/hexutil"
 	"github.com/ubiq/go-ubiq/v7/consensus"
 	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/event"
 )
 
 const (
@@ -207,6 +208,7 @@ type remoteSealer struct {
 	submitWorkCh chan *mineResult // Channel used for remote sealer to submit their mining result
 	fetchRateCh  chan chan uint64 // Channel used to gather submitted hash rate for local or remote sealer.
 	submitRateCh chan *hashrate   // Channel used for remote sealer to submit their mining hashrate
+	workFeed     event.Feed       // Feed of new work packages for in-process work servers (e.g. stratum)
 	requestExit  chan struct{}
 	exitCh       chan struct{}
 }
@@ -282,6 +284,7 @@ func (s *remoteSealer) loop() {
 			s.results = work.results
 			s.makeWork(work.block)
 			s.notifyWork()
+			s.workFeed.Send(s.currentWork)
 
 		case work := <-s.fetchWorkCh:
 			// Return current mining work to remote miner.
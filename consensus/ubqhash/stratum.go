@@ -0,0 +1,667 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ubqhash
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/event"
+	"github.com/ubiq/go-ubiq/v7/log"
+)
+
+const (
+	// stratumProtocol is the protocol version announced to stratum clients.
+	stratumProtocol = "EthereumStratum/1.0.0"
+
+	// stratumExtranonceSize is the number of nonce bytes assigned by the server
+	// to each session, the remaining bytes are searched by the miner.
+	stratumExtranonceSize = 2
+
+	// stratumMaxLineSize is the maximum length of a single stratum request.
+	stratumMaxLineSize = 4096
+
+	// stratumWriteTimeout is the maximum time allowed to push a single message
+	// to a stratum client before it is disconnected.
+	stratumWriteTimeout = 5 * time.Second
+
+	// stratumSendQueue is the number of messages queued for a stratum client
+	// before it is considered too slow and disconnected.
+	stratumSendQueue = 16
+)
+
+// Stratum error codes, as used by the EthereumStratum/1.0.0 pool software.
+const (
+	stratumErrOther         = 20
+	stratumErrJobNotFound   = 21
+	stratumErrDuplicate     = 22
+	stratumErrLowDifficulty = 23
+	stratumErrUnauthorized  = 24
+	stratumErrNotSubscribed = 25
+)
+
+var (
+	// stratumDiff1 is the share difficulty of 1 in EthereumStratum terms, i.e.
+	// a difficulty of 1 corresponds to 2^32 hashes.
+	stratumDiff1 = new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 32))
+
+	errStratumStopped    = errors.New("stratum server stopped")
+	errStratumClientSlow = errors.New("stratum client too slow")
+)
+
+// StratumStats contains the share accounting of a single stratum worker.
+type StratumStats struct {
+	Valid     uint64    `json:"valid"`     // Number of shares meeting the share target
+	Stale     uint64    `json:"stale"`     // Number of shares submitted for outdated jobs
+	Invalid   uint64    `json:"invalid"`   // Number of shares rejected as invalid or duplicate
+	Blocks    uint64    `json:"blocks"`    // Number of shares accepted as block solutions
+	LastShare time.Time `json:"lastShare"` // Time of the last submitted share
+}
+
+// stratumJob is a single work package announced to stratum clients. Jobs are
+// identified by the seal hash of the block they mine.
+type stratumJob struct {
+	id       string
+	sealhash common.Hash
+	seed     common.Hash
+	target   *big.Int // Block boundary condition, 2^256/difficulty
+	number   uint64
+
+	lock   sync.Mutex
+	nonces map[uint64]struct{} // Nonces submitted for this job, for duplicate detection
+}
+
+// stratumRequest is a single line-delimited JSON request sent by a client.
+type stratumRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// stratumResponse is the reply to a stratumRequest.
+type stratumResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+// stratumNotification is a server initiated message without a request.
+type stratumNotification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// stratumSession is a single connected stratum client.
+type stratumSession struct {
+	conn       net.Conn
+	extranonce string      // Hex encoded nonce prefix assigned to the session
+	queue      chan []byte // Messages waiting to be written to the connection
+
+	lock       sync.Mutex // Protects the fields below and the order of queued messages
+	subscribed bool
+	authorized bool
+	worker     string
+	difficulty float64 // Last share difficulty sent to the client
+}
+
+// send queues a single JSON message for the client. Clients too slow to keep up
+// with their queue are disconnected instead of holding up the server.
+func (sess *stratumSession) send(msg interface{}) error {
+	blob, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	select {
+	case sess.queue <- append(blob, '\n'):
+		return nil
+	default:
+		sess.conn.Close()
+		return errStratumClientSlow
+	}
+}
+
+// writeLoop writes the queued messages to the client until the session ends.
+func (sess *stratumSession) writeLoop(done <-chan struct{}) {
+	for {
+		select {
+		case msg := <-sess.queue:
+			sess.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+			if _, err := sess.conn.Write(msg); err != nil {
+				sess.conn.Close()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// stratumServer is an EthereumStratum/1.0.0 mining endpoint driven by the work
+// packages and submission channels of the remote sealer.
+type stratumServer struct {
+	sealer     *remoteSealer
+	difficulty *big.Int // Share difficulty, nil or zero for block difficulty
+	log        log.Logger
+
+	lock       sync.RWMutex
+	job        *stratumJob            // Latest work package
+	jobs       map[string]*stratumJob // Recent work packages by id
+	sessions   map[*stratumSession]struct{}
+	stats      map[string]*StratumStats // Share accounting by worker name
+	extranonce uint16                   // Last assigned session extranonce
+
+	listener net.Listener
+	workCh   chan [4]string
+	workSub  event.Subscription
+	notifyCh chan [4]string // Latest work package, waiting to be announced
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// newStratumServer creates a stratum server subscribed to the work packages of
+// the given remote sealer. Clients are attached via listen or serveConn.
+func newStratumServer(sealer *remoteSealer, difficulty uint64) *stratumServer {
+	s := &stratumServer{
+		sealer:   sealer,
+		log:      sealer.ubqhash.config.Log,
+		jobs:     make(map[string]*stratumJob),
+		sessions: make(map[*stratumSession]struct{}),
+		stats:    make(map[string]*StratumStats),
+		workCh:   make(chan [4]string, 1),
+		notifyCh: make(chan [4]string, 1),
+		quit:     make(chan struct{}),
+	}
+	if difficulty > 0 {
+		s.difficulty = new(big.Int).SetUint64(difficulty)
+	}
+	s.workSub = sealer.workFeed.Subscribe(s.workCh)
+
+	s.wg.Add(2)
+	go s.loop()
+	go s.notifyLoop()
+	return s
+}
+
+// startStratumServer creates a stratum server and starts accepting clients on
+// the given TCP listening address.
+func startStratumServer(sealer *remoteSealer, addr string, difficulty uint64) (*stratumServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newStratumServer(sealer, difficulty)
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.accept()
+
+	s.log.Info("Stratum mining server started", "addr", listener.Addr(), "protocol", stratumProtocol)
+	return s, nil
+}
+
+// close terminates the listener and all client sessions.
+func (s *stratumServer) close() {
+	close(s.quit)
+	s.workSub.Unsubscribe()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.lock.Lock()
+	for sess := range s.sessions {
+		sess.conn.Close()
+	}
+	s.lock.Unlock()
+	s.wg.Wait()
+}
+
+// loop tracks the work packages produced by the remote sealer. Announcing work
+// to clients is left to notifyLoop, so slow clients can't hold up the feed, and
+// with it the remote sealer.
+func (s *stratumServer) loop() {
+	defer s.wg.Done()
+
+	// Pick up any work produced before the server was started. The fetch can't
+	// block the loop, since the sealer might be waiting for us to take new work.
+	initial := make(chan [4]string, 1)
+	s.wg.Add(1)
+	go func(initial chan<- [4]string) {
+		defer s.wg.Done()
+		if work, err := s.fetchWork(); err == nil {
+			initial <- work
+		}
+	}(initial)
+	for {
+		select {
+		case work := <-initial:
+			s.queueWork(work)
+		case work := <-s.workCh:
+			initial = nil // Newer than whatever was fetched
+			s.queueWork(work)
+		case <-s.workSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// queueWork schedules a work package for announcement, replacing any previous
+// package that wasn't announced yet.
+func (s *stratumServer) queueWork(work [4]string) {
+	for {
+		select {
+		case s.notifyCh <- work:
+			return
+		default:
+		}
+		select {
+		case <-s.notifyCh: // Drop stale work
+		default:
+		}
+	}
+}
+
+// notifyLoop announces the latest work package to all connected clients.
+func (s *stratumServer) notifyLoop() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case work := <-s.notifyCh:
+			s.setWork(work)
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// accept handles incoming TCP connections until the listener is closed.
+func (s *stratumServer) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				s.log.Warn("Stratum listener failed", "err", err)
+			}
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// fetchWork retrieves the current work package from the remote sealer.
+func (s *stratumServer) fetchWork() ([4]string, error) {
+	var (
+		workCh = make(chan [4]string, 1)
+		errc   = make(chan error, 1)
+	)
+	select {
+	case s.sealer.fetchWorkCh <- &sealWork{errc: errc, res: workCh}:
+	case <-s.sealer.exitCh:
+		return [4]string{}, errUbqhashStopped
+	case <-s.quit:
+		return [4]string{}, errStratumStopped
+	}
+	select {
+	case work := <-workCh:
+		return work, nil
+	case err := <-errc:
+		return [4]string{}, err
+	}
+}
+
+// submitWork hands a block solution over to the remote sealer, returning whether
+// it was accepted.
+func (s *stratumServer) submitWork(nonce types.BlockNonce, digest common.Hash, sealhash common.Hash) bool {
+	errc := make(chan error, 1)
+	select {
+	case s.sealer.submitWorkCh <- &mineResult{nonce: nonce, mixDigest: digest, hash: sealhash, errc: errc}:
+	case <-s.sealer.exitCh:
+		return false
+	}
+	return <-errc == nil
+}
+
+// setWork converts a work package into a stratum job and pushes it to all the
+// subscribed and authorized clients.
+func (s *stratumServer) setWork(work [4]string) {
+	number, err := hexutil.DecodeUint64(work[3])
+	if err != nil {
+		s.log.Warn("Invalid work package for stratum", "number", work[3], "err", err)
+		return
+	}
+	job := &stratumJob{
+		id:       strings.TrimPrefix(work[0], "0x"),
+		sealhash: common.HexToHash(work[0]),
+		seed:     common.HexToHash(work[1]),
+		target:   new(big.Int).SetBytes(common.HexToHash(work[2]).Bytes()),
+		number:   number,
+		nonces:   make(map[uint64]struct{}),
+	}
+	s.lock.Lock()
+	if s.job != nil && s.job.id == job.id {
+		s.lock.Unlock()
+		return
+	}
+	// Clean up jobs that can no longer be accepted by the remote sealer
+	for id, old := range s.jobs {
+		if old.number+staleThreshold <= job.number {
+			delete(s.jobs, id)
+		}
+	}
+	s.job, s.jobs[job.id] = job, job
+
+	sessions := make([]*stratumSession, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.lock.Unlock()
+
+	for _, sess := range sessions {
+		sess.lock.Lock()
+		if sess.subscribed && sess.authorized {
+			if err := s.sendJob(sess, job, true); err != nil {
+				s.log.Debug("Failed to notify stratum client", "addr", sess.conn.RemoteAddr(), "err", err)
+				sess.conn.Close()
+			}
+		}
+		sess.lock.Unlock()
+	}
+}
+
+// shareTarget returns the boundary a share needs to meet for the given job. The
+// share target is never stricter than the block target.
+func (s *stratumServer) shareTarget(job *stratumJob) *big.Int {
+	if s.difficulty == nil || s.difficulty.Sign() <= 0 {
+		return job.target
+	}
+	target := new(big.Int).Div(two256, s.difficulty)
+	if target.Cmp(job.target) < 0 {
+		return job.target
+	}
+	return target
+}
+
+// sendJob pushes the job (and the share difficulty if it changed) to a client.
+// The session lock is assumed to be held.
+func (s *stratumServer) sendJob(sess *stratumSession, job *stratumJob, clean bool) error {
+	difficulty, _ := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Div(two256, s.shareTarget(job))), stratumDiff1).Float64()
+	if difficulty != sess.difficulty {
+		if err := sess.send(&stratumNotification{Method: "mining.set_difficulty", Params: []interface{}{difficulty}}); err != nil {
+			return err
+		}
+		sess.difficulty = difficulty
+	}
+	return sess.send(&stratumNotification{
+		Method: "mining.notify",
+		Params: []interface{}{job.id, hex.EncodeToString(job.seed[:]), hex.EncodeToString(job.sealhash[:]), clean},
+	})
+}
+
+// serveConn runs the stratum protocol on a single client connection until it
+// is closed.
+func (s *stratumServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	s.lock.Lock()
+	select {
+	case <-s.quit:
+		s.lock.Unlock()
+		return
+	default:
+	}
+	s.extranonce++
+	sess := &stratumSession{
+		conn:       conn,
+		extranonce: fmt.Sprintf("%0*x", 2*stratumExtranonceSize, s.extranonce),
+		queue:      make(chan []byte, stratumSendQueue),
+	}
+	s.sessions[sess] = struct{}{}
+	s.lock.Unlock()
+
+	var (
+		done   = make(chan struct{})
+		writer sync.WaitGroup
+	)
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		sess.writeLoop(done)
+	}()
+	defer func() {
+		s.lock.Lock()
+		delete(s.sessions, sess)
+		s.lock.Unlock()
+
+		close(done)
+		writer.Wait()
+	}()
+	s.log.Debug("Stratum client connected", "addr", conn.RemoteAddr(), "extranonce", sess.extranonce)
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, stratumMaxLineSize), stratumMaxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var req stratumRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.log.Debug("Invalid stratum request", "addr", conn.RemoteAddr(), "err", err)
+			return
+		}
+		if err := s.handle(sess, &req); err != nil {
+			s.log.Debug("Stratum client dropped", "addr", conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+}
+
+// handle processes a single client request and writes the response.
+func (s *stratumServer) handle(sess *stratumSession, req *stratumRequest) error {
+	var params []string
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return sess.reply(req.ID, nil, stratumErrOther, "Invalid parameters")
+		}
+	}
+	switch req.Method {
+	case "mining.subscribe":
+		if len(params) > 1 && params[1] != stratumProtocol {
+			return sess.reply(req.ID, nil, stratumErrOther, "Unsupported protocol version")
+		}
+		sess.lock.Lock()
+		sess.subscribed = true
+		sess.lock.Unlock()
+
+		notify := []interface{}{"mining.notify", sess.extranonce, stratumProtocol}
+		return sess.reply(req.ID, []interface{}{notify, sess.extranonce}, 0, "")
+
+	case "mining.extranonce.subscribe":
+		return sess.reply(req.ID, true, 0, "")
+
+	case "mining.authorize":
+		sess.lock.Lock()
+		defer sess.lock.Unlock()
+
+		if !sess.subscribed {
+			return sess.replyLocked(req.ID, nil, stratumErrNotSubscribed, "Not subscribed")
+		}
+		if len(params) < 1 || params[0] == "" {
+			return sess.replyLocked(req.ID, false, stratumErrUnauthorized, "Unauthorized worker")
+		}
+		sess.authorized, sess.worker = true, params[0]
+
+		s.lock.Lock()
+		if _, ok := s.stats[sess.worker]; !ok {
+			s.stats[sess.worker] = new(StratumStats)
+		}
+		job := s.job
+		s.lock.Unlock()
+
+		if err := sess.replyLocked(req.ID, true, 0, ""); err != nil {
+			return err
+		}
+		if job != nil {
+			return s.sendJob(sess, job, true)
+		}
+		return nil
+
+	case "mining.submit":
+		if len(params) < 3 {
+			return sess.reply(req.ID, nil, stratumErrOther, "Invalid parameters")
+		}
+		accepted, code, msg := s.submit(sess, params[1], params[2])
+		return sess.reply(req.ID, accepted, code, msg)
+
+	default:
+		return sess.reply(req.ID, nil, stratumErrOther, "Method not found")
+	}
+}
+
+// submit validates a share and forwards any block solution to the remote sealer.
+// It returns whether the share was accepted, and the stratum error otherwise.
+func (s *stratumServer) submit(sess *stratumSession, id string, minerNonce string) (bool, int, string) {
+	sess.lock.Lock()
+	worker, authorized := sess.worker, sess.authorized
+	sess.lock.Unlock()
+
+	if !authorized {
+		return false, stratumErrUnauthorized, "Unauthorized worker"
+	}
+	s.lock.RLock()
+	job, current := s.jobs[id], s.job
+	stats := s.stats[worker]
+	s.lock.RUnlock()
+
+	account := func(update func(*StratumStats)) {
+		s.lock.Lock()
+		update(stats)
+		stats.LastShare = time.Now()
+		s.lock.Unlock()
+	}
+	if job == nil {
+		account(func(stats *StratumStats) { stats.Stale++ })
+		return false, stratumErrJobNotFound, "Job not found"
+	}
+	// Assemble the full nonce from the session prefix and the miner's part
+	minerNonce = strings.TrimPrefix(minerNonce, "0x")
+	if len(minerNonce) != 2*(8-stratumExtranonceSize) {
+		account(func(stats *StratumStats) { stats.Invalid++ })
+		return false, stratumErrOther, "Invalid nonce"
+	}
+	blob, err := hex.DecodeString(sess.extranonce + minerNonce)
+	if err != nil {
+		account(func(stats *StratumStats) { stats.Invalid++ })
+		return false, stratumErrOther, "Invalid nonce"
+	}
+	nonce := binary.BigEndian.Uint64(blob)
+
+	job.lock.Lock()
+	_, dup := job.nonces[nonce]
+	job.nonces[nonce] = struct{}{}
+	job.lock.Unlock()
+	if dup {
+		account(func(stats *StratumStats) { stats.Invalid++ })
+		return false, stratumErrDuplicate, "Duplicate share"
+	}
+	// Verify the share against the share and block targets. Only the verification
+	// cache is used, so miners can't make the node generate arbitrary datasets.
+	digest, result := s.sealer.ubqhash.hashimoto(job.number, job.sealhash.Bytes(), nonce, false)
+	value := new(big.Int).SetBytes(result)
+	if value.Cmp(s.shareTarget(job)) > 0 {
+		account(func(stats *StratumStats) { stats.Invalid++ })
+		return false, stratumErrLowDifficulty, "Low difficulty share"
+	}
+	stale := job != current
+	if value.Cmp(job.target) <= 0 {
+		// Block solution found, only accept the share if the sealer takes it
+		if !s.submitWork(types.EncodeNonce(nonce), common.BytesToHash(digest), job.sealhash) {
+			account(func(stats *StratumStats) {
+				if stale {
+					stats.Stale++
+				} else {
+					stats.Invalid++
+				}
+			})
+			return false, stratumErrOther, "Block solution rejected"
+		}
+		s.log.Info("Stratum worker found block", "worker", worker, "number", job.number, "sealhash", job.sealhash)
+	}
+	account(func(stats *StratumStats) {
+		if stale {
+			stats.Stale++
+		} else {
+			stats.Valid++
+		}
+		if value.Cmp(job.target) <= 0 {
+			stats.Blocks++
+		}
+	})
+	return true, 0, ""
+}
+
+// workerStats returns a copy of the share accounting of all workers.
+func (s *stratumServer) workerStats() map[string]StratumStats {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	stats := make(map[string]StratumStats, len(s.stats))
+	for worker, stat := range s.stats {
+		stats[worker] = *stat
+	}
+	return stats
+}
+
+// reply sends the response to a client request. A non-zero code sends a stratum
+// error instead of the result.
+func (sess *stratumSession) reply(id json.RawMessage, result interface{}, code int, msg string) error {
+	sess.lock.Lock()
+	defer sess.lock.Unlock()
+
+	return sess.replyLocked(id, result, code, msg)
+}
+
+// replyLocked is the same as reply, but assumes the session lock is held.
+func (sess *stratumSession) replyLocked(id json.RawMessage, result interface{}, code int, msg string) error {
+	res := &stratumResponse{ID: id, Result: result}
+	if code != 0 {
+		res.Error = []interface{}{code, msg, nil}
+	}
+	return sess.send(res)
+}
@@ -0,0 +1,386 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ubqhash
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/internal/testlog"
+	"github.com/ubiq/go-ubiq/v7/log"
+)
+
+// stratumTestClient is a loopback stratum client driving a stratum server
+// session over an in-memory pipe.
+type stratumTestClient struct {
+	t      *testing.T
+	conn   net.Conn
+	nextID int
+
+	responses     chan *stratumTestMessage
+	notifications chan *stratumTestMessage
+}
+
+// stratumTestMessage is the union of stratum responses and notifications.
+type stratumTestMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  []interface{}   `json:"error"`
+}
+
+func newStratumTestClient(t *testing.T, s *stratumServer) *stratumTestClient {
+	server, client := net.Pipe()
+	go s.serveConn(server)
+
+	c := &stratumTestClient{
+		t:             t,
+		conn:          client,
+		responses:     make(chan *stratumTestMessage, 16),
+		notifications: make(chan *stratumTestMessage, 16),
+	}
+	go func() {
+		scanner := bufio.NewScanner(client)
+		for scanner.Scan() {
+			msg := new(stratumTestMessage)
+			if err := json.Unmarshal(scanner.Bytes(), msg); err != nil {
+				t.Errorf("failed to decode stratum message %q: %v", scanner.Text(), err)
+				return
+			}
+			if msg.ID == nil {
+				c.notifications <- msg
+			} else {
+				c.responses <- msg
+			}
+		}
+	}()
+	return c
+}
+
+// call sends a request and waits for its response.
+func (c *stratumTestClient) call(method string, params ...interface{}) *stratumTestMessage {
+	c.nextID++
+	blob, _ := json.Marshal(map[string]interface{}{"id": c.nextID, "method": method, "params": params})
+	if _, err := c.conn.Write(append(blob, '\n')); err != nil {
+		c.t.Fatalf("failed to send %s: %v", method, err)
+	}
+	select {
+	case res := <-c.responses:
+		if *res.ID != c.nextID {
+			c.t.Fatalf("response id mismatch: have %d, want %d", *res.ID, c.nextID)
+		}
+		return res
+	case <-time.After(3 * time.Second):
+		c.t.Fatalf("%s timed out", method)
+	}
+	return nil
+}
+
+// notification waits for the next server notification of the given method.
+func (c *stratumTestClient) notification(method string) []interface{} {
+	select {
+	case msg := <-c.notifications:
+		if msg.Method != method {
+			c.t.Fatalf("notification method mismatch: have %s, want %s", msg.Method, method)
+		}
+		var params []interface{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			c.t.Fatalf("failed to decode %s params: %v", method, err)
+		}
+		return params
+	case <-time.After(3 * time.Second):
+		c.t.Fatalf("%s notification timed out", method)
+	}
+	return nil
+}
+
+// searchNonce looks for the miner part of a nonce whose PoW result satisfies
+// (or fails, if valid is false) the given target.
+func searchNonce(ubqhash *Ubqhash, number uint64, sealhash common.Hash, extranonce uint64, target *big.Int, valid bool) string {
+	for nonce := uint64(0); ; nonce++ {
+		_, result := ubqhash.hashimoto(number, sealhash.Bytes(), extranonce<<48|nonce, false)
+		if (new(big.Int).SetBytes(result).Cmp(target) <= 0) == valid {
+			return fmt.Sprintf("0x%012x", nonce)
+		}
+	}
+}
+
+// Tests that a stratum client can subscribe, receive jobs and submit shares
+// which are turned into sealed blocks by the remote sealer.
+func TestStratumMining(t *testing.T) {
+	ubqhash := NewTester(nil, false)
+	ubqhash.config.Log = testlog.Logger(t, log.LvlWarn)
+	ubqhash.SetThreads(-1)
+	defer ubqhash.Close()
+
+	stratum := newStratumServer(ubqhash.remote, 0)
+	defer stratum.close()
+
+	client := newStratumTestClient(t, stratum)
+
+	// Submitting without authorization must be rejected
+	if res := client.call("mining.submit", "worker", "00", "0x000000000000"); res.Error == nil || res.Error[0].(float64) != stratumErrUnauthorized {
+		t.Fatalf("unauthorized submit error mismatch: have %v, want code %d", res.Error, stratumErrUnauthorized)
+	}
+	// Subscribe and authorize the worker
+	res := client.call("mining.subscribe", "tester", stratumProtocol)
+	var subscription []interface{}
+	if err := json.Unmarshal(res.Result, &subscription); err != nil || len(subscription) != 2 {
+		t.Fatalf("invalid subscription result: %s", res.Result)
+	}
+	if extranonce := subscription[1]; extranonce != "0001" {
+		t.Fatalf("extranonce mismatch: have %v, want %v", extranonce, "0001")
+	}
+	if res := client.call("mining.authorize", "worker", "x"); string(res.Result) != "true" {
+		t.Fatalf("authorization failed: %s %v", res.Result, res.Error)
+	}
+	// Push a work package and wait for the job to arrive
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	block := types.NewBlockWithHeader(header)
+	results := make(chan *types.Block, 1)
+	ubqhash.Seal(nil, block, results, nil)
+
+	diff := client.notification("mining.set_difficulty")
+	if want, _ := new(big.Float).Quo(new(big.Float).SetInt(header.Difficulty), stratumDiff1).Float64(); diff[0] != want {
+		t.Errorf("share difficulty mismatch: have %v, want %v", diff[0], want)
+	}
+	job := client.notification("mining.notify")
+	sealhash := ubqhash.SealHash(header)
+	if job[2] != common.Bytes2Hex(sealhash[:]) {
+		t.Fatalf("job header hash mismatch: have %v, want %x", job[2], sealhash)
+	}
+	if job[1] != common.Bytes2Hex(SeedHash(1)) {
+		t.Fatalf("job seed hash mismatch: have %v, want %x", job[1], SeedHash(1))
+	}
+	target := new(big.Int).Div(two256, header.Difficulty)
+
+	// Submit a share below the target, it must be rejected
+	bad := searchNonce(ubqhash, 1, sealhash, 1, target, false)
+	if res := client.call("mining.submit", "worker", job[0], bad); res.Error == nil || res.Error[0].(float64) != stratumErrLowDifficulty {
+		t.Fatalf("low difficulty error mismatch: have %v, want code %d", res.Error, stratumErrLowDifficulty)
+	}
+	// Submit a share for an unknown job, it must be rejected
+	if res := client.call("mining.submit", "worker", "deadbeef", bad); res.Error == nil || res.Error[0].(float64) != stratumErrJobNotFound {
+		t.Fatalf("unknown job error mismatch: have %v, want code %d", res.Error, stratumErrJobNotFound)
+	}
+	// Submit a valid solution and ensure the sealed block bubbles out
+	good := searchNonce(ubqhash, 1, sealhash, 1, target, true)
+	if res := client.call("mining.submit", "worker", job[0], good); string(res.Result) != "true" {
+		t.Fatalf("valid share rejected: %v", res.Error)
+	}
+	select {
+	case sealed := <-results:
+		if sealed.NumberU64() != 1 {
+			t.Errorf("sealed block number mismatch: have %d, want 1", sealed.NumberU64())
+		}
+		if err := ubqhash.verifySeal(nil, sealed.Header(), false); err != nil {
+			t.Errorf("sealed block failed verification: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("sealed block timed out")
+	}
+	// Resubmitting the same share must be detected
+	if res := client.call("mining.submit", "worker", job[0], good); res.Error == nil || res.Error[0].(float64) != stratumErrDuplicate {
+		t.Fatalf("duplicate share error mismatch: have %v, want code %d", res.Error, stratumErrDuplicate)
+	}
+	want := StratumStats{Valid: 1, Blocks: 1, Stale: 1, Invalid: 2}
+	have := stratum.workerStats()["worker"]
+	have.LastShare = time.Time{}
+	if have != want {
+		t.Errorf("share accounting mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+// Tests that shares meeting a configured share difficulty are accounted for,
+// without being submitted as block solutions.
+func TestStratumShareDifficulty(t *testing.T) {
+	ubqhash := NewTester(nil, false)
+	ubqhash.config.Log = testlog.Logger(t, log.LvlWarn)
+	ubqhash.SetThreads(-1)
+	defer ubqhash.Close()
+
+	// Push the work package before the server starts, it must be picked up
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1000000)}
+	block := types.NewBlockWithHeader(header)
+	results := make(chan *types.Block, 1)
+	ubqhash.Seal(nil, block, results, nil)
+
+	stratum := newStratumServer(ubqhash.remote, 10)
+	defer stratum.close()
+
+	client := newStratumTestClient(t, stratum)
+	client.call("mining.subscribe", "tester", stratumProtocol)
+	client.call("mining.authorize", "worker", "x")
+
+	diff := client.notification("mining.set_difficulty")
+	if want, _ := new(big.Float).Quo(big.NewFloat(10), stratumDiff1).Float64(); diff[0] != want {
+		t.Errorf("share difficulty mismatch: have %v, want %v", diff[0], want)
+	}
+	job := client.notification("mining.notify")
+	sealhash := ubqhash.SealHash(header)
+
+	// Submit a share meeting the share target, but not the block target
+	var (
+		shareTarget = new(big.Int).Div(two256, big.NewInt(10))
+		blockTarget = new(big.Int).Div(two256, header.Difficulty)
+	)
+	for nonce := uint64(0); ; nonce++ {
+		_, result := ubqhash.hashimoto(1, sealhash.Bytes(), 1<<48|nonce, false)
+		if value := new(big.Int).SetBytes(result); value.Cmp(shareTarget) <= 0 && value.Cmp(blockTarget) > 0 {
+			if res := client.call("mining.submit", "worker", job[0], fmt.Sprintf("%012x", nonce)); string(res.Result) != "true" {
+				t.Fatalf("valid share rejected: %v", res.Error)
+			}
+			break
+		}
+	}
+	select {
+	case <-results:
+		t.Fatalf("share submitted as block solution")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if stats := stratum.workerStats()["worker"]; stats.Valid != 1 || stats.Blocks != 0 {
+		t.Errorf("share accounting mismatch: have %+v, want 1 valid and 0 blocks", stats)
+	}
+}
+
+// Tests that block solutions rejected by the remote sealer are reported to the
+// worker and not accounted as valid shares.
+func TestStratumRejectedBlock(t *testing.T) {
+	ubqhash := NewTester(nil, false)
+	ubqhash.config.Log = testlog.Logger(t, log.LvlWarn)
+	ubqhash.SetThreads(-1)
+	defer ubqhash.Close()
+
+	stratum := newStratumServer(ubqhash.remote, 0)
+	defer stratum.close()
+
+	client := newStratumTestClient(t, stratum)
+	client.call("mining.subscribe", "tester", stratumProtocol)
+	client.call("mining.authorize", "worker", "x")
+
+	// Push a work package whose result channel is full, so the sealer can't
+	// accept any solution for it
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	results := make(chan *types.Block, 1)
+	results <- types.NewBlockWithHeader(&types.Header{})
+	ubqhash.Seal(nil, types.NewBlockWithHeader(header), results, nil)
+
+	client.notification("mining.set_difficulty")
+	job := client.notification("mining.notify")
+
+	sealhash := ubqhash.SealHash(header)
+	good := searchNonce(ubqhash, 1, sealhash, 1, new(big.Int).Div(two256, header.Difficulty), true)
+	if res := client.call("mining.submit", "worker", job[0], good); res.Error == nil || res.Error[0].(float64) != stratumErrOther {
+		t.Fatalf("rejected block error mismatch: have %v, want code %d", res.Error, stratumErrOther)
+	}
+	want := StratumStats{Invalid: 1}
+	have := stratum.workerStats()["worker"]
+	have.LastShare = time.Time{}
+	if have != want {
+		t.Errorf("share accounting mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+// Tests that clients which stop reading don't hold up the remote sealer or the
+// other clients, and are disconnected once their send queue fills up.
+func TestStratumStalledClient(t *testing.T) {
+	ubqhash := NewTester(nil, false)
+	ubqhash.config.Log = testlog.Logger(t, log.LvlWarn)
+	ubqhash.SetThreads(-1)
+	defer ubqhash.Close()
+
+	stratum := newStratumServer(ubqhash.remote, 0)
+	defer stratum.close()
+
+	// Subscribe and authorize a client, then stop reading from it
+	server, client := net.Pipe()
+	defer client.Close()
+	go stratum.serveConn(server)
+
+	reader := bufio.NewReader(client)
+	for i, req := range []string{
+		`{"id":1,"method":"mining.subscribe","params":["tester","` + stratumProtocol + `"]}`,
+		`{"id":2,"method":"mining.authorize","params":["worker","x"]}`,
+	} {
+		if _, err := client.Write([]byte(req + "\n")); err != nil {
+			t.Fatalf("failed to send request %d: %v", i, err)
+		}
+		if _, err := reader.ReadString('\n'); err != nil {
+			t.Fatalf("failed to read response %d: %v", i, err)
+		}
+	}
+	// Attach a second client which keeps reading
+	live := newStratumTestClient(t, stratum)
+	live.call("mining.subscribe", "tester", stratumProtocol)
+	live.call("mining.authorize", "live", "x")
+
+	// Push more work packages than the stalled client can queue, the remote
+	// sealer and the live client must stay responsive
+	for i := int64(1); i <= 2*stratumSendQueue; i++ {
+		header := &types.Header{Number: big.NewInt(i), Difficulty: big.NewInt(100)}
+		ubqhash.Seal(nil, types.NewBlockWithHeader(header), make(chan *types.Block, 1), nil)
+
+		sealhash := ubqhash.SealHash(header)
+		for {
+			select {
+			case msg := <-live.notifications:
+				var params []interface{}
+				if err := json.Unmarshal(msg.Params, &params); err != nil {
+					t.Fatalf("failed to decode %s params: %v", msg.Method, err)
+				}
+				if msg.Method != "mining.notify" || params[2] != common.Bytes2Hex(sealhash[:]) {
+					continue
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("work package %d not announced to live client", i)
+			}
+			break
+		}
+	}
+	done := make(chan [4]string, 1)
+	go func() {
+		work, _ := stratum.fetchWork()
+		done <- work
+	}()
+	select {
+	case work := <-done:
+		if want := hexutil.EncodeUint64(2 * stratumSendQueue); work[3] != want {
+			t.Errorf("work package number mismatch: have %s, want %s", work[3], want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("remote sealer blocked by stalled stratum client")
+	}
+	// The stalled client must have been disconnected
+	client.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			if err, ok := err.(net.Error); ok && err.Timeout() {
+				t.Fatalf("stalled client not disconnected")
+			}
+			break
+		}
+	}
+}
@@ -429,6 +429,12 @@ type Config struct {
 	// be block header JSON objects instead of work package arrays.
 	NotifyFull bool
 
+	// When set, a stratum mining server is started on the given listening
+	// address, handing out work with the given share difficulty (defaulting
+	// to the block difficulty if zero).
+	StratumAddr       string
+	StratumDifficulty uint64
+
 	Log log.Logger `toml:"-"`
 	// UIP-1 - ubqhash
 	UIP1Epoch uint64 `toml:"-"`
@@ -448,6 +454,7 @@ type Ubqhash struct {
 	update   chan struct{} // Notification channel to update mining parameters
 	hashrate metrics.Meter // Meter tracking the average hashrate
 	remote   *remoteSealer
+	stratum  *stratumServer
 
 	// The fields below are hooks for testing
 	shared    *Ubqhash      // Shared PoW verifier to avoid cache regeneration
@@ -486,6 +493,13 @@ func New(config Config, notify []string, noverify bool) *Ubqhash {
 		ubqhash.shared = sharedUbqhash
 	}
 	ubqhash.remote = startRemoteSealer(ubqhash, notify, noverify)
+	if config.StratumAddr != "" {
+		stratum, err := startStratumServer(ubqhash.remote, config.StratumAddr, config.StratumDifficulty)
+		if err != nil {
+			config.Log.Error("Failed to start stratum mining server", "addr", config.StratumAddr, "err", err)
+		}
+		ubqhash.stratum = stratum
+	}
 	return ubqhash
 }
 
@@ -557,6 +571,9 @@ func (ubqhash *Ubqhash) Close() error {
 		if ubqhash.remote == nil {
 			return
 		}
+		if ubqhash.stratum != nil {
+			ubqhash.stratum.close()
+		}
 		close(ubqhash.remote.requestExit)
 		<-ubqhash.remote.exitCh
 	})
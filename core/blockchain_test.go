@@ -17,6 +17,7 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -24,6 +25,7 @@ import (
 	"math/rand"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -2549,6 +2551,112 @@ func TestSkipStaleTxIndicesInSnapSync(t *testing.T) {
 	check(&tail, chain)
 }
 
+// prunedBodyTracker is a database wrapper counting the lookups of block bodies
+// pruned below the history tail.
+type prunedBodyTracker struct {
+	ethdb.Database
+	tail  uint64
+	reads int32
+}
+
+func (db *prunedBodyTracker) Get(key []byte) ([]byte, error) {
+	if len(key) == 1+8+common.HashLength && key[0] == 'b' {
+		// The genesis body is retained in the key-value store
+		if number := binary.BigEndian.Uint64(key[1:9]); number > 0 && number < db.tail {
+			atomic.AddInt32(&db.reads, 1)
+		}
+	}
+	return db.Database.Get(key)
+}
+
+// Tests that the transaction indexer doesn't try to reindex the block bodies
+// pruned from the ancient store.
+func TestTransactionIndicesPrunedHistory(t *testing.T) {
+	// Configure and generate a sample block chain
+	var (
+		gendb   = rawdb.NewMemoryDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(1000000000000000000)
+		gspec   = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   GenesisAlloc{address: {Balance: funds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, receipts := GenerateChain(gspec.Config, genesis, ubqhash.NewFaker(), gendb, 128, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x00}, big.NewInt(1000), params.TxGas, block.header.BaseFee, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	frdir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temp freezer dir: %v", err)
+	}
+	defer os.RemoveAll(frdir)
+	ancientDb, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), frdir, "", false)
+	if err != nil {
+		t.Fatalf("failed to create temp freezer db: %v", err)
+	}
+	defer ancientDb.Close()
+	gspec.MustCommit(ancientDb)
+
+	// Import all blocks into ancient db with all transactions indexed
+	l := uint64(0)
+	chain, err := NewBlockChain(ancientDb, nil, params.TestChainConfig, ubqhash.NewFaker(), vm.Config{}, nil, &l)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := chain.InsertHeaderChain(headers, 0); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, 128); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	chain.Stop()
+
+	// Prune the history of the first half of the chain, as db prune-history does
+	tail := uint64(64)
+	rawdb.UnindexTransactions(ancientDb, 0, tail, nil)
+	if err := rawdb.PruneHistory(ancientDb, tail); err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	// Restart the chain with all transactions to be indexed, the pruned bodies
+	// must not be looked up while the indexer follows the chain head
+	db := &prunedBodyTracker{Database: ancientDb, tail: tail}
+	chain, err = NewBlockChain(db, nil, params.TestChainConfig, ubqhash.NewFaker(), vm.Config{}, nil, &l)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for i := 0; i < 10; i++ {
+		chain.chainHeadFeed.Send(ChainHeadEvent{Block: blocks[len(blocks)-1]})
+		time.Sleep(10 * time.Millisecond) // Wait for the indexer to catch up
+	}
+	if reads := atomic.LoadInt32(&db.reads); reads != 0 {
+		t.Errorf("pruned block bodies looked up %d times", reads)
+	}
+	if stored := rawdb.ReadTxIndexTail(db); stored == nil || *stored != tail {
+		t.Fatalf("Oldest indexed block mismatch, want %d, have %v", tail, stored)
+	}
+	for i := tail; i <= blocks[len(blocks)-1].NumberU64(); i++ {
+		for _, tx := range blocks[i-1].Transactions() {
+			if index := rawdb.ReadTxLookupEntry(db, tx.Hash()); index == nil {
+				t.Fatalf("Miss transaction indice, number %d hash %s", i, tx.Hash().Hex())
+			}
+		}
+	}
+}
+
 // Benchmarks large blocks with value transfers to non-existing accounts
 func benchmarkLargeNumberOfValueToNonexisting(b *testing.B, numTxs, numBlocks int, recipientFn func(uint64) common.Address, dataFn func(uint64) []byte) {
 	var (
@@ -41,3 +41,47 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// TxDropReason describes why a transaction left the transaction pool.
+type TxDropReason string
+
+const (
+	// TxDropMined is reported for transactions included in the canonical chain.
+	TxDropMined TxDropReason = "mined"
+
+	// TxDropReplaced is reported for transactions replaced by another one with
+	// the same sender and nonce, but a higher price.
+	TxDropReplaced TxDropReason = "replaced"
+
+	// TxDropEvicted is reported for transactions dropped to keep the pool or
+	// a single account within the configured capacity limits.
+	TxDropEvicted TxDropReason = "evicted"
+
+	// TxDropUnderpriced is reported for transactions dropped in favour of better
+	// priced ones when the pool is full, or below a raised minimum gas price.
+	TxDropUnderpriced TxDropReason = "underpriced"
+
+	// TxDropNonceTooLow is reported for transactions invalidated by a different
+	// transaction with the same nonce being included in the chain.
+	TxDropNonceTooLow TxDropReason = "nonceTooLow"
+
+	// TxDropUnpayable is reported for transactions the sender can no longer pay
+	// for, or which exceed the block gas limit.
+	TxDropUnpayable TxDropReason = "unpayable"
+
+	// TxDropExpired is reported for queued transactions of accounts which have
+	// been inactive longer than the configured lifetime.
+	TxDropExpired TxDropReason = "expired"
+)
+
+// DroppedTx is a transaction which left the transaction pool, along with the
+// reason and, for replaced transactions, the hash of the replacement.
+type DroppedTx struct {
+	Tx          *types.Transaction
+	Reason      TxDropReason
+	Replacement common.Hash
+}
+
+// DroppedTxsEvent is posted when a batch of transactions leave the transaction
+// pool.
+type DroppedTxsEvent struct{ Txs []*DroppedTx }
@@ -429,7 +429,9 @@ func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(freezerBodiesTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
 		}
 		// If not, try reading from leveldb
 		data, _ = db.Get(blockBodyKey(number, hash))
@@ -569,7 +571,9 @@ func ReadReceiptsRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawVa
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(freezerReceiptTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
 		}
 		// If not, try reading from leveldb
 		data, _ = db.Get(blockReceiptsKey(number, hash))
@@ -983,3 +987,38 @@ func ReadHeadBlock(db ethdb.Reader) *types.Block {
 	}
 	return ReadBlock(db, headBlockHash, *headBlockNumber)
 }
+
+// ReadHistoryTail retrieves the number of the oldest block whose body and
+// receipts are still retained in the ancient store.
+func ReadHistoryTail(db ethdb.AncientReader) (uint64, error) {
+	return db.AncientTail(freezerBodiesTable)
+}
+
+// PruneHistory discards the block bodies and receipts below the given number
+// from the ancient store. Headers, canonical hashes and total difficulties are
+// retained, so the chain itself stays verifiable. The genesis block is moved
+// into the key-value store, as it is needed to open the chain.
+func PruneHistory(db ethdb.Database, tail uint64) error {
+	if tail == 0 {
+		return nil
+	}
+	if current, err := ReadHistoryTail(db); err != nil {
+		return err
+	} else if current == 0 {
+		hash := ReadCanonicalHash(db, 0)
+		if body := ReadBodyRLP(db, hash, 0); len(body) > 0 {
+			WriteBodyRLP(db, hash, 0, body)
+		}
+		if receipts := ReadReceiptsRLP(db, hash, 0); len(receipts) > 0 {
+			if err := db.Put(blockReceiptsKey(0, hash), receipts); err != nil {
+				return err
+			}
+		}
+	}
+	for _, kind := range []string{freezerBodiesTable, freezerReceiptTable} {
+		if err := db.TruncateTail(kind, tail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
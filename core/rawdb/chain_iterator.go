@@ -83,6 +83,16 @@ func InitDatabaseFromFreezer(db ethdb.Database) {
 	log.Info("Initialized database from freezer", "blocks", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
 }
 
+// historyTail returns the number of the oldest block whose body is retained in
+// the database, any older ones having been pruned from the ancient store.
+func historyTail(db ethdb.Database) uint64 {
+	tail, err := ReadHistoryTail(db)
+	if err != nil {
+		return 0 // No ancient store, nothing pruned
+	}
+	return tail
+}
+
 type blockTxHashes struct {
 	number uint64
 	hashes []common.Hash
@@ -178,6 +188,10 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
 func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
+	// Pruned block bodies can't be indexed, skip them
+	if tail := historyTail(db); from < tail {
+		from = tail
+	}
 	// short circuit for invalid range
 	if from >= to {
 		return
@@ -270,6 +284,10 @@ func indexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, inte
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
 func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
+	// Pruned block bodies were unindexed before pruning, skip them
+	if tail := historyTail(db); from < tail {
+		from = tail
+	}
 	// short circuit for invalid range
 	if from >= to {
 		return
@@ -15,3 +15,46 @@
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
 package rawdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Tests that the engine of a pre-existing database is detected and recorded,
+// and that opening it with a different engine is refused.
+func TestOpenDatabaseEngine(t *testing.T) {
+	for _, engine := range []string{dbLeveldb, dbPebble} {
+		dir, err := ioutil.TempDir("", "rawdb-engine")
+		if err != nil {
+			t.Fatalf("failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		db, err := Open(OpenOptions{Type: engine, Directory: dir})
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		if stored := ReadDatabaseEngine(db); stored != engine {
+			t.Errorf("%s: stored engine mismatch: have %q", engine, stored)
+		}
+		db.Close()
+
+		if have := PreexistingDatabase(dir); have != engine {
+			t.Errorf("%s: detected engine mismatch: have %q", engine, have)
+		}
+		other := dbPebble
+		if engine == dbPebble {
+			other = dbLeveldb
+		}
+		if _, err := Open(OpenOptions{Type: other, Directory: dir}); err == nil {
+			t.Errorf("%s: reopened as %s", engine, other)
+		}
+		db, err = Open(OpenOptions{Directory: dir})
+		if err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		db.Close()
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"sync"
@@ -44,6 +45,14 @@ var (
 
 	// errNotSupported is returned if the database doesn't support the required operation.
 	errNotSupported = errors.New("this operation is not supported")
+
+	// errTruncationBelowTail is returned if the user attempts to truncate the head
+	// of a freezer table below its already deleted tail.
+	errTruncationBelowTail = errors.New("truncation below tail")
+
+	// errTruncationAboveHead is returned if the user attempts to truncate the tail
+	// of a freezer table above its head.
+	errTruncationAboveHead = errors.New("truncation above head")
 )
 
 // indexEntry contains the number/id of the file that the data resides in, aswell as the
@@ -56,6 +65,12 @@ type indexEntry struct {
 
 const indexEntrySize = 6
 
+// indexHiddenFlag is set on the file number of index zero if the items deleted
+// from the tail did not end at a data file boundary. In that case index one does
+// not belong to any item, rather it holds the position of the first retained item
+// within the tail data file, all bytes preceding it belonging to hidden items.
+const indexHiddenFlag = 0x8000
+
 // unmarshalBinary deserializes binary b into the rawIndex entry.
 func (i *indexEntry) unmarshalBinary(b []byte) error {
 	i.filenum = uint32(binary.BigEndian.Uint16(b[:2]))
@@ -108,6 +123,7 @@ type freezerTable struct {
 	// In the case that old items are deleted (from the tail), we use itemOffset
 	// to count how many historic items have gone missing.
 	itemOffset uint32 // Offset (number of discarded items)
+	hidden     bool   // Whether discarded items still occupy the start of the tail file
 
 	headBytes  int64         // Number of bytes written to the head file
 	readMeter  metrics.Meter // Meter for measuring the effective amount of data read
@@ -247,11 +263,21 @@ func (t *freezerTable) repair() error {
 	t.index.ReadAt(buffer, 0)
 	firstIndex.unmarshalBinary(buffer)
 
-	t.tailId = firstIndex.filenum
+	t.tailId = firstIndex.filenum &^ indexHiddenFlag
 	t.itemOffset = firstIndex.offset
+	t.hidden = firstIndex.filenum&indexHiddenFlag != 0
 
-	t.index.ReadAt(buffer, offsetsSize-indexEntrySize)
-	lastIndex.unmarshalBinary(buffer)
+	// Index zero (and the hidden item boundary, if any) precede the item indexes
+	minOffsetsSize := int64(indexEntrySize)
+	if t.hidden {
+		minOffsetsSize += indexEntrySize
+	}
+	if offsetsSize < minOffsetsSize {
+		return fmt.Errorf("index file too short: have %d bytes, want at least %d", offsetsSize, minOffsetsSize)
+	}
+	if lastIndex, err = t.lastIndex(buffer, offsetsSize); err != nil {
+		return err
+	}
 	t.head, err = t.openFile(lastIndex.filenum, openFreezerFileForAppend)
 	if err != nil {
 		return err
@@ -275,14 +301,18 @@ func (t *freezerTable) repair() error {
 		}
 		// Truncate the index to point within the head file
 		if contentExp > contentSize {
+			if offsetsSize == minOffsetsSize {
+				return fmt.Errorf("missing tail data: indexed %d, stored %d", contentExp, contentSize)
+			}
 			t.logger.Warn("Truncating dangling indexes", "indexed", common.StorageSize(contentExp), "stored", common.StorageSize(contentSize))
 			if err := truncateFreezerFile(t.index, offsetsSize-indexEntrySize); err != nil {
 				return err
 			}
 			offsetsSize -= indexEntrySize
-			t.index.ReadAt(buffer, offsetsSize-indexEntrySize)
-			var newLastIndex indexEntry
-			newLastIndex.unmarshalBinary(buffer)
+			newLastIndex, err := t.lastIndex(buffer, offsetsSize)
+			if err != nil {
+				return err
+			}
 			// We might have slipped back into an earlier head-file here
 			if newLastIndex.filenum != lastIndex.filenum {
 				// Release earlier opened file
@@ -309,7 +339,7 @@ func (t *freezerTable) repair() error {
 		return err
 	}
 	// Update the item and byte counters and return
-	t.items = uint64(t.itemOffset) + uint64(offsetsSize/indexEntrySize-1) // last indexEntry points to the end of the data file
+	t.items = uint64(t.itemOffset) + uint64((offsetsSize-minOffsetsSize)/indexEntrySize) // last indexEntry points to the end of the data file
 	t.headBytes = contentSize
 	t.headId = lastIndex.filenum
 
@@ -321,6 +351,34 @@ func (t *freezerTable) repair() error {
 	return nil
 }
 
+// lastIndex reads the last entry of an index file of the given size. If the
+// index contains no items, the start of the tail file is returned instead of
+// index zero. The provided buffer is used for reading.
+func (t *freezerTable) lastIndex(buffer []byte, offsetsSize int64) (indexEntry, error) {
+	var entry indexEntry
+	if offsetsSize == indexEntrySize {
+		entry.filenum = t.tailId
+		return entry, nil
+	}
+	if _, err := t.index.ReadAt(buffer, offsetsSize-indexEntrySize); err != nil {
+		return entry, err
+	}
+	entry.unmarshalBinary(buffer)
+	return entry, nil
+}
+
+// indexPosition returns the position within the index file of the entry marking
+// the end of the given item, which is also the start of the next one. Item
+// numbers down to one below the tail are accepted, in which case the position
+// of index zero or of the hidden item boundary is returned.
+func (t *freezerTable) indexPosition(item uint64) int64 {
+	pos := item + 1 - uint64(t.itemOffset)
+	if t.hidden {
+		pos++
+	}
+	return int64(pos) * indexEntrySize
+}
+
 // preopen opens all files that the freezer will need. This method should be called from an init-context,
 // since it assumes that it doesn't have to bother with locking
 // The rationale for doing preopen is to not have to do it from within Retrieve, thus not needing to ever
@@ -349,6 +407,9 @@ func (t *freezerTable) truncate(items uint64) error {
 	if existing <= items {
 		return nil
 	}
+	if items < uint64(t.itemOffset) {
+		return errTruncationBelowTail
+	}
 	// We need to truncate, save the old size for metrics tracking
 	oldSize, err := t.sizeNolock()
 	if err != nil {
@@ -360,16 +421,16 @@ func (t *freezerTable) truncate(items uint64) error {
 		log = t.logger.Warn // Only loud warn if we delete multiple items
 	}
 	log("Truncating freezer table", "items", existing, "limit", items)
-	if err := truncateFreezerFile(t.index, int64(items+1)*indexEntrySize); err != nil {
+	length := t.indexPosition(items-1) + indexEntrySize
+	if err := truncateFreezerFile(t.index, length); err != nil {
 		return err
 	}
 	// Calculate the new expected size of the data file and truncate it
 	buffer := make([]byte, indexEntrySize)
-	if _, err := t.index.ReadAt(buffer, int64(items*indexEntrySize)); err != nil {
+	expected, err := t.lastIndex(buffer, length)
+	if err != nil {
 		return err
 	}
-	var expected indexEntry
-	expected.unmarshalBinary(buffer)
 
 	// We might need to truncate back to older files
 	if expected.filenum != t.headId {
@@ -403,6 +464,125 @@ func (t *freezerTable) truncate(items uint64) error {
 	return nil
 }
 
+// truncateTail discards any historic data below the provided threshold number.
+// Data files only containing discarded items are deleted, whereas discarded
+// items sharing the tail data file with retained ones are hidden by recording
+// the start of the first retained item in the index.
+func (t *freezerTable) truncateTail(tail uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Ensure the table is accessible and the tail is actually moving forward
+	if t.index == nil || t.head == nil {
+		return errClosed
+	}
+	if uint64(t.itemOffset) >= tail {
+		return nil
+	}
+	items := atomic.LoadUint64(&t.items)
+	if tail > items {
+		return errTruncationAboveHead
+	}
+	if tail > math.MaxUint32 {
+		return fmt.Errorf("tail %d exceeds index capacity", tail)
+	}
+	// We need to truncate, save the old size for metrics tracking
+	oldSize, err := t.sizeNolock()
+	if err != nil {
+		return err
+	}
+	t.logger.Info("Truncating freezer table tail", "items", items, "tail", tail)
+
+	// Locate the first retained item: it starts where the last discarded one
+	// ends, unless it was pushed into the next data file
+	var (
+		buffer = make([]byte, indexEntrySize)
+		pos    = t.indexPosition(tail - 1)
+		start  indexEntry
+	)
+	if _, err := t.index.ReadAt(buffer, pos); err != nil {
+		return err
+	}
+	start.unmarshalBinary(buffer)
+
+	nextId := t.headId
+	if tail < items {
+		var next indexEntry
+		if _, err := t.index.ReadAt(buffer, pos+indexEntrySize); err != nil {
+			return err
+		}
+		next.unmarshalBinary(buffer)
+		nextId = next.filenum
+	}
+	if start.filenum != nextId {
+		start = indexEntry{filenum: nextId}
+	}
+	// Assemble the new index into a temporary file: index zero, the hidden item
+	// boundary if the retained items don't start at the beginning of the tail
+	// file, followed by the retained item indexes
+	zero := indexEntry{filenum: start.filenum, offset: uint32(tail)}
+	if start.offset != 0 {
+		zero.filenum |= indexHiddenFlag
+	}
+	header := zero.append(nil)
+	if start.offset != 0 {
+		header = start.append(header)
+	}
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	name := t.index.Name()
+	temp, err := openFreezerFileTruncated(name + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(header); err != nil {
+		temp.Close()
+		return err
+	}
+	retained := io.NewSectionReader(t.index, pos+indexEntrySize, stat.Size()-pos-indexEntrySize)
+	if _, err := io.Copy(temp, retained); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	temp.Close()
+
+	// Swap in the new index and reopen it for appending
+	if err := t.index.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(name+".tmp", name); err != nil {
+		return err
+	}
+	if t.index, err = openFreezerFileForAppend(name); err != nil {
+		return err
+	}
+	// Delete all data files which only contained discarded items
+	for num := t.tailId; num < start.filenum; num++ {
+		t.releaseFile(num)
+		if err := os.Remove(t.fileName(num)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	t.tailId = start.filenum
+	t.itemOffset = uint32(tail)
+	t.hidden = start.offset != 0
+
+	// Retrieve the new size and update the total size counter
+	newSize, err := t.sizeNolock()
+	if err != nil {
+		return err
+	}
+	t.sizeGauge.Dec(int64(oldSize - newSize))
+
+	return nil
+}
+
 // Close closes all opened files.
 func (t *freezerTable) Close() error {
 	t.lock.Lock()
@@ -431,13 +611,7 @@ func (t *freezerTable) Close() error {
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.noCompression {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(t.fileName(num))
 		if err != nil {
 			return nil, err
 		}
@@ -446,6 +620,17 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	return f, err
 }
 
+// fileName returns the path of the data file with the given number.
+func (t *freezerTable) fileName(num uint32) string {
+	var name string
+	if t.noCompression {
+		name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	} else {
+		name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
+	}
+	return filepath.Join(t.path, name)
+}
+
 // releaseFile closes a file, and removes it from the open file cache.
 // Assumes that the caller holds the write lock
 func (t *freezerTable) releaseFile(num uint32) {
@@ -475,24 +660,23 @@ func (t *freezerTable) releaseFilesAfter(num uint32, remove bool) {
 // so that the items are within bounds. If this method is used to read out of bounds,
 // it will return error.
 func (t *freezerTable) getIndices(from, count uint64) ([]*indexEntry, error) {
-	// Apply the table-offset
-	from = from - uint64(t.itemOffset)
-	// For reading N items, we need N+1 indices.
+	// Apply the table-offset. For reading N items, we need N+1 indices.
+	start := t.indexPosition(from - 1)
 	buffer := make([]byte, (count+1)*indexEntrySize)
-	if _, err := t.index.ReadAt(buffer, int64(from*indexEntrySize)); err != nil {
+	if _, err := t.index.ReadAt(buffer, start); err != nil {
 		return nil, err
 	}
 	var (
 		indices []*indexEntry
 		offset  int
 	)
-	for i := from; i <= from+count; i++ {
+	for i := uint64(0); i <= count; i++ {
 		index := new(indexEntry)
 		index.unmarshalBinary(buffer[offset:])
 		offset += indexEntrySize
 		indices = append(indices, index)
 	}
-	if from == 0 {
+	if start == 0 {
 		// Special case if we're reading the first item in the freezer. We assume that
 		// the first item always start from zero(regarding the deletion, we
 		// only support deletion by files, so that the assumption is held).
@@ -651,7 +835,18 @@ func (t *freezerTable) retrieveItems(start, count, maxBytes uint64) ([]byte, []i
 // has returns an indicator whether the specified number data
 // exists in the freezer table.
 func (t *freezerTable) has(number uint64) bool {
-	return atomic.LoadUint64(&t.items) > number
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return atomic.LoadUint64(&t.items) > number && uint64(t.itemOffset) <= number
+}
+
+// tail returns the number of the first item retained in the freezer table.
+func (t *freezerTable) tail() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return uint64(t.itemOffset)
 }
 
 // size returns the total data size in the freezer table.
@@ -398,6 +398,127 @@ func TestFreezerTruncate(t *testing.T) {
 	}
 }
 
+// TestFreezerTableTruncateTail tests discarding items from the tail of a table, both
+// within a data file (hiding items) and at data file boundaries (deleting files).
+func TestFreezerTableTruncateTail(t *testing.T) {
+	t.Parallel()
+	rm, wm, sg := metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge()
+	fname := fmt.Sprintf("truncate-tail-%d", rand.Uint64())
+
+	// Fill table with 7 x 20 bytes, three items per file
+	f, err := newTable(os.TempDir(), fname, rm, wm, sg, 60, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeChunks(t, f, 7, 20)
+
+	// Discard the first item, hiding it within the first data file
+	if err := f.truncateTail(1); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(f.dumpIndexString(0, 100))
+	checkRetrieveError(t, f, map[uint64]error{
+		0: errOutOfBounds,
+	})
+	checkRetrieve(t, f, map[uint64][]byte{
+		1: getChunk(20, 1),
+		2: getChunk(20, 2),
+		6: getChunk(20, 6),
+	})
+	if f.has(0) || !f.has(1) {
+		t.Fatalf("item availability mismatch after hiding")
+	}
+	// Reopen the table, ensuring the hidden items are restored from the index
+	f.Close()
+	if f, err = newTable(os.TempDir(), fname, rm, wm, sg, 60, true); err != nil {
+		t.Fatal(err)
+	}
+	if f.items != 7 || f.tail() != 1 {
+		t.Fatalf("table bounds mismatch: have [%d, %d), want [1, 7)", f.tail(), f.items)
+	}
+	items, err := f.RetrieveItems(0, 3, 100)
+	if err != errOutOfBounds {
+		t.Fatalf("expected error reading hidden items, have %v (%d items)", err, len(items))
+	}
+	if items, err = f.RetrieveItems(1, 3, 100); err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range items {
+		if want := getChunk(20, i+1); !bytes.Equal(item, want) {
+			t.Fatalf("item %d mismatch: have %x, want %x", i+1, item, want)
+		}
+	}
+	// Discard up to a data file boundary, deleting the first file
+	if err := f.truncateTail(3); err != nil {
+		t.Fatal(err)
+	}
+	if f.tailId != 1 || f.hidden {
+		t.Fatalf("tail file mismatch: have %d (hidden %v), want 1", f.tailId, f.hidden)
+	}
+	if _, err := os.Stat(filepath.Join(os.TempDir(), fmt.Sprintf("%s.0000.rdat", fname))); !os.IsNotExist(err) {
+		t.Fatalf("expected first data file to be deleted: %v", err)
+	}
+	checkRetrieveError(t, f, map[uint64]error{
+		1: errOutOfBounds,
+		2: errOutOfBounds,
+	})
+	checkRetrieve(t, f, map[uint64][]byte{
+		3: getChunk(20, 3),
+		5: getChunk(20, 5),
+		6: getChunk(20, 6),
+	})
+	// Moving the tail backwards is a noop, beyond the head is an error
+	if err := f.truncateTail(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.truncateTail(8); err != errTruncationAboveHead {
+		t.Fatalf("wrong error truncating above head: %v", err)
+	}
+	// Hide another item in the second file, then truncate and extend the head
+	if err := f.truncateTail(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.truncate(4); err != errTruncationBelowTail {
+		t.Fatalf("wrong error truncating below tail: %v", err)
+	}
+	if err := f.truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	batch := f.newBatch()
+	require.NoError(t, batch.AppendRaw(5, getChunk(20, 0xaa)))
+	require.NoError(t, batch.AppendRaw(6, getChunk(20, 0xbb)))
+	require.NoError(t, batch.commit())
+
+	f.Close()
+	if f, err = newTable(os.TempDir(), fname, rm, wm, sg, 60, true); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	t.Log(f.dumpIndexString(0, 100))
+
+	checkRetrieveError(t, f, map[uint64]error{
+		4: errOutOfBounds,
+		7: errOutOfBounds,
+	})
+	checkRetrieve(t, f, map[uint64][]byte{
+		5: getChunk(20, 0xaa),
+		6: getChunk(20, 0xbb),
+	})
+	// Discard everything, the table must remain appendable
+	if err := f.truncateTail(7); err != nil {
+		t.Fatal(err)
+	}
+	batch = f.newBatch()
+	require.NoError(t, batch.AppendRaw(7, getChunk(20, 0xcc)))
+	require.NoError(t, batch.commit())
+	checkRetrieveError(t, f, map[uint64]error{
+		6: errOutOfBounds,
+	})
+	checkRetrieve(t, f, map[uint64][]byte{
+		7: getChunk(20, 0xcc),
+	})
+}
+
 // TestFreezerRepairFirstFile tests a head file with the very first item only half-written.
 // That will rewind the index, and _should_ truncate the head file
 func TestFreezerRepairFirstFile(t *testing.T) {
@@ -123,6 +123,67 @@ func TestFreezerModifyRollback(t *testing.T) {
 	checkAncientCount(t, f2, "test", 0)
 }
 
+// This checks that tail truncation only affects the requested table, and that
+// head truncation is rejected once it would cross a truncated tail.
+func TestFreezerTruncateTail(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]bool{"a": true, "b": false}
+	f, dir := newFreezerForTesting(t, tables)
+	defer os.RemoveAll(dir)
+
+	_, err := f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			require.NoError(t, op.AppendRaw("a", i, getChunk(1000, int(i))))
+			require.NoError(t, op.AppendRaw("b", i, getChunk(1000, int(i))))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.TruncateTail("a", 5))
+	if err := f.TruncateTail("c", 5); err != errUnknownTable {
+		t.Fatalf("wrong error truncating unknown table: %v", err)
+	}
+	if tail, _ := f.AncientTail("a"); tail != 5 {
+		t.Fatalf("wrong tail for truncated table: have %d, want 5", tail)
+	}
+	if tail, _ := f.AncientTail("b"); tail != 0 {
+		t.Fatalf("wrong tail for untouched table: have %d, want 0", tail)
+	}
+	if ok, _ := f.HasAncient("a", 4); ok {
+		t.Fatalf("HasAncient returned true for discarded item")
+	}
+	if _, err := f.Ancient("a", 4); err != errOutOfBounds {
+		t.Fatalf("wrong error reading discarded item: %v", err)
+	}
+	checkAncientCount(t, f, "a", 10)
+	checkAncientCount(t, f, "b", 10)
+
+	// Head truncation across the tail must fail without touching any table
+	if err := f.TruncateAncients(4); err != errTruncationBelowTail {
+		t.Fatalf("wrong error truncating below tail: %v", err)
+	}
+	checkAncientCount(t, f, "b", 10)
+	require.NoError(t, f.TruncateAncients(7))
+	checkAncientCount(t, f, "a", 7)
+	f.Close()
+
+	// Reopen and check that the tail persisted
+	f2, err := newFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatalf("can't reopen freezer after tail truncation: %v", err)
+	}
+	defer f2.Close()
+	if tail, _ := f2.AncientTail("a"); tail != 5 {
+		t.Fatalf("wrong tail after reopen: have %d, want 5", tail)
+	}
+	checkAncientCount(t, f2, "a", 7)
+	if v, _ := f2.Ancient("a", 5); !bytes.Equal(v, getChunk(1000, 5)) {
+		t.Fatalf("wrong value at tail: %x", v)
+	}
+}
+
 // This test runs ModifyAncients and Ancient concurrently with each other.
 func TestFreezerConcurrentModifyRetrieve(t *testing.T) {
 	t.Parallel()
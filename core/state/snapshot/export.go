@@ -0,0 +1,369 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/ethdb"
+	"github.com/ubiq/go-ubiq/v7/log"
+	"github.com/ubiq/go-ubiq/v7/rlp"
+	"github.com/ubiq/go-ubiq/v7/trie"
+)
+
+// exportMagic is the prefix of every state export stream, followed by the RLP
+// encoded header and the entries.
+var exportMagic = []byte("ubqstate")
+
+// exportVersion is the current version of the state export format.
+const exportVersion = 1
+
+// Entry kinds of the state export format. Every account is followed by its code
+// (unless already exported for an earlier account) and its storage slots, all of
+// them ordered by hash. The stream is closed by a trailer carrying the checksum.
+const (
+	exportAccount uint8 = iota + 1 // Slim RLP account keyed by account hash
+	exportStorage                  // Storage slot keyed by slot hash
+	exportCode                     // Contract code keyed by code hash
+	exportEnd                      // Trailer with the stream checksum and stats
+)
+
+var (
+	// errExportTruncated is returned if an export stream ends without a trailer.
+	errExportTruncated = errors.New("state export truncated")
+
+	// errExportChecksum is returned if the checksum of an export stream doesn't
+	// match its contents.
+	errExportChecksum = errors.New("state export checksum mismatch")
+)
+
+// ExportHeader is the leading record of a state export, identifying the state
+// contained within.
+type ExportHeader struct {
+	Version uint64      // Version of the export format
+	Root    common.Hash // State root of the exported state
+	Number  uint64      // Number of the block the state belongs to
+	Hash    common.Hash // Hash of the block the state belongs to
+}
+
+// ExportStats contains the number of items in a state export.
+type ExportStats struct {
+	Accounts uint64
+	Slots    uint64
+	Codes    uint64
+}
+
+// exportEntry is a single record of a state export.
+type exportEntry struct {
+	Kind uint8
+	Key  common.Hash
+	Data []byte
+}
+
+// exportWriter writes state export entries while checksumming them.
+type exportWriter struct {
+	w      io.Writer
+	hasher crypto.KeccakState
+}
+
+// write encodes and writes a single entry into the export.
+func (w *exportWriter) write(kind uint8, key common.Hash, data []byte) error {
+	blob, err := rlp.EncodeToBytes(&exportEntry{Kind: kind, Key: key, Data: data})
+	if err != nil {
+		return err
+	}
+	w.hasher.Write(blob)
+	_, err = w.w.Write(blob)
+	return err
+}
+
+// checksum returns the checksum of all the data written so far. No more entries
+// may be written afterwards.
+func (w *exportWriter) checksum() common.Hash {
+	var sum common.Hash
+	w.hasher.Read(sum[:])
+	return sum
+}
+
+// Export streams the state identified by root out of the snapshot tree into the
+// portable state export format. The block number and hash are only recorded to
+// identify the state, they are not verified against the root.
+func (t *Tree) Export(w io.Writer, root common.Hash, number uint64, hash common.Hash) (*ExportStats, error) {
+	accIt, err := t.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	defer accIt.Release()
+
+	header, err := rlp.EncodeToBytes(&ExportHeader{Version: exportVersion, Root: root, Number: number, Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+	out := &exportWriter{w: w, hasher: crypto.NewKeccakState()}
+	out.hasher.Write(exportMagic)
+	out.hasher.Write(header)
+	if _, err := w.Write(append(append([]byte{}, exportMagic...), header...)); err != nil {
+		return nil, err
+	}
+	var (
+		stats  = new(ExportStats)
+		codes  = make(map[common.Hash]struct{})
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for accIt.Next() {
+		account, err := FullAccount(accIt.Account())
+		if err != nil {
+			return nil, err
+		}
+		if err := out.write(exportAccount, accIt.Hash(), accIt.Account()); err != nil {
+			return nil, err
+		}
+		stats.Accounts++
+
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != emptyCode {
+			if _, ok := codes[codeHash]; !ok {
+				code := rawdb.ReadCode(t.diskdb, codeHash)
+				if len(code) == 0 {
+					return nil, fmt.Errorf("code %x missing for account %x", codeHash, accIt.Hash())
+				}
+				if err := out.write(exportCode, codeHash, code); err != nil {
+					return nil, err
+				}
+				codes[codeHash] = struct{}{}
+				stats.Codes++
+			}
+		}
+		if common.BytesToHash(account.Root) != emptyRoot {
+			stIt, err := t.StorageIterator(root, accIt.Hash(), common.Hash{})
+			if err != nil {
+				return nil, err
+			}
+			for stIt.Next() {
+				if err := out.write(exportStorage, stIt.Hash(), stIt.Slot()); err != nil {
+					stIt.Release()
+					return nil, err
+				}
+				stats.Slots++
+			}
+			err = stIt.Error()
+			stIt.Release()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("State export in progress", "at", accIt.Hash(), "accounts", stats.Accounts, "slots", stats.Slots,
+				"codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return nil, err
+	}
+	blob, err := rlp.EncodeToBytes(stats)
+	if err != nil {
+		return nil, err
+	}
+	trailer, err := rlp.EncodeToBytes(&exportEntry{Kind: exportEnd, Key: out.checksum(), Data: blob})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(trailer); err != nil {
+		return nil, err
+	}
+	log.Info("State export complete", "root", root, "accounts", stats.Accounts, "slots", stats.Slots,
+		"codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// importAccount is an account being imported, waiting for all of its storage
+// slots to verify its storage root.
+type importAccount struct {
+	hash    common.Hash
+	account Account
+	storage *trie.StackTrie
+	last    *common.Hash // Hash of the last storage slot, to enforce ordering
+}
+
+// Import reads a state export from the given stream, rebuilding the account and
+// storage tries into the database. All the trie roots, the stream checksum and
+// item counts are verified, so an import which returns without an error leaves
+// a complete state behind.
+func Import(r io.Reader, db ethdb.KeyValueStore) (*ExportHeader, *ExportStats, error) {
+	in := bufio.NewReader(r)
+
+	magic := make([]byte, len(exportMagic))
+	if _, err := io.ReadFull(in, magic); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(magic, exportMagic) {
+		return nil, nil, errors.New("not a state export")
+	}
+	hasher := crypto.NewKeccakState()
+	hasher.Write(magic)
+
+	stream := rlp.NewStream(in, 0)
+	blob, err := stream.Raw()
+	if err != nil {
+		return nil, nil, err
+	}
+	hasher.Write(blob)
+
+	header := new(ExportHeader)
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return nil, nil, err
+	}
+	if header.Version != exportVersion {
+		return nil, nil, fmt.Errorf("unsupported state export version %d", header.Version)
+	}
+	var (
+		batch    = db.NewBatch()
+		accTrie  = trie.NewStackTrie(batch)
+		stats    = new(ExportStats)
+		codes    = make(map[common.Hash]struct{})
+		current  *importAccount
+		start    = time.Now()
+		logged   = time.Now()
+		finalise = func() error {
+			if current == nil {
+				return nil
+			}
+			root, err := current.storage.Commit()
+			if err != nil {
+				return err
+			}
+			if root != common.BytesToHash(current.account.Root) {
+				return fmt.Errorf("storage root mismatch for account %x: have %x, want %x", current.hash, root, current.account.Root)
+			}
+			if codeHash := common.BytesToHash(current.account.CodeHash); codeHash != emptyCode {
+				if _, ok := codes[codeHash]; !ok {
+					return fmt.Errorf("code %x missing for account %x", codeHash, current.hash)
+				}
+			}
+			full, err := rlp.EncodeToBytes(current.account)
+			if err != nil {
+				return err
+			}
+			return accTrie.TryUpdate(current.hash[:], full)
+		}
+	)
+	for {
+		blob, err := stream.Raw()
+		if err == io.EOF {
+			return nil, nil, errExportTruncated
+		} else if err != nil {
+			return nil, nil, err
+		}
+		var entry exportEntry
+		if err := rlp.DecodeBytes(blob, &entry); err != nil {
+			return nil, nil, err
+		}
+		if entry.Kind == exportEnd {
+			var sum common.Hash
+			hasher.Read(sum[:])
+			if sum != entry.Key {
+				return nil, nil, errExportChecksum
+			}
+			var want ExportStats
+			if err := rlp.DecodeBytes(entry.Data, &want); err != nil {
+				return nil, nil, err
+			}
+			if want != *stats {
+				return nil, nil, fmt.Errorf("state export item count mismatch: have %+v, want %+v", *stats, want)
+			}
+			break
+		}
+		hasher.Write(blob)
+
+		switch entry.Kind {
+		case exportAccount:
+			if current != nil && bytes.Compare(entry.Key[:], current.hash[:]) <= 0 {
+				return nil, nil, fmt.Errorf("account %x out of order", entry.Key)
+			}
+			if err := finalise(); err != nil {
+				return nil, nil, err
+			}
+			account, err := FullAccount(entry.Data)
+			if err != nil {
+				return nil, nil, err
+			}
+			current = &importAccount{hash: entry.Key, account: account, storage: trie.NewStackTrie(batch)}
+			stats.Accounts++
+
+		case exportStorage:
+			if current == nil {
+				return nil, nil, fmt.Errorf("storage slot %x without account", entry.Key)
+			}
+			if current.last != nil && bytes.Compare(entry.Key[:], current.last[:]) <= 0 {
+				return nil, nil, fmt.Errorf("storage slot %x of account %x out of order", entry.Key, current.hash)
+			}
+			if err := current.storage.TryUpdate(entry.Key[:], entry.Data); err != nil {
+				return nil, nil, err
+			}
+			key := entry.Key
+			current.last = &key
+			stats.Slots++
+
+		case exportCode:
+			if crypto.Keccak256Hash(entry.Data) != entry.Key {
+				return nil, nil, fmt.Errorf("code hash mismatch for %x", entry.Key)
+			}
+			rawdb.WriteCode(batch, entry.Key, entry.Data)
+			codes[entry.Key] = struct{}{}
+			stats.Codes++
+
+		default:
+			return nil, nil, fmt.Errorf("unknown state export entry kind %d", entry.Kind)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, nil, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("State import in progress", "accounts", stats.Accounts, "slots", stats.Slots,
+				"codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := finalise(); err != nil {
+		return nil, nil, err
+	}
+	root, err := accTrie.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	if root != header.Root {
+		return nil, nil, fmt.Errorf("state root mismatch: have %x, want %x", root, header.Root)
+	}
+	if err := batch.Write(); err != nil {
+		return nil, nil, err
+	}
+	log.Info("State import complete", "root", root, "accounts", stats.Accounts, "slots", stats.Slots,
+		"codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return header, stats, nil
+}
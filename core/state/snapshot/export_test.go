@@ -0,0 +1,146 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/ethdb/memorydb"
+	"github.com/ubiq/go-ubiq/v7/rlp"
+	"github.com/ubiq/go-ubiq/v7/trie"
+)
+
+// Tests that a state exported from a snapshot can be imported into an empty
+// database, recreating the exact same tries, and that damaged exports are
+// rejected.
+func TestExportImport(t *testing.T) {
+	// Create a small state of 3 accounts, two of which share the same storage
+	// and code
+	var (
+		diskdb = memorydb.New()
+		triedb = trie.NewDatabase(diskdb)
+		code   = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	)
+	rawdb.WriteCode(diskdb, crypto.Keccak256Hash(code), code)
+
+	stTrie, _ := trie.NewSecure(common.Hash{}, triedb)
+	stTrie.Update([]byte("key-1"), []byte("val-1"))
+	stTrie.Update([]byte("key-2"), []byte("val-2"))
+	stTrie.Update([]byte("key-3"), []byte("val-3"))
+	stTrie.Commit(nil)
+
+	accTrie, _ := trie.NewSecure(common.Hash{}, triedb)
+	acc := &Account{Balance: big.NewInt(1), Root: stTrie.Hash().Bytes(), CodeHash: crypto.Keccak256(code)}
+	val, _ := rlp.EncodeToBytes(acc)
+	accTrie.Update([]byte("acc-1"), val)
+
+	acc = &Account{Balance: big.NewInt(2), Root: emptyRoot.Bytes(), CodeHash: emptyCode.Bytes()}
+	val, _ = rlp.EncodeToBytes(acc)
+	accTrie.Update([]byte("acc-2"), val)
+
+	acc = &Account{Balance: big.NewInt(3), Root: stTrie.Hash().Bytes(), CodeHash: crypto.Keccak256(code)}
+	val, _ = rlp.EncodeToBytes(acc)
+	accTrie.Update([]byte("acc-3"), val)
+	root, _, _ := accTrie.Commit(nil)
+	triedb.Commit(root, false, nil)
+
+	snap := generateSnapshot(diskdb, triedb, 16, root)
+	select {
+	case <-snap.genPending:
+		// Snapshot generation succeeded
+
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed")
+	}
+	defer func() {
+		stop := make(chan *generatorStats)
+		snap.genAbort <- stop
+		<-stop
+	}()
+	snaps := &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		layers: map[common.Hash]snapshot{root: snap},
+	}
+	// Export the state and import it into a fresh database
+	var export bytes.Buffer
+	stats, err := snaps.Export(&export, root, 42, common.HexToHash("0x01"))
+	if err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+	if want := (ExportStats{Accounts: 3, Slots: 6, Codes: 1}); *stats != want {
+		t.Fatalf("Export stats mismatch: have %+v, want %+v", *stats, want)
+	}
+	db := memorydb.New()
+	header, imported, err := Import(bytes.NewReader(export.Bytes()), db)
+	if err != nil {
+		t.Fatalf("Failed to import state: %v", err)
+	}
+	if header.Root != root || header.Number != 42 || header.Hash != common.HexToHash("0x01") {
+		t.Errorf("Import header mismatch: have %+v", header)
+	}
+	if *imported != *stats {
+		t.Errorf("Import stats mismatch: have %+v, want %+v", *imported, *stats)
+	}
+	if !bytes.Equal(rawdb.ReadCode(db, crypto.Keccak256Hash(code)), code) {
+		t.Errorf("Imported code missing")
+	}
+	tr, err := trie.NewSecure(root, trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("Imported account trie missing: %v", err)
+	}
+	blob, err := tr.TryGet([]byte("acc-3"))
+	if err != nil {
+		t.Fatalf("Failed to read imported account: %v", err)
+	}
+	var account Account
+	if err := rlp.DecodeBytes(blob, &account); err != nil || account.Balance.Uint64() != 3 {
+		t.Fatalf("Imported account mismatch: %v, %+v", err, account)
+	}
+	st, err := trie.NewSecure(common.BytesToHash(account.Root), trie.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("Imported storage trie missing: %v", err)
+	}
+	if slot, _ := st.TryGet([]byte("key-2")); !bytes.Equal(slot, []byte("val-2")) {
+		t.Errorf("Imported storage slot mismatch: have %x, want %x", slot, []byte("val-2"))
+	}
+	// Truncated exports must be rejected
+	if _, _, err := Import(bytes.NewReader(export.Bytes()[:export.Len()-10]), memorydb.New()); err == nil {
+		t.Errorf("Truncated export imported")
+	}
+	// Tampering with the header is only caught by the checksum
+	_, _, rest, err := rlp.Split(export.Bytes()[len(exportMagic):])
+	if err != nil {
+		t.Fatalf("Failed to split export header: %v", err)
+	}
+	forged, _ := rlp.EncodeToBytes(&ExportHeader{Version: exportVersion, Root: root, Number: 43, Hash: common.HexToHash("0x01")})
+	tampered := append(append(append([]byte{}, exportMagic...), forged...), rest...)
+	if _, _, err := Import(bytes.NewReader(tampered), memorydb.New()); err != errExportChecksum {
+		t.Errorf("Tampered export error mismatch: have %v, want %v", err, errExportChecksum)
+	}
+	// Tampering with the data must be caught by the root verification
+	tampered = bytes.Replace(export.Bytes(), []byte("val-2"), []byte("val-X"), 1)
+	if _, _, err := Import(bytes.NewReader(tampered), memorydb.New()); err == nil {
+		t.Errorf("Tampered storage imported")
+	}
+}
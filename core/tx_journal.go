@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/ubiq/go-ubiq/v7/common"
 	"github.com/ubiq/go-ubiq/v7/core/types"
@@ -178,3 +179,100 @@ func (journal *txJournal) close() error {
 	}
 	return err
 }
+
+// remoteJournalEntry is a remote transaction snapshotted on shutdown, along with
+// the time it was first seen to allow dropping stale ones on reload.
+type remoteJournalEntry struct {
+	Time uint64 // Unix time in nanoseconds the transaction was first seen at
+	Tx   *types.Transaction
+}
+
+// saveRemoteJournal snapshots the specified remote transactions to disk,
+// replacing any previous snapshot.
+func saveRemoteJournal(path string, all map[common.Address]types.Transactions) error {
+	replacement, err := os.OpenFile(path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	journaled := 0
+	for _, txs := range all {
+		for _, tx := range txs {
+			entry := &remoteJournalEntry{Time: uint64(tx.Time().UnixNano()), Tx: tx}
+			if err = rlp.Encode(replacement, entry); err != nil {
+				replacement.Close()
+				return err
+			}
+		}
+		journaled += len(txs)
+	}
+	if err = replacement.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(path+".new", path); err != nil {
+		return err
+	}
+	log.Info("Saved remote transaction journal", "transactions", journaled, "accounts", len(all))
+	return nil
+}
+
+// loadRemoteJournal parses a remote transaction snapshot from disk, loading the
+// transactions younger than maxAge (0 = unlimited) into the specified pool. The
+// snapshot is deleted afterwards, so a crash cannot resurrect its contents.
+func loadRemoteJournal(path string, maxAge time.Duration, add func([]*types.Transaction) []error) error {
+	// Skip the parsing if the snapshot doesn't exist at all
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	input, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	defer input.Close()
+
+	var (
+		stream = rlp.NewStream(input, 0)
+		cutoff = time.Now().Add(-maxAge)
+
+		total, stale, dropped int
+		failure               error
+		batch                 types.Transactions
+	)
+	loadBatch := func(txs types.Transactions) {
+		for _, err := range add(txs) {
+			if err != nil {
+				log.Debug("Failed to add journaled remote transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		// Parse the next transaction and terminate on error
+		entry := new(remoteJournalEntry)
+		if err = stream.Decode(entry); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			if batch.Len() > 0 {
+				loadBatch(batch)
+			}
+			break
+		}
+		total++
+
+		// Skip transactions which spent too long in the pool already
+		seen := time.Unix(0, int64(entry.Time))
+		if maxAge > 0 && seen.Before(cutoff) {
+			stale++
+			continue
+		}
+		entry.Tx.SetTime(seen)
+		if batch = append(batch, entry.Tx); batch.Len() > 1024 {
+			loadBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	log.Info("Loaded remote transaction journal", "transactions", total, "stale", stale, "dropped", dropped)
+
+	return failure
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/ubiq/go-ubiq/v7/common"
 	"github.com/ubiq/go-ubiq/v7/common/prque"
 	"github.com/ubiq/go-ubiq/v7/consensus/misc"
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
 	"github.com/ubiq/go-ubiq/v7/core/state"
 	"github.com/ubiq/go-ubiq/v7/core/types"
 	"github.com/ubiq/go-ubiq/v7/event"
@@ -144,6 +145,7 @@ const (
 type blockChain interface {
 	CurrentBlock() *types.Block
 	GetBlock(hash common.Hash, number uint64) *types.Block
+	GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry
 	StateAt(root common.Hash) (*state.StateDB, error)
 
 	SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription
@@ -165,6 +167,9 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	RemoteJournal    string        // Snapshot of remote transactions to survive node restarts (empty = disabled)
+	RemoteJournalAge time.Duration // Maximum age of remote transactions reloaded from the snapshot (0 = unlimited)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -182,6 +187,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	RemoteJournalAge: time.Hour,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -236,6 +243,7 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	txFeed      event.Feed
+	dropFeed    event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -268,6 +276,8 @@ type TxPool struct {
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+
+	dropped []*DroppedTx // Transactions left the pool since the last dropped event
 }
 
 type txpoolResetRequest struct {
@@ -322,6 +332,13 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote transaction snapshotting is enabled, reload the last snapshot
+	if config.RemoteJournal != "" {
+		add := func(txs []*types.Transaction) []error { return pool.addTxs(txs, false, true) }
+		if err := loadRemoteJournal(config.RemoteJournal, config.RemoteJournalAge, add); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+	}
 
 	// Subscribe events from blockchain and start the main event loop.
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
@@ -392,11 +409,15 @@ func (pool *TxPool) loop() {
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true)
 					}
+					pool.dropTxs(list, TxDropExpired)
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			dropped := pool.takeDropped()
 			pool.mu.Unlock()
 
+			pool.sendDropped(dropped)
+
 		// Handle local transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
@@ -422,6 +443,15 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.config.RemoteJournal != "" {
+		pool.mu.RLock()
+		remotes := pool.remote()
+		pool.mu.RUnlock()
+
+		if err := saveRemoteJournal(pool.config.RemoteJournal, remotes); err != nil {
+			log.Warn("Failed to save remote transaction journal", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -431,6 +461,12 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDroppedTxsEvent registers a subscription of DroppedTxsEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeDroppedTxsEvent(ch chan<- DroppedTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -443,7 +479,11 @@ func (pool *TxPool) GasPrice() *big.Int {
 // new transaction, and drops all transactions below this threshold.
 func (pool *TxPool) SetGasPrice(price *big.Int) {
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
+	defer func() {
+		dropped := pool.takeDropped()
+		pool.mu.Unlock()
+		pool.sendDropped(dropped)
+	}()
 
 	old := pool.gasPrice
 	pool.gasPrice = price
@@ -454,6 +494,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 		for _, tx := range drop {
 			pool.removeTx(tx.Hash(), false)
 		}
+		pool.dropTxs(drop, TxDropUnderpriced)
 		pool.priced.Removed(len(drop))
 	}
 
@@ -581,6 +622,24 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves all currently known remote transactions, grouped by origin
+// account and sorted by nonce. The returned transaction set is a copy and can be
+// freely modified by calling code.
+func (pool *TxPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pending.Flatten()...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], queued.Flatten()...)
+		}
+	}
+	return txs
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -705,6 +764,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 			pool.removeTx(tx.Hash(), false)
+			pool.dropTx(tx, TxDropUnderpriced, common.Hash{})
 		}
 	}
 	// Try to replace an existing transaction in the pending pool
@@ -720,6 +780,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		if old != nil {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
+			pool.dropTx(old, TxDropReplaced, hash)
 			pendingReplaceMeter.Mark(1)
 		}
 		pool.all.Add(tx, isLocal)
@@ -771,6 +832,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, local boo
 	if old != nil {
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
+		pool.dropTx(old, TxDropReplaced, hash)
 		queuedReplaceMeter.Mark(1)
 	} else {
 		// Nothing was replaced, bump the queued counter
@@ -820,6 +882,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		// An older transaction was better, discard this
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
+		pool.dropTx(tx, TxDropReplaced, list.txs.Get(tx.Nonce()).Hash())
 		pendingDiscardMeter.Mark(1)
 		return false
 	}
@@ -827,6 +890,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	if old != nil {
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
+		pool.dropTx(old, TxDropReplaced, hash)
 		pendingReplaceMeter.Mark(1)
 	} else {
 		// Nothing was replaced, bump the pending counter
@@ -918,8 +982,12 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
 	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
+	dropped := pool.takeDropped()
 	pool.mu.Unlock()
 
+	// Notify subsystems of transactions underpriced or replaced by the new ones
+	pool.sendDropped(dropped)
+
 	var nilSlot = 0
 	for _, err := range newErrs {
 		for errs[nilSlot] != nil {
@@ -1036,6 +1104,55 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 	}
 }
 
+// dropTx records a transaction which left the pool, to be reported in the next
+// dropped transactions event. The replacement is only set for replaced ones.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropTx(tx *types.Transaction, reason TxDropReason, replacement common.Hash) {
+	pool.dropped = append(pool.dropped, &DroppedTx{Tx: tx, Reason: reason, Replacement: replacement})
+}
+
+// dropTxs records a batch of transactions which left the pool for the same reason.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropTxs(txs types.Transactions, reason TxDropReason) {
+	for _, tx := range txs {
+		pool.dropTx(tx, reason, common.Hash{})
+	}
+}
+
+// dropStale records a batch of transactions with nonces below the account nonce,
+// telling apart the ones included in the canonical chain from the ones invalidated
+// by a different transaction with the same nonce.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropStale(txs types.Transactions) {
+	for _, tx := range txs {
+		if pool.chain.GetTransactionLookup(tx.Hash()) != nil {
+			pool.dropTx(tx, TxDropMined, common.Hash{})
+		} else {
+			pool.dropTx(tx, TxDropNonceTooLow, common.Hash{})
+		}
+	}
+}
+
+// takeDropped returns the transactions recorded as dropped since the last call.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) takeDropped() []*DroppedTx {
+	dropped := pool.dropped
+	pool.dropped = nil
+	return dropped
+}
+
+// sendDropped notifies subsystems of transactions which left the pool. It must
+// not be called with the pool lock held, as subscribers may call back into it.
+func (pool *TxPool) sendDropped(dropped []*DroppedTx) {
+	if len(dropped) > 0 {
+		pool.dropFeed.Send(DroppedTxsEvent{dropped})
+	}
+}
+
 // requestReset requests a pool reset to the new head block.
 // The returned channel is closed when the reset has occurred.
 func (pool *TxPool) requestReset(oldHead *types.Header, newHead *types.Header) chan struct{} {
@@ -1196,8 +1313,12 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
+	dropped := pool.takeDropped()
 	pool.mu.Unlock()
 
+	// Notify subsystems of transactions which left the pool
+	pool.sendDropped(dropped)
+
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
 		addr, _ := types.Sender(pool.signer, tx)
@@ -1325,6 +1446,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.dropStale(forwards)
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -1332,6 +1454,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.dropTxs(drops, TxDropUnpayable)
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
@@ -1355,6 +1478,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 				pool.all.Remove(hash)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
+			pool.dropTxs(caps, TxDropEvicted)
 			queuedRateLimitMeter.Mark(int64(len(caps)))
 		}
 		// Mark all the items dropped as removed
@@ -1420,6 +1544,7 @@ func (pool *TxPool) truncatePending() {
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
+					pool.dropTxs(caps, TxDropEvicted)
 					pool.priced.Removed(len(caps))
 					pendingGauge.Dec(int64(len(caps)))
 					if pool.locals.contains(offenders[i]) {
@@ -1447,6 +1572,7 @@ func (pool *TxPool) truncatePending() {
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
 					log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 				}
+				pool.dropTxs(caps, TxDropEvicted)
 				pool.priced.Removed(len(caps))
 				pendingGauge.Dec(int64(len(caps)))
 				if pool.locals.contains(addr) {
@@ -1487,9 +1613,11 @@ func (pool *TxPool) truncateQueue() {
 
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
-			for _, tx := range list.Flatten() {
+			txs := list.Flatten()
+			for _, tx := range txs {
 				pool.removeTx(tx.Hash(), true)
 			}
+			pool.dropTxs(txs, TxDropEvicted)
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
 			continue
@@ -1498,6 +1626,7 @@ func (pool *TxPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true)
+			pool.dropTx(txs[i], TxDropEvicted, common.Hash{})
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1523,6 +1652,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
+		pool.dropStale(olds)
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
 		for _, tx := range drops {
@@ -1530,6 +1660,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 		}
+		pool.dropTxs(drops, TxDropUnpayable)
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
 		for _, tx := range invalids {
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -73,6 +74,10 @@ func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block
 	return bc.CurrentBlock()
 }
 
+func (bc *testBlockChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry {
+	return nil
+}
+
 func (bc *testBlockChain) StateAt(common.Hash) (*state.StateDB, error) {
 	return bc.statedb, nil
 }
@@ -2234,6 +2239,154 @@ func TestTransactionReplacementDynamicFee(t *testing.T) {
 	}
 }
 
+// Tests that transactions leaving the pool are announced along with the reason
+// of their removal.
+func TestTransactionDropEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	events := make(chan DroppedTxsEvent, 32)
+	sub := pool.SubscribeDroppedTxsEvent(events)
+	defer sub.Unsubscribe()
+
+	checkEvent := func(ev DroppedTxsEvent, tx *types.Transaction, reason TxDropReason, replacement common.Hash) {
+		t.Helper()
+		if len(ev.Txs) != 1 {
+			t.Fatalf("dropped transaction count mismatch: have %d, want 1", len(ev.Txs))
+		}
+		drop := ev.Txs[0]
+		if drop.Tx.Hash() != tx.Hash() {
+			t.Errorf("dropped transaction mismatch: have %x, want %x", drop.Tx.Hash(), tx.Hash())
+		}
+		if drop.Reason != reason {
+			t.Errorf("drop reason mismatch: have %s, want %s", drop.Reason, reason)
+		}
+		if drop.Replacement != replacement {
+			t.Errorf("replacement mismatch: have %x, want %x", drop.Replacement, replacement)
+		}
+	}
+	checkDrop := func(tx *types.Transaction, reason TxDropReason, replacement common.Hash) {
+		t.Helper()
+		select {
+		case ev := <-events:
+			checkEvent(ev, tx, reason, replacement)
+		case <-time.After(time.Second):
+			t.Fatalf("dropped transaction event timeout")
+		}
+	}
+	// Replace a pending transaction with a better priced one, which must be
+	// reported right away instead of with the next pool reorganisation
+	cheap, expensive := pricedTransaction(0, 100000, big.NewInt(1), key), pricedTransaction(0, 100000, big.NewInt(2), key)
+	if err := pool.addRemoteSync(cheap); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if err := pool.AddRemotes([]*types.Transaction{expensive})[0]; err != nil {
+		t.Fatalf("failed to add replacement transaction: %v", err)
+	}
+	select {
+	case ev := <-events:
+		checkEvent(ev, cheap, TxDropReplaced, expensive.Hash())
+	default:
+		t.Fatalf("replaced transaction not reported on addition")
+	}
+
+	// Raise the minimum gas price above a pending transaction
+	underpriced := pricedTransaction(1, 100000, big.NewInt(1), key)
+	if err := pool.addRemoteSync(underpriced); err != nil {
+		t.Fatalf("failed to add underpriced transaction: %v", err)
+	}
+	pool.SetGasPrice(big.NewInt(2))
+	checkDrop(underpriced, TxDropUnderpriced, common.Hash{})
+
+	// Invalidate the remaining transaction by a nonce change
+	testSetNonce(pool, addr, 1)
+	<-pool.requestReset(nil, nil)
+	checkDrop(expensive, TxDropNonceTooLow, common.Hash{})
+
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected dropped transactions: %v", ev.Txs)
+	default:
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// minedBlockChain is a testBlockChain with a set of transactions included in
+// the canonical chain.
+type minedBlockChain struct {
+	*testBlockChain
+	mined map[common.Hash]bool
+}
+
+func (bc *minedBlockChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLookupEntry {
+	if bc.mined[hash] {
+		return new(rawdb.LegacyTxLookupEntry)
+	}
+	return nil
+}
+
+// Tests that transactions invalidated by a nonce change are reported as mined if
+// they are included in the canonical chain, and as nonce too low otherwise.
+func TestTransactionDropEventsMined(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &minedBlockChain{
+		testBlockChain: &testBlockChain{10000000, statedb, new(event.Feed)},
+		mined:          make(map[common.Hash]bool),
+	}
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+	<-pool.initDoneCh
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	events := make(chan DroppedTxsEvent, 32)
+	sub := pool.SubscribeDroppedTxsEvent(events)
+	defer sub.Unsubscribe()
+
+	mined, stale := transaction(0, 100000, key), transaction(1, 100000, key)
+	if err := pool.addRemoteSync(mined); err != nil {
+		t.Fatalf("failed to add mined transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(stale); err != nil {
+		t.Fatalf("failed to add stale transaction: %v", err)
+	}
+	// Include the first transaction, and a different one with the second nonce
+	blockchain.mined[mined.Hash()] = true
+	testSetNonce(pool, addr, 2)
+	<-pool.requestReset(nil, nil)
+
+	want := map[common.Hash]TxDropReason{
+		mined.Hash(): TxDropMined,
+		stale.Hash(): TxDropNonceTooLow,
+	}
+	for len(want) > 0 {
+		select {
+		case ev := <-events:
+			for _, drop := range ev.Txs {
+				if reason, ok := want[drop.Tx.Hash()]; !ok {
+					t.Errorf("unexpected dropped transaction %x", drop.Tx.Hash())
+				} else if drop.Reason != reason {
+					t.Errorf("drop reason mismatch for %x: have %s, want %s", drop.Tx.Hash(), drop.Reason, reason)
+				}
+				delete(want, drop.Tx.Hash())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("dropped transaction event timeout, missing %d", len(want))
+		}
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -2347,6 +2500,102 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that remote transactions are snapshotted on shutdown if enabled, and
+// reloaded on startup after revalidation against the new head, dropping the ones
+// exceeding the maximum age.
+func TestTransactionRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary directory for the remote journal
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	journal := filepath.Join(dir, "remotes.rlp")
+
+	// Create the original pool to snapshot the transactions from
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.RemoteJournal = journal
+	config.RemoteJournalAge = time.Hour
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	// Create two remote and a local account, only the remotes should be snapshotted
+	remote1, _ := crypto.GenerateKey()
+	remote2, _ := crypto.GenerateKey()
+	local, _ := crypto.GenerateKey()
+
+	for _, key := range []*ecdsa.PrivateKey{remote1, remote2, local} {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	for _, tx := range []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), remote1),
+		pricedTransaction(1, 100000, big.NewInt(1), remote1),
+		pricedTransaction(3, 100000, big.NewInt(1), remote1),
+		pricedTransaction(0, 100000, big.NewInt(1), remote2),
+	} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 4/1", pending, queued)
+	}
+	// Terminate the old pool, bump a remote nonce, create a new pool and ensure
+	// only the still valid remote transactions survive
+	pool.Stop()
+	if _, err := os.Stat(journal); err != nil {
+		t.Fatalf("remote journal not saved: %v", err)
+	}
+	statedb.SetNonce(crypto.PubkeyToAddress(remote2.PublicKey), 1)
+	blockchain = &testBlockChain{1000000, statedb, new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 2/1", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if _, err := os.Stat(journal); !os.IsNotExist(err) {
+		t.Fatalf("remote journal not consumed: %v", err)
+	}
+	pool.Stop()
+
+	// Replace the journal with a fresh and a stale transaction, and ensure only
+	// the fresh one is reloaded, retaining its original arrival time
+	var (
+		fresh = pricedTransaction(0, 100000, big.NewInt(1), remote1)
+		stale = pricedTransaction(1, 100000, big.NewInt(1), remote2)
+		seen  = time.Now().Add(-time.Minute)
+	)
+	fresh.SetTime(seen)
+	stale.SetTime(time.Now().Add(-2 * time.Hour))
+
+	if err := saveRemoteJournal(journal, map[common.Address]types.Transactions{
+		crypto.PubkeyToAddress(remote1.PublicKey): {fresh},
+		crypto.PubkeyToAddress(remote2.PublicKey): {stale},
+	}); err != nil {
+		t.Fatalf("failed to save remote journal: %v", err)
+	}
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 1/0", pending, queued)
+	}
+	if tx := pool.Get(fresh.Hash()); tx == nil || !tx.Time().Equal(seen) {
+		t.Fatalf("fresh transaction not reloaded with its arrival time")
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
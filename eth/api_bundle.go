@@ -0,0 +1,247 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/consensus/misc"
+	"github.com/ubiq/go-ubiq/v7/core"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/core/vm"
+	"github.com/ubiq/go-ubiq/v7/miner"
+	"github.com/ubiq/go-ubiq/v7/rpc"
+)
+
+// BundleArgs represents the arguments to submit a bundle of transactions for
+// atomic inclusion at the top of a block.
+type BundleArgs struct {
+	Txs          []hexutil.Bytes `json:"txs"`
+	BlockNumber  hexutil.Uint64  `json:"blockNumber"`
+	MinTimestamp *hexutil.Uint64 `json:"minTimestamp"`
+	MaxTimestamp *hexutil.Uint64 `json:"maxTimestamp"`
+}
+
+// decodeBundleTxs decodes a list of binary encoded transactions.
+func decodeBundleTxs(encoded []hexutil.Bytes) (types.Transactions, error) {
+	if len(encoded) == 0 {
+		return nil, miner.ErrEmptyBundle
+	}
+	txs := make(types.Transactions, len(encoded))
+	for i, input := range encoded {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
+
+// toBundle converts the arguments into a miner bundle.
+func (args *BundleArgs) toBundle() (*miner.Bundle, error) {
+	txs, err := decodeBundleTxs(args.Txs)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &miner.Bundle{
+		Txs:         txs,
+		BlockNumber: uint64(args.BlockNumber),
+	}
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = uint64(*args.MinTimestamp)
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = uint64(*args.MaxTimestamp)
+	}
+	return bundle, nil
+}
+
+// submitBundle schedules a bundle for inclusion by the local miner, returning
+// its hash.
+func submitBundle(e *Ethereum, args BundleArgs) (common.Hash, error) {
+	bundle, err := args.toBundle()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := e.Miner().SubmitBundle(bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
+
+// SubmitBundle schedules a bundle of transactions for atomic inclusion at the
+// top of its target block, if this node mines it and all the transactions of
+// the bundle succeed.
+func (api *PrivateMinerAPI) SubmitBundle(args BundleArgs) (common.Hash, error) {
+	return submitBundle(api.e, args)
+}
+
+// CallBundleArgs represents the arguments to simulate a bundle of transactions.
+// If BlockNumber refers to an existing block, the simulated block also takes its
+// base fee and difficulty, otherwise they are derived from the state block.
+type CallBundleArgs struct {
+	Txs              []hexutil.Bytes        `json:"txs"`
+	BlockNumber      *hexutil.Uint64        `json:"blockNumber"`
+	StateBlockNumber *rpc.BlockNumberOrHash `json:"stateBlockNumber"`
+	Timestamp        *hexutil.Uint64        `json:"timestamp"`
+	Coinbase         *common.Address        `json:"coinbase"`
+}
+
+// BundleTxResult is the outcome of a single transaction of a simulated bundle.
+type BundleTxResult struct {
+	TxHash     common.Hash    `json:"txHash"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	ReturnData hexutil.Bytes  `json:"returnData,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// CallBundleResult is the outcome of a simulated bundle.
+type CallBundleResult struct {
+	BundleHash       common.Hash       `json:"bundleHash"`
+	StateBlockNumber hexutil.Uint64    `json:"stateBlockNumber"`
+	TotalGasUsed     hexutil.Uint64    `json:"totalGasUsed"`
+	CoinbaseDiff     *hexutil.Big      `json:"coinbaseDiff"`
+	Results          []*BundleTxResult `json:"results"`
+}
+
+// PrivateBundleAPI provides private RPC methods to submit and simulate bundles
+// of transactions.
+type PrivateBundleAPI struct {
+	e *Ethereum
+}
+
+// NewPrivateBundleAPI creates a new RPC service to submit and simulate bundles.
+func NewPrivateBundleAPI(e *Ethereum) *PrivateBundleAPI {
+	return &PrivateBundleAPI{e: e}
+}
+
+// SendBundle schedules a bundle of transactions for atomic inclusion by the
+// local miner. It is equivalent to miner_submitBundle.
+func (api *PrivateBundleAPI) SendBundle(args BundleArgs) (common.Hash, error) {
+	return submitBundle(api.e, args)
+}
+
+// CallBundle simulates a bundle of transactions in a new block on top of the
+// given state block (latest by default), without including it anywhere.
+// Reverting transactions are reported in the results, while transactions which
+// cannot be included at all fail the entire call.
+func (api *PrivateBundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	txs, err := decodeBundleTxs(args.Txs)
+	if err != nil {
+		return nil, err
+	}
+	stateBlock := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if args.StateBlockNumber != nil {
+		stateBlock = *args.StateBlockNumber
+	}
+	statedb, parent, err := api.e.APIBackend.StateAndHeaderByNumberOrHash(ctx, stateBlock)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	// Assemble the header of the block to simulate the bundle in
+	var (
+		config = api.e.blockchain.Config()
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit:   parent.GasLimit,
+			Time:       parent.Time + 1,
+			Difficulty: parent.Difficulty,
+			Coinbase:   parent.Coinbase,
+		}
+	)
+	if args.BlockNumber != nil {
+		header.Number = new(big.Int).SetUint64(uint64(*args.BlockNumber))
+		if block := api.e.blockchain.GetHeaderByNumber(header.Number.Uint64()); block != nil {
+			header.Difficulty = block.Difficulty
+			header.BaseFee = block.BaseFee
+		}
+	}
+	if args.Timestamp != nil {
+		header.Time = uint64(*args.Timestamp)
+	}
+	if args.Coinbase != nil {
+		header.Coinbase = *args.Coinbase
+	}
+	if header.BaseFee == nil && config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(config, parent)
+	}
+	// Setup context so the simulation may be cancelled on timeout
+	var cancel context.CancelFunc
+	if timeout := api.e.APIBackend.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		signer   = types.MakeSigner(config, header.Number)
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+		blockCtx = core.NewEVMBlockContext(header, api.e.blockchain, &header.Coinbase)
+		evm      = vm.NewEVM(blockCtx, vm.TxContext{}, statedb, config, *api.e.blockchain.GetVMConfig())
+		balance  = statedb.GetBalance(header.Coinbase)
+
+		result = &CallBundleResult{
+			BundleHash:       (&miner.Bundle{Txs: txs}).Hash(),
+			StateBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+		}
+	)
+	// Wait for the context to be done and cancel the evm. Even if the
+	// EVM has finished, cancelling may be done (repeatedly)
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	for i, tx := range txs {
+		msg, err := tx.AsMessage(signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d (%x): %v", i, tx.Hash(), err)
+		}
+		statedb.Prepare(tx.Hash(), i)
+
+		evm.Reset(core.NewEVMTxContext(msg), statedb)
+		res, err := core.ApplyMessage(evm, msg, gp)
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", api.e.APIBackend.RPCEVMTimeout())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d (%x): %w", i, tx.Hash(), err)
+		}
+		statedb.Finalise(config.IsEIP158(header.Number))
+
+		txResult := &BundleTxResult{
+			TxHash:  tx.Hash(),
+			GasUsed: hexutil.Uint64(res.UsedGas),
+		}
+		if res.Failed() {
+			txResult.Error = res.Err.Error()
+			txResult.ReturnData = res.Revert()
+		} else {
+			txResult.ReturnData = res.Return()
+		}
+		result.Results = append(result.Results, txResult)
+		result.TotalGasUsed += txResult.GasUsed
+	}
+	result.CoinbaseDiff = (*hexutil.Big)(new(big.Int).Sub(statedb.GetBalance(header.Coinbase), balance))
+	return result, nil
+}
@@ -125,6 +125,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// Transfer mining-related config to the ubqhash config.
 	ubqhashConfig := config.Ubqhash
 	ubqhashConfig.NotifyFull = config.Miner.NotifyFull
+	ubqhashConfig.StratumAddr = config.Miner.Stratum
+	ubqhashConfig.StratumDifficulty = config.Miner.StratumDifficulty
 
 	// Assemble the Ethereum object
 	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", false)
@@ -157,6 +159,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		p2pServer:         stack.Server(),
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
 	}
+	if c, ok := eth.engine.(*clique.Clique); ok && config.Miner.CliqueStandby > 0 {
+		log.Info("Running as standby clique signer", "threshold", config.Miner.CliqueStandby)
+		c.SetStandby(config.Miner.CliqueStandby)
+	}
 
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -206,6 +212,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = stack.ResolvePath(config.TxPool.RemoteJournal)
+	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -314,6 +323,16 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateMinerAPI(s),
 			Public:    false,
+		}, {
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   NewPrivateBundleAPI(s),
+			Public:    false,
+		}, {
+			Namespace: "bundle",
+			Version:   "1.0",
+			Service:   NewPrivateBundleAPI(s),
+			Public:    false,
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
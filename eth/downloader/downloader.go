@@ -80,8 +80,9 @@ var (
 	errNoAncestorFound         = errors.New("no common ancestor found")
 )
 
-// peerDropFn is a callback type for dropping a peer detected as malicious.
-type peerDropFn func(id string)
+// peerDropFn is a callback type for dropping a peer detected as malicious. The
+// invalid flag tells peers feeding invalid data apart from slow or useless ones.
+type peerDropFn func(id string, invalid bool)
 
 // headerTask is a set of downloaded headers to queue along with their precomputed
 // hashes to avoid constant rehashing.
@@ -335,7 +336,7 @@ func (d *Downloader) Synchronise(id string, head common.Hash, td *big.Int, mode
 			// Timeouts can occur if e.g. compaction hits at the wrong time, and can be ignored
 			log.Warn("Downloader wants to drop peer, but peerdrop-function is not set", "peer", id)
 		} else {
-			d.dropPeer(id)
+			d.dropPeer(id, errors.Is(err, errInvalidChain) || errors.Is(err, errInvalidAncestor) || errors.Is(err, errBadPeer))
 		}
 		return err
 	}
@@ -962,7 +963,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64, head uint64) e
 		default:
 			// Header retrieval either timed out, or the peer failed in some strange way
 			// (e.g. disconnect). Consider the master peer bad and drop
-			d.dropPeer(p.id)
+			d.dropPeer(p.id, false)
 
 			// Finish the sync gracefully instead of dumping the gathered data though
 			for _, ch := range []chan bool{d.queue.blockWakeCh, d.queue.receiptWakeCh} {
@@ -130,7 +130,7 @@ func (dl *downloadTester) newPeer(id string, version uint, blocks []*types.Block
 }
 
 // dropPeer simulates a hard peer removal from the connection pool.
-func (dl *downloadTester) dropPeer(id string) {
+func (dl *downloadTester) dropPeer(id string, invalid bool) {
 	dl.lock.Lock()
 	defer dl.lock.Unlock()
 
@@ -152,7 +152,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 						// permitted it, consider the peer malicious attempting to
 						// stall the sync.
 						peer.log.Warn("Peer stalling, dropping", "waited", common.PrettyDuration(waited))
-						d.dropPeer(peer.id)
+						d.dropPeer(peer.id, false)
 					}
 				}
 			}
@@ -315,7 +315,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 			if fails > 2 {
 				queue.updateCapacity(peer, 0, 0)
 			} else {
-				d.dropPeer(peer.id)
+				d.dropPeer(peer.id, false)
 
 				// If this peer was the master peer, abort sync immediately
 				d.cancelLock.RLock()
@@ -230,17 +230,19 @@ func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, co
 		}
 
 		engine = ubqhash.New(ubqhash.Config{
-			PowMode:          config.PowMode,
-			CacheDir:         stack.ResolvePath(config.CacheDir),
-			CachesInMem:      config.CachesInMem,
-			CachesOnDisk:     config.CachesOnDisk,
-			CachesLockMmap:   config.CachesLockMmap,
-			DatasetDir:       config.DatasetDir,
-			DatasetsInMem:    config.DatasetsInMem,
-			DatasetsOnDisk:   config.DatasetsOnDisk,
-			DatasetsLockMmap: config.DatasetsLockMmap,
-			NotifyFull:       config.NotifyFull,
-			UIP1Epoch:        uip1Epoch,
+			PowMode:           config.PowMode,
+			CacheDir:          stack.ResolvePath(config.CacheDir),
+			CachesInMem:       config.CachesInMem,
+			CachesOnDisk:      config.CachesOnDisk,
+			CachesLockMmap:    config.CachesLockMmap,
+			DatasetDir:        config.DatasetDir,
+			DatasetsInMem:     config.DatasetsInMem,
+			DatasetsOnDisk:    config.DatasetsOnDisk,
+			DatasetsLockMmap:  config.DatasetsLockMmap,
+			NotifyFull:        config.NotifyFull,
+			StratumAddr:       config.StratumAddr,
+			StratumDifficulty: config.StratumDifficulty,
+			UIP1Epoch:         uip1Epoch,
 		}, notify, noverify)
 		engine.(*ubqhash.Ubqhash).SetThreads(-1) // Disable CPU mining
 	}
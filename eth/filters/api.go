@@ -28,6 +28,7 @@ import (
 	ethereum "github.com/ubiq/go-ubiq/v7"
 	"github.com/ubiq/go-ubiq/v7/common"
 	"github.com/ubiq/go-ubiq/v7/common/hexutil"
+	"github.com/ubiq/go-ubiq/v7/core"
 	"github.com/ubiq/go-ubiq/v7/core/types"
 	"github.com/ubiq/go-ubiq/v7/event"
 	"github.com/ubiq/go-ubiq/v7/rpc"
@@ -172,6 +173,57 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// DroppedTransaction is the notification sent for a transaction which left the
+// transaction pool.
+type DroppedTransaction struct {
+	Hash       common.Hash       `json:"hash"`
+	Nonce      hexutil.Uint64    `json:"nonce"`
+	Reason     core.TxDropReason `json:"reason"`
+	ReplacedBy *common.Hash      `json:"replacedBy,omitempty"`
+}
+
+// DroppedTransactions creates a subscription that is triggered each time a
+// transaction leaves the transaction pool, reporting why it was removed.
+func (api *PublicFilterAPI) DroppedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		drops := make(chan []*core.DroppedTx, 128)
+		droppedTxSub := api.events.SubscribeDroppedTxs(drops)
+
+		for {
+			select {
+			case txs := <-drops:
+				for _, drop := range txs {
+					notification := &DroppedTransaction{
+						Hash:   drop.Tx.Hash(),
+						Nonce:  hexutil.Uint64(drop.Tx.Nonce()),
+						Reason: drop.Reason,
+					}
+					if drop.Replacement != (common.Hash{}) {
+						replacement := drop.Replacement
+						notification.ReplacedBy = &replacement
+					}
+					notifier.Notify(rpcSub.ID, notification)
+				}
+			case <-rpcSub.Err():
+				droppedTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				droppedTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 //
@@ -48,6 +48,7 @@ type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
 	txFeed          event.Feed
+	droppedTxFeed   event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
@@ -110,6 +111,10 @@ func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Sub
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeDroppedTxsEvent(ch chan<- core.DroppedTxsEvent) event.Subscription {
+	return b.droppedTxFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -269,6 +274,45 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestDroppedTxSubscription tests whether transactions leaving the pool are
+// delivered to dropped transaction subscriptions.
+func TestDroppedTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		api     = NewPublicFilterAPI(backend, false, deadline)
+
+		original    = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(1), nil)
+		replacement = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(2), nil)
+		expired     = types.NewTransaction(5, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(1), nil)
+	)
+	drops := make(chan []*core.DroppedTx)
+	sub := api.events.SubscribeDroppedTxs(drops)
+	defer sub.Unsubscribe()
+
+	want := []*core.DroppedTx{
+		{Tx: original, Reason: core.TxDropReplaced, Replacement: replacement.Hash()},
+		{Tx: expired, Reason: core.TxDropExpired},
+	}
+	backend.droppedTxFeed.Send(core.DroppedTxsEvent{Txs: want})
+
+	select {
+	case have := <-drops:
+		if len(have) != len(want) {
+			t.Fatalf("invalid number of dropped transactions, want %d, got %d", len(want), len(have))
+		}
+		for i := range have {
+			if have[i].Tx.Hash() != want[i].Tx.Hash() || have[i].Reason != want[i].Reason || have[i].Replacement != want[i].Replacement {
+				t.Errorf("drop %d mismatch: have %+v, want %+v", i, have[i], want[i])
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dropped transactions timeout")
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
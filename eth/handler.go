@@ -173,7 +173,13 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	// Construct the downloader (long sync) and its backing state bloom if snap
 	// sync is requested. The downloader is responsible for deallocating the state
 	// bloom when it's done.
-	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.eventMux, h.chain, nil, h.removePeer)
+	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.eventMux, h.chain, nil, func(id string, invalid bool) {
+		if invalid {
+			h.removePeer(id, p2p.PenaltyInvalid, "invalid chain")
+		} else {
+			h.removePeer(id, p2p.PenaltyUseless, "sync failed")
+		}
+	})
 
 	// Construct the fetcher (short sync)
 	validator := func(header *types.Header) error {
@@ -245,7 +251,9 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return n, err
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, func(id string) {
+		h.removePeer(id, p2p.PenaltyInvalid, "invalid block")
+	})
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -380,7 +388,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 
 			case <-timeout.C:
 				peer.Log().Warn("Checkpoint challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				h.removePeer(peer.ID(), p2p.PenaltyTimeout, "checkpoint challenge timed out")
 
 			case <-dead:
 				// Peer handler terminated, abort all goroutines
@@ -420,7 +428,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 
 			case <-timeout.C:
 				peer.Log().Warn("Whitelist challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				h.removePeer(peer.ID(), p2p.PenaltyTimeout, "whitelist challenge timed out")
 			}
 		}(number, hash)
 	}
@@ -443,10 +451,12 @@ func (h *handler) runSnapExtension(peer *snap.Peer, handler snap.Handler) error
 	return handler(peer)
 }
 
-// removePeer requests disconnection of a peer.
-func (h *handler) removePeer(id string) {
+// removePeer requests disconnection of a peer, lowering its reputation by the
+// given penalty for the reason it is being dropped.
+func (h *handler) removePeer(id string, penalty p2p.Penalty, reason string) {
 	peer := h.peers.peer(id)
 	if peer != nil {
+		peer.Peer.Penalize(penalty, reason)
 		peer.Peer.Disconnect(p2p.DiscUselessPeer)
 	}
 }
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -153,11 +154,21 @@ func Handle(backend Backend, peer *Peer) error {
 	for {
 		if err := handleMessage(backend, peer); err != nil {
 			peer.Log().Debug("Message handling failed in `eth`", "err", err)
+			if misbehaving(err) {
+				peer.Penalize(p2p.PenaltyInvalid, err.Error())
+			}
 			return err
 		}
 	}
 }
 
+// misbehaving reports whether a message handling error was caused by the remote
+// peer violating the protocol, as opposed to a failing connection.
+func misbehaving(err error) bool {
+	return errors.Is(err, errMsgTooLarge) || errors.Is(err, errDecode) || errors.Is(err, errInvalidMsgCode) ||
+		errors.Is(err, errDanglingResponse) || errors.Is(err, errMismatchingResponseType)
+}
+
 type msgHandler func(backend Backend, msg Decoder, peer *Peer) error
 type Decoder interface {
 	Decode(val interface{}) error
@@ -18,6 +18,7 @@ package snap
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"time"
 
@@ -122,11 +123,21 @@ func Handle(backend Backend, peer *Peer) error {
 	for {
 		if err := HandleMessage(backend, peer); err != nil {
 			peer.Log().Debug("Message handling failed in `snap`", "err", err)
+			if misbehaving(err) {
+				peer.Penalize(p2p.PenaltyInvalid, err.Error())
+			}
 			return err
 		}
 	}
 }
 
+// misbehaving reports whether a message handling error was caused by the remote
+// peer violating the protocol, as opposed to a failing connection.
+func misbehaving(err error) bool {
+	return errors.Is(err, errMsgTooLarge) || errors.Is(err, errDecode) || errors.Is(err, errInvalidMsgCode) ||
+		errors.Is(err, errBadRequest)
+}
+
 // HandleMessage is invoked whenever an inbound message is received from a
 // remote peer on the `snap` protocol. The remote connection is torn down upon
 // returning any error.
@@ -37,6 +37,7 @@ import (
 	"github.com/ubiq/go-ubiq/v7/ethdb"
 	"github.com/ubiq/go-ubiq/v7/event"
 	"github.com/ubiq/go-ubiq/v7/log"
+	"github.com/ubiq/go-ubiq/v7/p2p"
 	"github.com/ubiq/go-ubiq/v7/p2p/msgrate"
 	"github.com/ubiq/go-ubiq/v7/rlp"
 	"github.com/ubiq/go-ubiq/v7/trie"
@@ -379,6 +380,19 @@ type SyncPeer interface {
 	Log() log.Logger
 }
 
+// penalizer is implemented by sync peers whose reputation is tracked, allowing
+// the syncer to report timed out and useless requests.
+type penalizer interface {
+	Penalize(penalty p2p.Penalty, reason string)
+}
+
+// penalizePeer reports a misbehaving sync peer if its reputation is tracked.
+func penalizePeer(peer SyncPeer, penalty p2p.Penalty, reason string) {
+	if p, ok := peer.(penalizer); ok {
+		p.Penalize(penalty, reason)
+	}
+}
+
 // Syncer is an Ethereum account and storage trie syncer based on snapshots and
 // the  snap protocol. It's purpose is to download all the accounts and storage
 // slots from remote peers and reassemble chunks of the state trie, on top of
@@ -950,6 +964,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Account range request timed out", "reqid", reqid)
+			penalizePeer(peer, p2p.PenaltyTimeout, "account range request timed out")
 			s.rates.Update(idle, AccountRangeMsg, 0, 0)
 			s.scheduleRevertAccountRequest(req)
 		})
@@ -1061,6 +1076,7 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode request timed out", "reqid", reqid)
+			penalizePeer(peer, p2p.PenaltyTimeout, "bytecode request timed out")
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeRequest(req)
 		})
@@ -1208,6 +1224,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Storage request timed out", "reqid", reqid)
+			penalizePeer(peer, p2p.PenaltyTimeout, "storage request timed out")
 			s.rates.Update(idle, StorageRangesMsg, 0, 0)
 			s.scheduleRevertStorageRequest(req)
 		})
@@ -1341,6 +1358,7 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Trienode heal request timed out", "reqid", reqid)
+			penalizePeer(peer, p2p.PenaltyTimeout, "trienode heal request timed out")
 			s.rates.Update(idle, TrieNodesMsg, 0, 0)
 			s.scheduleRevertTrienodeHealRequest(req)
 		})
@@ -1457,6 +1475,7 @@ func (s *Syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode heal request timed out", "reqid", reqid)
+			penalizePeer(peer, p2p.PenaltyTimeout, "bytecode heal request timed out")
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeHealRequest(req)
 		})
@@ -2272,6 +2291,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 		logger.Debug("Peer rejected account range request", "root", s.root)
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		penalizePeer(peer, p2p.PenaltyUseless, "rejected account range request")
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertAccountRequest(req)
@@ -2384,6 +2404,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 		logger.Debug("Peer rejected bytecode request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		penalizePeer(peer, p2p.PenaltyUseless, "rejected bytecode request")
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertBytecodeRequest(req)
@@ -2508,6 +2529,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 		logger.Debug("Peer rejected storage request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		penalizePeer(peer, p2p.PenaltyUseless, "rejected storage request")
 		s.scheduleRevertStorageRequest(req) // reschedule request
 		return nil
 	}
@@ -2620,6 +2642,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 		logger.Debug("Peer rejected trienode heal request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		penalizePeer(peer, p2p.PenaltyUseless, "rejected trienode heal request")
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertTrienodeHealRequest(req)
@@ -2715,6 +2738,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 		logger.Debug("Peer rejected bytecode heal request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		penalizePeer(peer, p2p.PenaltyUseless, "rejected bytecode heal request")
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertBytecodeHealRequest(req)
@@ -306,6 +306,43 @@ func TestTraceCall(t *testing.T) {
 	}
 }
 
+func TestTraceCallWithBlockOverrides(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts, the contract returns the current block number
+	accounts := newAccounts(2)
+	contract := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		contract: {Balance: common.Big0, Code: []byte{
+			byte(vm.NUMBER), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		}},
+	}}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {}))
+
+	for i, tt := range []struct {
+		overrides *ethapi.BlockOverrides
+		want      *big.Int
+	}{
+		{nil, big.NewInt(1)},
+		{&ethapi.BlockOverrides{Number: (*hexutil.Big)(big.NewInt(0x1337))}, big.NewInt(0x1337)},
+	} {
+		config := &TraceCallConfig{BlockOverrides: tt.overrides}
+		result, err := api.TraceCall(context.Background(), ethapi.TransactionArgs{
+			From: &accounts[0].addr,
+			To:   &contract,
+		}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), config)
+		if err != nil {
+			t.Fatalf("test %d: failed to trace call: %v", i, err)
+		}
+		output := common.FromHex(result.(*ethapi.ExecutionResult).ReturnValue)
+		if number := new(big.Int).SetBytes(output); number.Cmp(tt.want) != 0 {
+			t.Errorf("test %d: block number mismatch: have %v, want %v", i, number, tt.want)
+		}
+	}
+}
+
 func TestTraceTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,223 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core"
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/core/vm"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/eth/tracers"
+	"github.com/ubiq/go-ubiq/v7/params"
+	"github.com/ubiq/go-ubiq/v7/tests"
+)
+
+// gasProfile is the result of a gasProfileTracer run.
+type gasProfile struct {
+	GasUsed uint64 `json:"gasUsed"`
+	Opcodes map[string]struct {
+		Count uint64 `json:"count"`
+		Gas   uint64 `json:"gas"`
+	} `json:"opcodes"`
+	Contracts map[common.Address]uint64 `json:"contracts"`
+}
+
+// Tests that the gas profile tracer attributes the gas forwarded by calls to
+// the callees, so that the opcode, precompile and intrinsic costs add up to the
+// gas used by the transaction.
+func TestGasProfileTracer(t *testing.T) {
+	var (
+		to       = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		identity = common.BytesToAddress([]byte{0x04})
+		empty    = common.BytesToAddress([]byte{0xff})
+	)
+	var code = []byte{
+		// Call the identity precompile with all the available gas
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+		byte(vm.DUP1), byte(vm.PUSH1), 0x04, byte(vm.GAS),
+		byte(vm.CALL), byte(vm.POP),
+		// Call an empty account with all the available gas
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+		byte(vm.DUP1), byte(vm.PUSH1), 0xff, byte(vm.GAS),
+		byte(vm.CALL), byte(vm.POP),
+		// Call an empty account with value beyond the balance, which fails
+		// without entering the callee
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0xff, byte(vm.GAS),
+		byte(vm.CALL), byte(vm.POP),
+		byte(vm.STOP),
+	}
+	have, result := runGasProfile(t, to, code, nil)
+	if have.GasUsed != result.UsedGas {
+		t.Errorf("gas used mismatch: have %d, want %d", have.GasUsed, result.UsedGas)
+	}
+	if call := have.Opcodes["CALL"]; call.Count != 3 {
+		t.Errorf("CALL count mismatch: have %d, want 3", call.Count)
+	}
+	if pop := have.Opcodes["POP"]; pop.Count != 3 || pop.Gas != 3*2 {
+		t.Errorf("POP profile mismatch: have %+v, want count 3, gas 6", pop)
+	}
+	// Identity with empty input costs its base price only
+	if gas := have.Contracts[identity]; gas != params.IdentityBaseGas {
+		t.Errorf("identity gas mismatch: have %d, want %d", gas, params.IdentityBaseGas)
+	}
+	if gas, ok := have.Contracts[empty]; ok {
+		t.Errorf("empty account profiled with %d gas", gas)
+	}
+	var opcodes uint64
+	for _, profile := range have.Opcodes {
+		opcodes += profile.Gas
+	}
+	if have.Contracts[to] != opcodes {
+		t.Errorf("contract gas mismatch: have %d, want %d", have.Contracts[to], opcodes)
+	}
+	if total := opcodes + have.Contracts[identity] + params.TxGas; total != result.UsedGas {
+		t.Errorf("profiled gas mismatch: have %d, want %d", total, result.UsedGas)
+	}
+}
+
+// Tests that a value transfer failing for lack of balance deducts the refunded
+// stipend from the calling opcode, along with the forwarded gas.
+func TestGasProfileTracerFailedValueCall(t *testing.T) {
+	to := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	code := []byte{
+		// Transfer more value than the balance to an existing account
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+		byte(vm.PUSH1), 0x01, byte(vm.ADDRESS), byte(vm.PUSH2), 0xff, 0xff,
+		byte(vm.CALL), byte(vm.POP),
+		byte(vm.STOP),
+	}
+	have, result := runGasProfile(t, to, code, nil)
+
+	// The CALL pays for the warm account access and the value transfer, minus
+	// the stipend returned to the caller
+	call := have.Opcodes["CALL"]
+	if want := params.CallGasEIP150 + params.CallValueTransferGas - params.CallStipend; call.Count != 1 || call.Gas != want {
+		t.Errorf("CALL profile mismatch: have %+v, want count 1, gas %d", call, want)
+	}
+	var opcodes uint64
+	for _, profile := range have.Opcodes {
+		opcodes += profile.Gas
+	}
+	if have.Contracts[to] != opcodes {
+		t.Errorf("contract gas mismatch: have %d, want %d", have.Contracts[to], opcodes)
+	}
+	if total := opcodes + params.TxGas; total != result.UsedGas {
+		t.Errorf("profiled gas mismatch: have %d, want %d", total, result.UsedGas)
+	}
+}
+
+// Tests that the stipend of a value transfer entering the callee is deducted
+// from the calling opcode, so the profile adds up to the gas used.
+func TestGasProfileTracerValueCall(t *testing.T) {
+	to := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	code := []byte{
+		// Forward the value of the transaction to an empty account
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+		byte(vm.CALLVALUE), byte(vm.PUSH1), 0xff, byte(vm.GAS),
+		byte(vm.CALL), byte(vm.POP),
+		byte(vm.STOP),
+	}
+	have, result := runGasProfile(t, to, code, big.NewInt(1))
+	if result.Failed() {
+		t.Fatalf("transaction failed: %v", result.Err)
+	}
+	var opcodes uint64
+	for _, profile := range have.Opcodes {
+		opcodes += profile.Gas
+	}
+	if total := opcodes + params.TxGas; total != result.UsedGas {
+		t.Errorf("profiled gas mismatch: have %d, want %d", total, result.UsedGas)
+	}
+}
+
+// runGasProfile executes a transaction calling the given code with the gas
+// profile tracer.
+func runGasProfile(t *testing.T, to common.Address, code []byte, value *big.Int) (*gasProfile, *core.ExecutionResult) {
+	t.Helper()
+
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		GasPrice: big.NewInt(0),
+		Gas:      100000,
+		To:       &to,
+		Value:    value,
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: big.NewInt(1),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    common.Address{},
+		BlockNumber: new(big.Int).SetUint64(1500000),
+		Time:        new(big.Int).SetUint64(5),
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+	}
+	var alloc = core.GenesisAlloc{
+		to: core.GenesisAccount{
+			Nonce: 1,
+			Code:  code,
+		},
+		origin: core.GenesisAccount{
+			Nonce:   0,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	// Create the tracer, the EVM environment and run it
+	tracer, err := tracers.New("gasProfileTracer", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create gas profile tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, params.MainnetChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	result, err := st.TransitionDb()
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	// Retrieve the trace result and check its consistency
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	have := new(gasProfile)
+	if err := json.Unmarshal(res, have); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	return have, result
+}
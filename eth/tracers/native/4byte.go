@@ -55,11 +55,11 @@ type fourByteTracer struct {
 
 // newFourByteTracer returns a native go tracer which collects
 // 4 byte-identifiers of a tx, and implements vm.EVMLogger.
-func newFourByteTracer() tracers.Tracer {
+func newFourByteTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
 	t := &fourByteTracer{
 		ids: make(map[string]int),
 	}
-	return t
+	return t, nil
 }
 
 // isPrecompiled returns whether the addr is a precompile. Logic borrowed from newJsTracer in eth/tracers/js/tracer.go
@@ -123,6 +123,10 @@ func (t *fourByteTracer) CaptureEnter(op vm.OpCode, from common.Address, to comm
 func (t *fourByteTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 }
 
+func (t *fourByteTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *fourByteTracer) CaptureTxEnd(restGas uint64) {}
+
 // CaptureFault implements the EVMLogger interface to trace an execution fault.
 func (t *fourByteTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
 }
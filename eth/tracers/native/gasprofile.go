@@ -0,0 +1,237 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core/vm"
+	"github.com/ubiq/go-ubiq/v7/eth/tracers"
+)
+
+func init() {
+	register("gasProfileTracer", newGasProfileTracer)
+}
+
+// opcodeProfile aggregates the executions of a single opcode.
+type opcodeProfile struct {
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// gasProfileTracer counts the executed opcodes and the gas spent by them, along
+// with the gas spent executing the code of every contract. The gas forwarded to
+// sub-calls is attributed to the callee's own opcodes, not to the calling opcode.
+// Gas spent in precompiled contracts is only reported among the contract totals.
+// The stipend granted to value transfers is deducted from the calling opcode
+// along with the forwarded gas, as the callee's opcodes account for the part of
+// it used and the rest is returned to the caller.
+//
+// Example:
+//   > debug.traceTransaction("0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "gasProfileTracer"})
+//   {
+//     gasUsed: 43857,
+//     opcodes: {
+//       ADD: { count: 4, gas: 12 },
+//       CALL: { count: 1, gas: 2600 },
+//       ...
+//     },
+//     contracts: {
+//       0x00000000000000000000000000000000deadbeef: 19341,
+//       ...
+//     }
+//   }
+type gasProfileTracer struct {
+	env       *vm.EVM
+	opcodes   map[string]*opcodeProfile
+	contracts map[common.Address]uint64
+	gasLimit  uint64 // Amount of gas bought for the whole tx
+	gasUsed   uint64 // Amount of gas used by the whole tx
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+
+	// Set by CaptureState for call-family opcodes, whose cost includes the gas
+	// forwarded to the callee. Settled by the matching CaptureEnter, or by the
+	// next step of the caller if the call failed before entering the callee.
+	pendingCall *pendingCall
+
+	activePrecompiles []common.Address  // Updated on CaptureStart based on given rules
+	precompiles       []*common.Address // Precompile entered by each open call frame, if any
+}
+
+// pendingCall tracks a call opcode until the gas forwarded to the callee is known.
+type pendingCall struct {
+	op       vm.OpCode
+	contract common.Address
+	depth    int
+	gasLeft  uint64 // Gas left in the caller after paying for the opcode
+}
+
+// newGasProfileTracer returns a native go tracer which profiles the opcode and
+// contract gas usage of a tx, and implements vm.EVMLogger.
+func newGasProfileTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	return &gasProfileTracer{
+		opcodes:   make(map[string]*opcodeProfile),
+		contracts: make(map[common.Address]uint64),
+	}, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *gasProfileTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+
+	// Update list of precompiles based on current block
+	rules := env.ChainConfig().Rules(env.Context.BlockNumber)
+	t.activePrecompiles = vm.ActivePrecompiles(rules)
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *gasProfileTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.env.Cancel()
+		return
+	}
+	// If a call did not enter the callee, the forwarded gas was refunded as is,
+	// together with the stipend of value transfers.
+	if call := t.pendingCall; call != nil && call.depth == depth && gas > call.gasLeft {
+		t.deductForwarded(call, gas-call.gasLeft)
+	}
+	// Opcodes are attributed to the account whose code is running, which for
+	// delegated calls is the library, not the storage owner.
+	contract := scope.Contract.Address()
+	if scope.Contract.CodeAddr != nil {
+		contract = *scope.Contract.CodeAddr
+	}
+	profile := t.opcodes[op.String()]
+	if profile == nil {
+		profile = new(opcodeProfile)
+		t.opcodes[op.String()] = profile
+	}
+	profile.Count++
+	profile.Gas += cost
+	t.contracts[contract] += cost
+
+	// The cost of call-family opcodes includes the gas forwarded to the callee,
+	// which is deducted once the sub-call starts.
+	t.pendingCall = nil
+	if err == nil && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL) {
+		t.pendingCall = &pendingCall{op: op, contract: contract, depth: depth, gasLeft: gas - cost}
+	}
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *gasProfileTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *gasProfileTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *gasProfileTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	var precompile *common.Address
+	if t.isPrecompiled(to) {
+		precompile = &to
+	}
+	t.precompiles = append(t.precompiles, precompile)
+
+	call := t.pendingCall
+	t.pendingCall = nil
+	if call == nil || call.op != typ {
+		return
+	}
+	// Value transfers receive a stipend on top of the forwarded gas. Whatever
+	// the callee doesn't use of either is returned to the caller, so both are
+	// deducted.
+	t.deductForwarded(call, gas)
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *gasProfileTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.precompiles) == 0 {
+		return
+	}
+	precompile := t.precompiles[len(t.precompiles)-1]
+	t.precompiles = t.precompiles[:len(t.precompiles)-1]
+
+	// Precompiles run no opcodes, account their gas to the contract only
+	if precompile != nil {
+		t.contracts[*precompile] += gasUsed
+	}
+}
+
+// deductForwarded removes the gas forwarded by a call opcode from the opcode's
+// and the caller's totals.
+func (t *gasProfileTracer) deductForwarded(call *pendingCall, forwarded uint64) {
+	t.pendingCall = nil
+
+	profile := t.opcodes[call.op.String()]
+	if profile.Gas < forwarded {
+		profile.Gas = 0
+	} else {
+		profile.Gas -= forwarded
+	}
+	if t.contracts[call.contract] < forwarded {
+		t.contracts[call.contract] = 0
+	} else {
+		t.contracts[call.contract] -= forwarded
+	}
+}
+
+// isPrecompiled returns whether the addr is a precompile.
+func (t *gasProfileTracer) isPrecompiled(addr common.Address) bool {
+	for _, p := range t.activePrecompiles {
+		if p == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *gasProfileTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+func (t *gasProfileTracer) CaptureTxEnd(restGas uint64) {
+	t.gasUsed = t.gasLimit - restGas
+}
+
+// GetResult returns the json-encoded gas profile, and any error arising from
+// the encoding or forceful termination (via `Stop`).
+func (t *gasProfileTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(struct {
+		GasUsed   uint64                    `json:"gasUsed"`
+		Opcodes   map[string]*opcodeProfile `json:"opcodes"`
+		Contracts map[common.Address]uint64 `json:"contracts"`
+	}{t.gasUsed, t.opcodes, t.contracts})
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *gasProfileTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
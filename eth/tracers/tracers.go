@@ -42,12 +42,16 @@ type Tracer interface {
 	Stop(err error)
 }
 
-type lookupFunc func(string, *Context) (Tracer, error)
+type lookupFunc func(string, *Context, json.RawMessage) (Tracer, error)
 
 var (
 	lookups []lookupFunc
 )
 
+// ErrTracerNotFound is returned by a lookup if it doesn't know the requested
+// tracer, so the next registered lookup can be tried.
+var ErrTracerNotFound = errors.New("tracer not found")
+
 // RegisterLookup registers a method as a lookup for tracers, meaning that
 // users can invoke a named tracer through that lookup. If 'wildcard' is true,
 // then the lookup will be placed last. This is typically meant for interpreted
@@ -61,12 +65,18 @@ func RegisterLookup(wildcard bool, lookup lookupFunc) {
 }
 
 // New returns a new instance of a tracer, by iterating through the
-// registered lookups.
-func New(code string, ctx *Context) (Tracer, error) {
+// registered lookups. The optional cfg is passed verbatim to the tracer
+// and is interpreted by it. If a lookup knows the tracer but fails to
+// create it, the error is returned without trying any further lookups.
+func New(code string, ctx *Context, cfg json.RawMessage) (Tracer, error) {
 	for _, lookup := range lookups {
-		if tracer, err := lookup(code, ctx); err == nil {
+		tracer, err := lookup(code, ctx, cfg)
+		if err == nil {
 			return tracer, nil
 		}
+		if !errors.Is(err, ErrTracerNotFound) {
+			return nil, err
+		}
 	}
-	return nil, errors.New("tracer not found")
+	return nil, ErrTracerNotFound
 }
@@ -291,6 +291,9 @@ func TestEthClient(t *testing.T) {
 		"TransactionSender": {
 			func(t *testing.T) { testTransactionSender(t, client) },
 		},
+		"BlockReceipts": {
+			func(t *testing.T) { testBlockReceipts(t, chain, client) },
+		},
 	}
 
 	t.Parallel()
@@ -411,6 +414,48 @@ func testTransactionInBlockInterrupted(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testBlockReceipts(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+
+	// Retrieve the receipts of the block containing the test transactions,
+	// both by number and by hash.
+	block := chain[2]
+	byNumber, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(block.NumberU64())))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byHash, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(byNumber, byHash) {
+		t.Fatalf("receipts mismatch between number and hash lookups")
+	}
+	txs := block.Transactions()
+	if len(byNumber) != len(txs) {
+		t.Fatalf("receipt count mismatch: have %d, want %d", len(byNumber), len(txs))
+	}
+	for i, receipt := range byNumber {
+		if receipt.TxHash != txs[i].Hash() {
+			t.Errorf("receipt %d: tx hash mismatch: have %x, want %x", i, receipt.TxHash, txs[i].Hash())
+		}
+		if receipt.BlockHash != block.Hash() || receipt.TransactionIndex != uint(i) {
+			t.Errorf("receipt %d: position mismatch: have %x/%d", i, receipt.BlockHash, receipt.TransactionIndex)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			t.Errorf("receipt %d: status mismatch: have %d", i, receipt.Status)
+		}
+	}
+	// Blocks without transactions return no receipts, missing ones are not found
+	receipts, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(1))
+	if err != nil || len(receipts) != 0 {
+		t.Fatalf("unexpected receipts for empty block: %v %v", receipts, err)
+	}
+	if _, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(common.Hash{1}, false)); err != ethereum.NotFound {
+		t.Fatalf("error mismatch: have %v, want %v", err, ethereum.NotFound)
+	}
+}
+
 func testChainID(t *testing.T, client *rpc.Client) {
 	ec := NewClient(client)
 	id, err := ec.ChainID(context.Background())
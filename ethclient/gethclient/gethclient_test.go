@@ -24,10 +24,12 @@ import (
 
 	ethereum "github.com/ubiq/go-ubiq/v7"
 	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
 	"github.com/ubiq/go-ubiq/v7/consensus/ubqhash"
 	"github.com/ubiq/go-ubiq/v7/core"
 	"github.com/ubiq/go-ubiq/v7/core/rawdb"
 	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/core/vm"
 	"github.com/ubiq/go-ubiq/v7/crypto"
 	"github.com/ubiq/go-ubiq/v7/eth"
 	"github.com/ubiq/go-ubiq/v7/eth/ethconfig"
@@ -126,6 +128,9 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestCallContract",
 			func(t *testing.T) { testCallContract(t, client) },
+		}, {
+			"TestCallMany",
+			func(t *testing.T) { testCallMany(t, client) },
 		},
 	}
 	t.Parallel()
@@ -306,3 +311,52 @@ func testCallContract(t *testing.T, client *rpc.Client) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func testCallMany(t *testing.T, client *rpc.Client) {
+	// The contract stores the calldata word and emits a log if called with data,
+	// otherwise it returns the stored word.
+	contract := common.HexToAddress("0x00000000000000000000000000000000c0ffee")
+	code := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0x0f, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0), byte(vm.STOP),
+	}
+	word := common.BigToHash(big.NewInt(42))
+	calls := []map[string]interface{}{
+		{"from": testAddr, "to": contract},
+		{"from": testAddr, "to": contract, "data": hexutil.Bytes(word[:])},
+		{"from": testAddr, "to": contract},
+	}
+	overrides := map[common.Address]map[string]interface{}{
+		contract: {"code": hexutil.Bytes(code)},
+	}
+	var results []struct {
+		ReturnData hexutil.Bytes  `json:"returnData"`
+		Logs       []*types.Log   `json:"logs"`
+		GasUsed    hexutil.Uint64 `json:"gasUsed"`
+		Error      string         `json:"error"`
+	}
+	if err := client.CallContext(context.Background(), &results, "eth_callMany", calls, "latest", overrides, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), len(calls))
+	}
+	// The first call sees the empty slot, the last one the stored word
+	if have := common.BytesToHash(results[0].ReturnData); have != (common.Hash{}) {
+		t.Errorf("initial slot mismatch: have %x, want empty", have)
+	}
+	if len(results[1].Logs) != 1 || results[1].Logs[0].Address != contract {
+		t.Errorf("store call logs mismatch: have %v", results[1].Logs)
+	}
+	if have := common.BytesToHash(results[2].ReturnData); have != word {
+		t.Errorf("stored slot mismatch: have %x, want %x", have, word)
+	}
+	for i, res := range results {
+		if res.Error != "" || res.GasUsed == 0 {
+			t.Errorf("call %d: unexpected outcome: error %q, gas %d", i, res.Error, res.GasUsed)
+		}
+	}
+}
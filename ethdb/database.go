@@ -86,6 +86,10 @@ type AncientReader interface {
 	// Ancients returns the ancient item numbers in the ancient store.
 	Ancients() (uint64, error)
 
+	// AncientTail returns the number of the first item retained in the specified
+	// category of the ancient store, older items having been discarded.
+	AncientTail(kind string) (uint64, error)
+
 	// AncientSize returns the ancient size of the specified category.
 	AncientSize(kind string) (uint64, error)
 }
@@ -109,6 +113,10 @@ type AncientWriter interface {
 	// TruncateAncients discards all but the first n ancient data from the ancient store.
 	TruncateAncients(n uint64) error
 
+	// TruncateTail discards the ancient data of the specified category below item
+	// n, retaining the numbering of the remaining items.
+	TruncateTail(kind string, n uint64) error
+
 	// Sync flushes all in-memory ancient store data to disk.
 	Sync() error
 }
@@ -222,6 +222,10 @@ const schema string = `
         # transactions are unavailable for this block, or if the index is out of
         # bounds, this field will be null.
         transactionAt(index: Int!): Transaction
+        # RawReceipts is the list of binary encoded receipts of the transactions
+        # in this block, in order. If receipts are unavailable for this block,
+        # this field will be null.
+        rawReceipts: [Bytes!]
         # Logs returns a filtered set of logs from this block.
         logs(filter: BlockFilterCriteria!): [Log!]!
         # Account fetches an Ubiq account at the current block's state.
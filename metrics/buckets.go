@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Bucketed is implemented by histograms and timers which, besides sampling
+// their values, count every recorded value into a fixed set of buckets. As
+// opposed to samples, bucket counts are never decayed, which makes them
+// suitable for aggregation across time and across multiple processes.
+type Bucketed interface {
+	Buckets() *BucketsSnapshot
+}
+
+// DefaultTimerBuckets are bucket upper bounds suitable for timers, covering
+// durations from 50µs to a minute, in nanoseconds.
+var DefaultTimerBuckets = []float64{
+	float64(50 * time.Microsecond), float64(100 * time.Microsecond),
+	float64(250 * time.Microsecond), float64(500 * time.Microsecond),
+	float64(time.Millisecond), float64(2500 * time.Microsecond),
+	float64(5 * time.Millisecond), float64(10 * time.Millisecond),
+	float64(25 * time.Millisecond), float64(50 * time.Millisecond),
+	float64(100 * time.Millisecond), float64(250 * time.Millisecond),
+	float64(500 * time.Millisecond), float64(time.Second),
+	float64(2500 * time.Millisecond), float64(5 * time.Second),
+	float64(10 * time.Second), float64(30 * time.Second),
+	float64(time.Minute),
+}
+
+// buckets counts values into buckets delimited by sorted upper bounds. Values
+// above the last bound are only accounted for in the total count. The counts
+// are updated atomically, so recording values never blocks.
+type buckets struct {
+	sum    int64 // Accessed atomically, kept first for 64-bit alignment
+	bounds []float64
+	counts []uint64 // Non-cumulative count per bound, plus one for overflows
+}
+
+// newBuckets creates a bucket counter with the given upper bounds.
+func newBuckets(bounds []float64) *buckets {
+	bounds = append([]float64{}, bounds...)
+	sort.Float64s(bounds)
+
+	return &buckets{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Clear resets all the bucket counts.
+func (b *buckets) Clear() {
+	for i := range b.counts {
+		atomic.StoreUint64(&b.counts[i], 0)
+	}
+	atomic.StoreInt64(&b.sum, 0)
+}
+
+// Update counts a new value into its bucket.
+func (b *buckets) Update(v int64) {
+	atomic.AddUint64(&b.counts[sort.SearchFloat64s(b.bounds, float64(v))], 1)
+	atomic.AddInt64(&b.sum, v)
+}
+
+// Snapshot returns a read-only copy of the cumulative bucket counts. Values
+// recorded concurrently may or may not be included.
+func (b *buckets) Snapshot() *BucketsSnapshot {
+	counts := make([]uint64, len(b.bounds))
+	var total uint64
+	for i := range b.bounds {
+		total += atomic.LoadUint64(&b.counts[i])
+		counts[i] = total
+	}
+	return &BucketsSnapshot{
+		bounds: b.bounds,
+		counts: counts,
+		count:  total + atomic.LoadUint64(&b.counts[len(b.bounds)]),
+		sum:    atomic.LoadInt64(&b.sum),
+	}
+}
+
+// BucketsSnapshot is a read-only copy of the bucket counts of a histogram.
+type BucketsSnapshot struct {
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    int64
+}
+
+// Bounds returns the upper bounds of the buckets, in increasing order.
+func (b *BucketsSnapshot) Bounds() []float64 { return b.bounds }
+
+// Counts returns the cumulative number of values recorded in each bucket, that
+// is the number of values less than or equal to the bucket's upper bound.
+func (b *BucketsSnapshot) Counts() []uint64 { return b.counts }
+
+// Count returns the total number of values recorded, including the ones
+// exceeding the largest bucket bound.
+func (b *BucketsSnapshot) Count() uint64 { return b.count }
+
+// Sum returns the sum of all the values recorded.
+func (b *BucketsSnapshot) Sum() int64 { return b.sum }
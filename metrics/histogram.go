@@ -43,6 +43,15 @@ func NewHistogram(s Sample) Histogram {
 	return &StandardHistogram{sample: s}
 }
 
+// NewBucketedHistogram constructs a new StandardHistogram from a Sample, which
+// also counts its values into buckets with the given upper bounds.
+func NewBucketedHistogram(s Sample, bounds []float64) Histogram {
+	if !Enabled {
+		return NilHistogram{}
+	}
+	return &StandardHistogram{sample: s, buckets: newBuckets(bounds)}
+}
+
 // NewRegisteredHistogram constructs and registers a new StandardHistogram from
 // a Sample.
 func NewRegisteredHistogram(name string, r Registry, s Sample) Histogram {
@@ -56,9 +65,13 @@ func NewRegisteredHistogram(name string, r Registry, s Sample) Histogram {
 
 // HistogramSnapshot is a read-only copy of another Histogram.
 type HistogramSnapshot struct {
-	sample *SampleSnapshot
+	sample  *SampleSnapshot
+	buckets *BucketsSnapshot
 }
 
+// Buckets returns the bucket counts at the time the snapshot was taken.
+func (h *HistogramSnapshot) Buckets() *BucketsSnapshot { return h.buckets }
+
 // Clear panics.
 func (*HistogramSnapshot) Clear() {
 	panic("Clear called on a HistogramSnapshot")
@@ -160,11 +173,26 @@ func (NilHistogram) Variance() float64 { return 0.0 }
 // StandardHistogram is the standard implementation of a Histogram and uses a
 // Sample to bound its memory use.
 type StandardHistogram struct {
-	sample Sample
+	sample  Sample
+	buckets *buckets
+}
+
+// Buckets returns a read-only copy of the histogram's bucket counts, or nil if
+// it does not count its values into buckets.
+func (h *StandardHistogram) Buckets() *BucketsSnapshot {
+	if h.buckets == nil {
+		return nil
+	}
+	return h.buckets.Snapshot()
 }
 
-// Clear clears the histogram and its sample.
-func (h *StandardHistogram) Clear() { h.sample.Clear() }
+// Clear clears the histogram, its sample and its buckets.
+func (h *StandardHistogram) Clear() {
+	h.sample.Clear()
+	if h.buckets != nil {
+		h.buckets.Clear()
+	}
+}
 
 // Count returns the number of samples recorded since the histogram was last
 // cleared.
@@ -195,7 +223,10 @@ func (h *StandardHistogram) Sample() Sample { return h.sample }
 
 // Snapshot returns a read-only copy of the histogram.
 func (h *StandardHistogram) Snapshot() Histogram {
-	return &HistogramSnapshot{sample: h.sample.Snapshot().(*SampleSnapshot)}
+	return &HistogramSnapshot{
+		sample:  h.sample.Snapshot().(*SampleSnapshot),
+		buckets: h.Buckets(),
+	}
 }
 
 // StdDev returns the standard deviation of the values in the sample.
@@ -205,7 +236,12 @@ func (h *StandardHistogram) StdDev() float64 { return h.sample.StdDev() }
 func (h *StandardHistogram) Sum() int64 { return h.sample.Sum() }
 
 // Update samples a new value.
-func (h *StandardHistogram) Update(v int64) { h.sample.Update(v) }
+func (h *StandardHistogram) Update(v int64) {
+	h.sample.Update(v)
+	if h.buckets != nil {
+		h.buckets.Update(v)
+	}
+}
 
 // Variance returns the variance of the values in the sample.
 func (h *StandardHistogram) Variance() float64 { return h.sample.Variance() }
@@ -93,3 +93,27 @@ func testHistogram10000(t *testing.T, h Histogram) {
 		t.Errorf("99th percentile: 9900.99 != %v\n", ps[2])
 	}
 }
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewBucketedHistogram(NewUniformSample(10), []float64{10, 100, 1000})
+	for i := 1; i <= 2000; i++ {
+		h.Update(int64(i))
+	}
+	snapshot := h.Snapshot()
+	h.Update(1)
+
+	b := snapshot.(Bucketed).Buckets()
+	if counts := b.Counts(); counts[0] != 10 || counts[1] != 100 || counts[2] != 1000 {
+		t.Errorf("b.Counts(): [10 100 1000] != %v\n", counts)
+	}
+	if count := b.Count(); count != 2000 {
+		t.Errorf("b.Count(): 2000 != %v\n", count)
+	}
+	if sum := b.Sum(); sum != 2001000 {
+		t.Errorf("b.Sum(): 2001000 != %v\n", sum)
+	}
+	h.Clear()
+	if count := h.(Bucketed).Buckets().Count(); count != 0 {
+		t.Errorf("b.Count(): 0 != %v\n", count)
+	}
+}
@@ -21,98 +21,161 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ubiq/go-ubiq/v7/log"
 	"github.com/ubiq/go-ubiq/v7/metrics"
 )
 
 var (
-	typeGaugeTpl           = "# TYPE %s gauge\n"
-	typeCounterTpl         = "# TYPE %s counter\n"
-	typeSummaryTpl         = "# TYPE %s summary\n"
-	keyValueTpl            = "%s %v\n\n"
-	keyQuantileTagValueTpl = "%s {quantile=\"%s\"} %v\n"
+	typeGaugeTpl     = "# TYPE %s gauge\n"
+	typeCounterTpl   = "# TYPE %s counter\n"
+	typeSummaryTpl   = "# TYPE %s summary\n"
+	typeHistogramTpl = "# TYPE %s histogram\n"
+	keyValueTpl      = "%s%s %v\n"
+	keyQuantileTpl   = "%s {%squantile=\"%s\"} %v\n"
+	keyBucketTpl     = "%s_bucket{%sle=\"%s\"} %v\n"
 )
 
 // collector is a collection of byte buffers that aggregate Prometheus reports
-// for different metric types.
+// for different metric types. Reports are grouped by metric family, so that
+// all the labelled metrics of a family are listed under a single type line.
 type collector struct {
-	buff *bytes.Buffer
+	families map[string]*family
+	order    []string // Metric families in order of appearance
+}
+
+// family is the aggregated report of a single Prometheus metric family.
+type family struct {
+	typeTpl string
+	buff    *bytes.Buffer
 }
 
 // newCollector creates a new Prometheus metric aggregator.
 func newCollector() *collector {
 	return &collector{
-		buff: &bytes.Buffer{},
+		families: make(map[string]*family),
 	}
 }
 
 func (c *collector) addCounter(name string, m metrics.Counter) {
-	c.writeGaugeCounter(name, m.Count())
+	name, labels := familyOf(name)
+	c.writeGaugeCounter(name, labels, m.Count())
 }
 
 func (c *collector) addGauge(name string, m metrics.Gauge) {
-	c.writeGaugeCounter(name, m.Value())
+	name, labels := familyOf(name)
+	c.writeGaugeCounter(name, labels, m.Value())
 }
 
 func (c *collector) addGaugeFloat64(name string, m metrics.GaugeFloat64) {
-	c.writeGaugeCounter(name, m.Value())
+	name, labels := familyOf(name)
+	c.writeGaugeCounter(name, labels, m.Value())
 }
 
 func (c *collector) addHistogram(name string, m metrics.Histogram) {
+	name, labels := familyOf(name)
 	pv := []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
 	ps := m.Percentiles(pv)
-	c.writeSummaryCounter(name, m.Count())
-	c.buff.WriteString(fmt.Sprintf(typeSummaryTpl, mutateKey(name)))
+	c.writeSummaryCounter(name, labels, m.Count())
 	for i := range pv {
-		c.writeSummaryPercentile(name, strconv.FormatFloat(pv[i], 'f', -1, 64), ps[i])
+		c.writeSummaryPercentile(name, labels, strconv.FormatFloat(pv[i], 'f', -1, 64), ps[i])
+	}
+	if b, ok := m.(metrics.Bucketed); ok && b.Buckets() != nil {
+		c.writeHistogram(name+"_histogram", labels, b.Buckets(), 1)
 	}
-	c.buff.WriteRune('\n')
 }
 
 func (c *collector) addMeter(name string, m metrics.Meter) {
-	c.writeGaugeCounter(name, m.Count())
+	name, labels := familyOf(name)
+	c.writeGaugeCounter(name, labels, m.Count())
 }
 
 func (c *collector) addTimer(name string, m metrics.Timer) {
+	name, labels := familyOf(name)
 	pv := []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
 	ps := m.Percentiles(pv)
-	c.writeSummaryCounter(name, m.Count())
-	c.buff.WriteString(fmt.Sprintf(typeSummaryTpl, mutateKey(name)))
+	c.writeSummaryCounter(name, labels, m.Count())
 	for i := range pv {
-		c.writeSummaryPercentile(name, strconv.FormatFloat(pv[i], 'f', -1, 64), ps[i])
+		c.writeSummaryPercentile(name, labels, strconv.FormatFloat(pv[i], 'f', -1, 64), ps[i])
+	}
+	// Timers are measured in nanoseconds, export the buckets in base units
+	if b, ok := m.(metrics.Bucketed); ok && b.Buckets() != nil {
+		c.writeHistogram(name+"_seconds", labels, b.Buckets(), float64(time.Second))
 	}
-	c.buff.WriteRune('\n')
 }
 
 func (c *collector) addResettingTimer(name string, m metrics.ResettingTimer) {
 	if len(m.Values()) <= 0 {
 		return
 	}
+	name, labels := familyOf(name)
 	ps := m.Percentiles([]float64{50, 95, 99})
 	val := m.Values()
-	c.writeSummaryCounter(name, len(val))
-	c.buff.WriteString(fmt.Sprintf(typeSummaryTpl, mutateKey(name)))
-	c.writeSummaryPercentile(name, "0.50", ps[0])
-	c.writeSummaryPercentile(name, "0.95", ps[1])
-	c.writeSummaryPercentile(name, "0.99", ps[2])
-	c.buff.WriteRune('\n')
+	c.writeSummaryCounter(name, labels, len(val))
+	c.writeSummaryPercentile(name, labels, "0.50", ps[0])
+	c.writeSummaryPercentile(name, labels, "0.95", ps[1])
+	c.writeSummaryPercentile(name, labels, "0.99", ps[2])
 }
 
-func (c *collector) writeGaugeCounter(name string, value interface{}) {
+func (c *collector) writeGaugeCounter(name string, labels []label, value interface{}) {
 	name = mutateKey(name)
-	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
-	c.buff.WriteString(fmt.Sprintf(keyValueTpl, name, value))
+	c.write(name, typeGaugeTpl, fmt.Sprintf(keyValueTpl, name, formatLabels(labels), value))
 }
 
-func (c *collector) writeSummaryCounter(name string, value interface{}) {
+func (c *collector) writeSummaryCounter(name string, labels []label, value interface{}) {
 	name = mutateKey(name + "_count")
-	c.buff.WriteString(fmt.Sprintf(typeCounterTpl, name))
-	c.buff.WriteString(fmt.Sprintf(keyValueTpl, name, value))
+	c.write(name, typeCounterTpl, fmt.Sprintf(keyValueTpl, name, formatLabels(labels), value))
 }
 
-func (c *collector) writeSummaryPercentile(name, p string, value interface{}) {
+func (c *collector) writeSummaryPercentile(name string, labels []label, p string, value interface{}) {
 	name = mutateKey(name)
-	c.buff.WriteString(fmt.Sprintf(keyQuantileTagValueTpl, name, p, value))
+	c.write(name, typeSummaryTpl, fmt.Sprintf(keyQuantileTpl, name, formatLabelPrefix(labels), p, value))
+}
+
+// writeHistogram writes the cumulative bucket counts of a histogram, dividing
+// the bucket bounds and the sum of the values by the given unit.
+func (c *collector) writeHistogram(name string, labels []label, b *metrics.BucketsSnapshot, unit float64) {
+	name = mutateKey(name)
+
+	var (
+		prefix = formatLabelPrefix(labels)
+		report bytes.Buffer
+	)
+	for i, bound := range b.Bounds() {
+		report.WriteString(fmt.Sprintf(keyBucketTpl, name, prefix, strconv.FormatFloat(bound/unit, 'f', -1, 64), b.Counts()[i]))
+	}
+	report.WriteString(fmt.Sprintf(keyBucketTpl, name, prefix, "+Inf", b.Count()))
+	report.WriteString(fmt.Sprintf(keyValueTpl, name+"_sum", formatLabels(labels), strconv.FormatFloat(float64(b.Sum())/unit, 'f', -1, 64)))
+	report.WriteString(fmt.Sprintf(keyValueTpl, name+"_count", formatLabels(labels), b.Count()))
+
+	c.write(name, typeHistogramTpl, report.String())
+}
+
+// write appends a report to the given metric family, creating it if needed.
+func (c *collector) write(name string, typeTpl string, report string) {
+	f, ok := c.families[name]
+	if !ok {
+		f = &family{typeTpl: typeTpl, buff: new(bytes.Buffer)}
+		c.families[name] = f
+		c.order = append(c.order, name)
+	}
+	if f.typeTpl != typeTpl {
+		log.Warn("Dropping Prometheus metric of mismatching type", "family", name)
+		return
+	}
+	f.buff.WriteString(report)
+}
+
+// bytes returns the aggregated Prometheus report of all the metric families.
+func (c *collector) bytes() []byte {
+	buff := new(bytes.Buffer)
+	for _, name := range c.order {
+		buff.WriteString(fmt.Sprintf(c.families[name].typeTpl, name))
+		buff.Write(c.families[name].buff.Bytes())
+		buff.WriteRune('\n')
+	}
+	return buff.Bytes()
 }
 
 func mutateKey(key string) string {
@@ -1,7 +1,9 @@
 package prometheus
 
 import (
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -101,10 +103,144 @@ test_resetting_timer {quantile="0.95"} 120000000
 test_resetting_timer {quantile="0.99"} 120000000
 
 `
-	exp := c.buff.String()
+	exp := string(c.bytes())
 	if exp != expectedOutput {
 		t.Log("Expected Output:\n", expectedOutput)
 		t.Log("Actual Output:\n", exp)
 		t.Fatal("unexpected collector output")
 	}
 }
+
+func TestCollectorBuckets(t *testing.T) {
+	c := newCollector()
+
+	histogram := metrics.NewBucketedHistogram(&metrics.NilSample{}, []float64{10, 100})
+	histogram.Update(5)
+	histogram.Update(50)
+	histogram.Update(500)
+	c.addHistogram("test/histogram", histogram)
+
+	timer := metrics.NewBucketedTimer([]float64{float64(10 * time.Millisecond), float64(time.Second)})
+	defer timer.Stop()
+	timer.Update(5 * time.Millisecond)
+	timer.Update(250 * time.Millisecond)
+	c.addTimer("test/timer", timer)
+
+	const expectedOutput = `# TYPE test_histogram_count counter
+test_histogram_count 0
+
+# TYPE test_histogram summary
+test_histogram {quantile="0.5"} 0
+test_histogram {quantile="0.75"} 0
+test_histogram {quantile="0.95"} 0
+test_histogram {quantile="0.99"} 0
+test_histogram {quantile="0.999"} 0
+test_histogram {quantile="0.9999"} 0
+
+# TYPE test_histogram_histogram histogram
+test_histogram_histogram_bucket{le="10"} 1
+test_histogram_histogram_bucket{le="100"} 2
+test_histogram_histogram_bucket{le="+Inf"} 3
+test_histogram_histogram_sum 555
+test_histogram_histogram_count 3
+
+# TYPE test_timer_count counter
+test_timer_count 2
+
+# TYPE test_timer summary
+test_timer {quantile="0.5"} 1.275e+08
+test_timer {quantile="0.75"} 2.5e+08
+test_timer {quantile="0.95"} 2.5e+08
+test_timer {quantile="0.99"} 2.5e+08
+test_timer {quantile="0.999"} 2.5e+08
+test_timer {quantile="0.9999"} 2.5e+08
+
+# TYPE test_timer_seconds histogram
+test_timer_seconds_bucket{le="0.01"} 1
+test_timer_seconds_bucket{le="1"} 2
+test_timer_seconds_bucket{le="+Inf"} 2
+test_timer_seconds_sum 0.255
+test_timer_seconds_count 2
+
+`
+	exp := string(c.bytes())
+	if exp != expectedOutput {
+		t.Log("Expected Output:\n", expectedOutput)
+		t.Log("Actual Output:\n", exp)
+		t.Fatal("unexpected collector output")
+	}
+}
+
+func TestHandlerFamilies(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	metrics.NewRegisteredCounter("p2p/ingress", reg).Inc(300)
+	metrics.NewRegisteredCounter("p2p/ingress/eth/66/0x03", reg).Inc(100)
+	metrics.NewRegisteredCounter("p2p/ingress/snap/1/0x00", reg).Inc(200)
+	metrics.NewRegisteredCounter("p2p/ingress/eth/66/0x03/packets", reg).Inc(1)
+	metrics.NewRegisteredGauge("p2p/peers", reg).Update(5)
+
+	timer := metrics.NewRegisteredBucketedTimer("rpc/duration/eth_call/success", reg, metrics.DefaultTimerBuckets)
+	timer.Update(time.Millisecond)
+	defer timer.Stop()
+
+	req := httptest.NewRequest("GET", "/debug/metrics/prometheus", nil)
+	res := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(res, req)
+
+	const expectedOutput = `# TYPE p2p_ingress gauge
+p2p_ingress 300
+
+# TYPE p2p_ingress_bytes gauge
+p2p_ingress_bytes{protocol="eth",version="66",code="0x03"} 100
+p2p_ingress_bytes{protocol="snap",version="1",code="0x00"} 200
+
+# TYPE p2p_ingress_packets gauge
+p2p_ingress_packets{protocol="eth",version="66",code="0x03"} 1
+
+# TYPE p2p_peers gauge
+p2p_peers 5
+
+# TYPE rpc_duration_count counter
+rpc_duration_count{method="eth_call",result="success"} 1
+
+# TYPE rpc_duration summary
+rpc_duration {method="eth_call",result="success",quantile="0.5"} 1e+06
+rpc_duration {method="eth_call",result="success",quantile="0.75"} 1e+06
+rpc_duration {method="eth_call",result="success",quantile="0.95"} 1e+06
+rpc_duration {method="eth_call",result="success",quantile="0.99"} 1e+06
+rpc_duration {method="eth_call",result="success",quantile="0.999"} 1e+06
+rpc_duration {method="eth_call",result="success",quantile="0.9999"} 1e+06
+
+`
+	if !strings.HasPrefix(res.Body.String(), expectedOutput) {
+		t.Log("Expected Output:\n", expectedOutput)
+		t.Log("Actual Output:\n", res.Body.String())
+		t.Fatal("unexpected handler output")
+	}
+	if want := `rpc_duration_seconds_bucket{method="eth_call",result="success",le="0.001"} 1`; !strings.Contains(res.Body.String(), want) {
+		t.Fatalf("missing labelled histogram bucket %q", want)
+	}
+}
+
+func TestRegisterFamily(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		fail    bool
+	}{
+		{name: "test", pattern: "test/{label}"},
+		{name: "", pattern: "test/{label}", fail: true},
+		{name: "test", pattern: "{a}/{b}", fail: true},
+		{name: "test", pattern: "test/{le}", fail: true},
+		{name: "test", pattern: "test/{in-valid}", fail: true},
+	}
+	for _, tt := range tests {
+		if err := RegisterFamily(tt.name, tt.pattern); (err != nil) != tt.fail {
+			t.Errorf("family %q pattern %q: have error %v, want failure %v", tt.name, tt.pattern, err, tt.fail)
+		}
+	}
+	if family, labels := familyOf("test/value"); family != "test" || formatLabels(labels) != `{label="value"}` {
+		t.Errorf("family mismatch: have %s%s, want test{label=\"value\"}", family, formatLabels(labels))
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// defaultFamilies are the metric name patterns exported as labelled families
+// out of the box, keyed by family name. The per-message traffic meters are not
+// named after their prefix, which is taken by the total traffic meters.
+var defaultFamilies = []struct {
+	name    string
+	pattern string
+}{
+	{"p2p/ingress/bytes", "p2p/ingress/{protocol}/{version}/{code}"},
+	{"p2p/ingress/packets", "p2p/ingress/{protocol}/{version}/{code}/packets"},
+	{"p2p/egress/bytes", "p2p/egress/{protocol}/{version}/{code}"},
+	{"p2p/egress/packets", "p2p/egress/{protocol}/{version}/{code}/packets"},
+	{"p2p/handle", "p2p/handle/{protocol}/{version}/{code}"},
+	{"rpc/duration", "rpc/duration/{method}/{result}"},
+}
+
+var (
+	families     []*familyPattern
+	familiesLock sync.RWMutex
+
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+func init() {
+	for _, f := range defaultFamilies {
+		if err := RegisterFamily(f.name, f.pattern); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// label is a single name-value pair attached to a Prometheus metric.
+type label struct {
+	name  string
+	value string
+}
+
+// familyPattern matches metric names against a slash separated pattern.
+type familyPattern struct {
+	name     string   // Name of the metric family
+	segments []string // Pattern segments, label names if labels is set
+	labels   []bool   // Flags whether a segment is a label placeholder
+}
+
+// RegisterFamily registers a metric name pattern, exporting all the metrics
+// matching it as a single labelled Prometheus metric family of the given name.
+// Segments of the pattern enclosed in braces match any value and are exported
+// as labels named after the braces' content, while all other segments must
+// match literally. E.g. the "rpc/duration/{method}/{result}" pattern of the
+// "rpc/duration" family exports "rpc/duration/eth_call/success" as:
+//
+//	rpc_duration{method="eth_call",result="success"}
+//
+// The family name must not be taken by any other metric, as the two would be
+// merged under a single type. Metrics are matched against the patterns in their
+// order of registration.
+func RegisterFamily(name string, pattern string) error {
+	if name == "" {
+		return fmt.Errorf("empty family name for pattern %q", pattern)
+	}
+	f := &familyPattern{
+		name:     name,
+		segments: strings.Split(pattern, "/"),
+	}
+	f.labels = make([]bool, len(f.segments))
+
+	var literals int
+	for i, segment := range f.segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			literals++
+			continue
+		}
+		name := segment[1 : len(segment)-1]
+		if !labelNameRegexp.MatchString(name) || name == "le" || name == "quantile" {
+			return fmt.Errorf("invalid label name %q in pattern %q", name, pattern)
+		}
+		f.segments[i], f.labels[i] = name, true
+	}
+	if literals == 0 {
+		return fmt.Errorf("pattern %q has no literal segments", pattern)
+	}
+
+	familiesLock.Lock()
+	defer familiesLock.Unlock()
+
+	families = append(families, f)
+	return nil
+}
+
+// match checks whether a metric name matches the pattern, returning the name
+// of the family and the labels extracted from the metric name if so.
+func (f *familyPattern) match(name string) (string, []label, bool) {
+	segments := strings.Split(name, "/")
+	if len(segments) != len(f.segments) {
+		return "", nil, false
+	}
+	var labels []label
+	for i, segment := range segments {
+		switch {
+		case f.labels[i]:
+			labels = append(labels, label{name: f.segments[i], value: segment})
+		case segment != f.segments[i]:
+			return "", nil, false
+		}
+	}
+	return f.name, labels, true
+}
+
+// familyOf returns the name of the metric family a metric belongs to, along
+// with the labels identifying the metric within the family.
+func familyOf(name string) (string, []label) {
+	familiesLock.RLock()
+	defer familiesLock.RUnlock()
+
+	for _, f := range families {
+		if family, labels, ok := f.match(name); ok {
+			return family, labels
+		}
+	}
+	return name, nil
+}
+
+// labelEscaper escapes label values as required by the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// formatLabelPrefix formats a list of labels, to be followed by further ones.
+func formatLabelPrefix(labels []label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		fmt.Fprintf(&b, "%s=\"%s\",", l.name, labelEscaper.Replace(l.value))
+	}
+	return b.String()
+}
+
+// formatLabels formats a list of labels, or nothing if the list is empty.
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.TrimSuffix(formatLabelPrefix(labels), ",") + "}"
+}
@@ -61,8 +61,10 @@ func Handler(reg metrics.Registry) http.Handler {
 				log.Warn("Unknown Prometheus metric type", "type", fmt.Sprintf("%T", i))
 			}
 		}
+		report := c.bytes()
+
 		w.Header().Add("Content-Type", "text/plain")
-		w.Header().Add("Content-Length", fmt.Sprint(c.buff.Len()))
-		w.Write(c.buff.Bytes())
+		w.Header().Add("Content-Length", fmt.Sprint(len(report)))
+		w.Write(report)
 	})
 }
@@ -38,6 +38,17 @@ func GetOrRegisterTimer(name string, r Registry) Timer {
 	return r.GetOrRegister(name, NewTimer).(Timer)
 }
 
+// GetOrRegisterBucketedTimer returns an existing Timer or constructs and
+// registers a new StandardTimer counting its durations into the given buckets.
+// Be sure to unregister the meter from the registry once it is of no use to
+// allow for garbage collection.
+func GetOrRegisterBucketedTimer(name string, r Registry, bounds []float64) Timer {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return r.GetOrRegister(name, func() Timer { return NewBucketedTimer(bounds) }).(Timer)
+}
+
 // NewCustomTimer constructs a new StandardTimer from a Histogram and a Meter.
 // Be sure to call Stop() once the timer is of no use to allow for garbage collection.
 func NewCustomTimer(h Histogram, m Meter) Timer {
@@ -50,6 +61,19 @@ func NewCustomTimer(h Histogram, m Meter) Timer {
 	}
 }
 
+// NewRegisteredBucketedTimer constructs and registers a new StandardTimer
+// counting its durations into the given buckets.
+// Be sure to unregister the meter from the registry once it is of no use to
+// allow for garbage collection.
+func NewRegisteredBucketedTimer(name string, r Registry, bounds []float64) Timer {
+	c := NewBucketedTimer(bounds)
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
 // NewRegisteredTimer constructs and registers a new StandardTimer.
 // Be sure to unregister the meter from the registry once it is of no use to
 // allow for garbage collection.
@@ -75,6 +99,19 @@ func NewTimer() Timer {
 	}
 }
 
+// NewBucketedTimer constructs a new StandardTimer like NewTimer, which also
+// counts the durations, in nanoseconds, into buckets with the given upper bounds.
+// Be sure to call Stop() once the timer is of no use to allow for garbage collection.
+func NewBucketedTimer(bounds []float64) Timer {
+	if !Enabled {
+		return NilTimer{}
+	}
+	return &StandardTimer{
+		histogram: NewBucketedHistogram(NewExpDecaySample(1028, 0.015), bounds),
+		meter:     NewMeter(),
+	}
+}
+
 // NilTimer is a no-op Timer.
 type NilTimer struct{}
 
@@ -142,6 +179,15 @@ type StandardTimer struct {
 	mutex     sync.Mutex
 }
 
+// Buckets returns a read-only copy of the bucket counts of the underlying
+// histogram, or nil if it does not count its values into buckets.
+func (t *StandardTimer) Buckets() *BucketsSnapshot {
+	if h, ok := t.histogram.(Bucketed); ok {
+		return h.Buckets()
+	}
+	return nil
+}
+
 // Count returns the number of events recorded.
 func (t *StandardTimer) Count() int64 {
 	return t.histogram.Count()
@@ -252,6 +298,9 @@ type TimerSnapshot struct {
 	meter     *MeterSnapshot
 }
 
+// Buckets returns the bucket counts at the time the snapshot was taken.
+func (t *TimerSnapshot) Buckets() *BucketsSnapshot { return t.histogram.Buckets() }
+
 // Count returns the number of events recorded at the time the snapshot was
 // taken.
 func (t *TimerSnapshot) Count() int64 { return t.histogram.Count() }
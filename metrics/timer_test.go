@@ -112,3 +112,23 @@ func ExampleGetOrRegisterTimer() {
 	t.Update(47)
 	fmt.Println(t.Max()) // Output: 47
 }
+
+func TestTimerBuckets(t *testing.T) {
+	tm := NewBucketedTimer(DefaultTimerBuckets)
+	defer tm.Stop()
+	tm.Update(20 * time.Millisecond)
+	tm.Update(2 * time.Second)
+
+	b := tm.Snapshot().(Bucketed).Buckets()
+	if counts := b.Counts(); counts[8] != 1 || counts[15] != 2 {
+		t.Errorf("b.Counts(): [... 1 ... 2 ...] != %v\n", counts)
+	}
+	if sum := b.Sum(); sum != int64(2020*time.Millisecond) {
+		t.Errorf("b.Sum(): %v != %v\n", int64(2020*time.Millisecond), sum)
+	}
+	plain := NewTimer()
+	defer plain.Stop()
+	if b := plain.(Bucketed).Buckets(); b != nil {
+		t.Errorf("plain timer buckets: nil != %v\n", b)
+	}
+}
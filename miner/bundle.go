@@ -0,0 +1,145 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+)
+
+// maxPendingBundles is the maximum number of bundles waiting for their target
+// block to be mined.
+const maxPendingBundles = 1024
+
+var (
+	// ErrEmptyBundle is returned if a bundle without transactions is submitted.
+	ErrEmptyBundle = errors.New("empty bundle")
+
+	// ErrBundleTimestamps is returned if the minimum timestamp of a bundle is
+	// above its maximum timestamp.
+	ErrBundleTimestamps = errors.New("bundle min timestamp above max timestamp")
+
+	// ErrStaleBundle is returned if a bundle targets an already mined block.
+	ErrStaleBundle = errors.New("bundle targets a past block")
+
+	// ErrTooManyBundles is returned if the bundle pool is full.
+	ErrTooManyBundles = errors.New("too many pending bundles")
+
+	// errBundleTxReverted is returned if the execution of a bundle transaction
+	// reverted, failing the entire bundle.
+	errBundleTxReverted = errors.New("bundle transaction reverted")
+)
+
+// Bundle is an ordered group of transactions to be included atomically at the
+// top of a specific block: either all of them succeed, or none is included.
+type Bundle struct {
+	Txs          types.Transactions // Transactions to include, in order
+	BlockNumber  uint64             // Number of the block the bundle targets
+	MinTimestamp uint64             // Earliest acceptable block timestamp (0 = unbounded)
+	MaxTimestamp uint64             // Latest acceptable block timestamp (0 = unbounded)
+}
+
+// Hash returns the identifier of the bundle, the hash of its transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// validate checks the sanity of the bundle, irrespective of the chain state.
+func (b *Bundle) validate() error {
+	if len(b.Txs) == 0 {
+		return ErrEmptyBundle
+	}
+	if b.MaxTimestamp != 0 && b.MinTimestamp > b.MaxTimestamp {
+		return ErrBundleTimestamps
+	}
+	return nil
+}
+
+// eligible returns whether the bundle may be included in the given block.
+func (b *Bundle) eligible(header *types.Header) bool {
+	if header.Number.Uint64() != b.BlockNumber {
+		return false
+	}
+	if b.MinTimestamp != 0 && header.Time < b.MinTimestamp {
+		return false
+	}
+	if b.MaxTimestamp != 0 && header.Time > b.MaxTimestamp {
+		return false
+	}
+	return true
+}
+
+// bundlePool holds the bundles submitted to the miner until their target block
+// is mined.
+type bundlePool struct {
+	bundles []*Bundle
+	lock    sync.Mutex
+}
+
+// add inserts a new bundle into the pool, provided its target block is above
+// the given chain head.
+func (p *bundlePool) add(bundle *Bundle, head uint64) error {
+	if err := bundle.validate(); err != nil {
+		return err
+	}
+	if bundle.BlockNumber <= head {
+		return ErrStaleBundle
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.bundles) >= maxPendingBundles {
+		return ErrTooManyBundles
+	}
+	p.bundles = append(p.bundles, bundle)
+	return nil
+}
+
+// eligible drops all the bundles targeting blocks below the given one and
+// returns the ones which may be included into it, in submission order.
+func (p *bundlePool) eligible(header *types.Header) []*Bundle {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var (
+		number   = header.Number.Uint64()
+		eligible []*Bundle
+		pending  = p.bundles[:0]
+	)
+	for _, bundle := range p.bundles {
+		if bundle.BlockNumber < number {
+			continue
+		}
+		pending = append(pending, bundle)
+		if bundle.eligible(header) {
+			eligible = append(eligible, bundle)
+		}
+	}
+	for i := len(pending); i < len(p.bundles); i++ {
+		p.bundles[i] = nil
+	}
+	p.bundles = pending
+	return eligible
+}
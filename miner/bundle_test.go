@@ -0,0 +1,132 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/consensus/ubqhash"
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/params"
+)
+
+// newBundleTx creates a simple value transfer for bundle testing.
+func newBundleTx(key *ecdsa.PrivateKey, nonce uint64, value int64) *types.Transaction {
+	return types.MustSignNewTx(key, types.LatestSigner(ubqhashChainConfig), &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &testUserAddress,
+		Value:    big.NewInt(value),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(params.InitialBaseFee),
+	})
+}
+
+// Tests that invalid bundles are rejected on submission.
+func TestBundleValidation(t *testing.T) {
+	tx := newBundleTx(testBankKey, 0, 1)
+
+	tests := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{&Bundle{BlockNumber: 1}, ErrEmptyBundle},
+		{&Bundle{Txs: types.Transactions{tx}, BlockNumber: 1, MinTimestamp: 2, MaxTimestamp: 1}, ErrBundleTimestamps},
+		{&Bundle{Txs: types.Transactions{tx}, BlockNumber: 5}, ErrStaleBundle},
+		{&Bundle{Txs: types.Transactions{tx}, BlockNumber: 6, MinTimestamp: 2}, nil},
+	}
+	pool := new(bundlePool)
+	for i, tt := range tests {
+		if err := pool.add(tt.bundle, 5); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if len(pool.bundles) != 1 {
+		t.Fatalf("pooled bundle count mismatch: have %d, want 1", len(pool.bundles))
+	}
+	// Bundles must be dropped once their block is passed
+	if eligible := pool.eligible(&types.Header{Number: big.NewInt(6), Time: 1}); len(eligible) != 0 {
+		t.Errorf("bundle eligible before its min timestamp")
+	}
+	if eligible := pool.eligible(&types.Header{Number: big.NewInt(6), Time: 2}); len(eligible) != 1 {
+		t.Errorf("bundle not eligible for its target block")
+	}
+	if eligible := pool.eligible(&types.Header{Number: big.NewInt(7), Time: 3}); len(eligible) != 0 || len(pool.bundles) != 0 {
+		t.Errorf("stale bundle not dropped: eligible %d, pooled %d", len(eligible), len(pool.bundles))
+	}
+}
+
+// Tests that bundles are placed at the top of their target block if all their
+// transactions succeed, and dropped entirely otherwise.
+func TestBundleInclusion(t *testing.T) {
+	engine := ubqhash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ubqhashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	// Submit a failing bundle (unfunded second sender), followed by a valid one
+	// conflicting with the pooled transactions
+	unfunded, _ := crypto.GenerateKey()
+	failing := &Bundle{
+		Txs:         types.Transactions{newBundleTx(testBankKey, 0, 5000), newBundleTx(unfunded, 0, 1)},
+		BlockNumber: 1,
+	}
+	valid := &Bundle{
+		Txs:         types.Transactions{newBundleTx(testBankKey, 0, 1), newBundleTx(testBankKey, 1, 2)},
+		BlockNumber: 1,
+	}
+	for _, bundle := range []*Bundle{failing, valid} {
+		if err := w.bundles.add(bundle, b.chain.CurrentBlock().NumberU64()); err != nil {
+			t.Fatalf("failed to submit bundle: %v", err)
+		}
+	}
+	taskCh := make(chan *task, 1)
+	w.newTaskHook = func(task *task) {
+		if len(task.block.Transactions()) > 0 {
+			select {
+			case taskCh <- task:
+			default:
+			}
+		}
+	}
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	select {
+	case task := <-taskCh:
+		var have []common.Hash
+		for _, tx := range task.block.Transactions() {
+			have = append(have, tx.Hash())
+		}
+		want := []common.Hash{valid.Txs[0].Hash(), valid.Txs[1].Hash()}
+		if len(have) != len(want) || have[0] != want[0] || have[1] != want[1] {
+			t.Fatalf("block transactions mismatch: have %x, want %x", have, want)
+		}
+		if balance := task.state.GetBalance(testUserAddress); balance.Cmp(big.NewInt(3)) != 0 {
+			t.Fatalf("recipient balance mismatch: have %v, want 3", balance)
+		}
+	case <-time.NewTimer(3 * time.Second).C:
+		t.Fatalf("new task timeout")
+	}
+}
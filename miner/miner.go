@@ -52,6 +52,13 @@ type Config struct {
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
 	Recommit   time.Duration  // The time interval for miner to re-create mining work.
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
+
+	TxOrdering string `toml:",omitempty"` // Transaction ordering policy used to fill blocks (price, fifo or fair)
+
+	CliqueStandby uint64 `toml:",omitempty"` // Missed in-turn slots of the primary clique signer before sealing with the same key (0 = not a standby)
+
+	Stratum           string `toml:",omitempty"` // Listening address of the stratum mining server (only useful in ethash).
+	StratumDifficulty uint64 `toml:",omitempty"` // Share difficulty of stratum workers, block difficulty if zero
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -233,6 +240,13 @@ func (miner *Miner) DisablePreseal() {
 	miner.worker.disablePreseal()
 }
 
+// SubmitBundle schedules a bundle of transactions for atomic inclusion at the
+// top of its target block. Bundles are simulated while the block is assembled
+// and only included if all of their transactions succeed.
+func (miner *Miner) SubmitBundle(bundle *Bundle) error {
+	return miner.worker.bundles.add(bundle, miner.eth.BlockChain().CurrentBlock().NumberU64())
+}
+
 // SubscribePendingLogs starts delivering logs from pending transactions
 // to the given channel.
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
@@ -0,0 +1,245 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+)
+
+const (
+	// TxOrderingPriceAndNonce orders transactions by the miner tip they pay,
+	// highest first. This is the default policy.
+	TxOrderingPriceAndNonce = "price"
+
+	// TxOrderingFIFO orders transactions by the time they were first seen.
+	TxOrderingFIFO = "fifo"
+
+	// TxOrderingFair includes the transactions of all senders in a round-robin
+	// fashion, one transaction per sender per round, starting each round with
+	// the sender paying the highest tip.
+	TxOrderingFair = "fair"
+)
+
+// TxOrderingPolicy decides the order in which the pending transactions are
+// committed into a new block. Policies must honour the nonce order of every
+// sender's transactions.
+type TxOrderingPolicy interface {
+	// Order creates an iterator over the given set of per-sender, nonce sorted
+	// transactions. Transactions which cannot pay the base fee must be skipped
+	// along with the rest of the sender's transactions.
+	//
+	// Note, the input map is reowned so the caller should not interact any more
+	// with it after providing it to the policy.
+	Order(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TxIterator
+}
+
+// TxIterator iterates over an ordered set of transactions, while supporting the
+// removal of all remaining transactions of non-executable senders.
+type TxIterator interface {
+	// Peek returns the next transaction, or nil if the set is exhausted.
+	Peek() *types.Transaction
+
+	// Shift replaces the next transaction with the following one from the same
+	// sender.
+	Shift()
+
+	// Pop removes the next transaction, along with all following ones from the
+	// same sender.
+	Pop()
+}
+
+// NewTxOrderingPolicy returns the transaction ordering policy with the given
+// name, or the default price-and-nonce policy if the name is empty.
+func NewTxOrderingPolicy(name string) (TxOrderingPolicy, error) {
+	switch name {
+	case "", TxOrderingPriceAndNonce:
+		return priceAndNonceOrdering{}, nil
+	case TxOrderingFIFO:
+		return fifoOrdering{}, nil
+	case TxOrderingFair:
+		return fairOrdering{}, nil
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering policy %q", name)
+	}
+}
+
+// priceAndNonceOrdering orders transactions by their miner tip, highest first.
+type priceAndNonceOrdering struct{}
+
+func (priceAndNonceOrdering) Order(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TxIterator {
+	return types.NewTransactionsByPriceAndNonce(signer, txs, baseFee)
+}
+
+// payable returns whether the transaction can pay for the given base fee.
+func payable(tx *types.Transaction, baseFee *big.Int) bool {
+	_, err := tx.EffectiveGasTip(baseFee)
+	return err == nil
+}
+
+// heads validates the given transactions, returning the first payable one of
+// each sender, and leaving only the following ones in the map.
+func heads(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) []*types.Transaction {
+	heads := make([]*types.Transaction, 0, len(txs))
+	for from, accTxs := range txs {
+		// Remove the sender if it doesn't match, or if it cannot pay
+		if acc, _ := types.Sender(signer, accTxs[0]); acc != from || !payable(accTxs[0], baseFee) {
+			delete(txs, from)
+			continue
+		}
+		heads = append(heads, accTxs[0])
+		txs[from] = accTxs[1:]
+	}
+	return heads
+}
+
+// fifoOrdering orders transactions by the time they were first seen, earliest
+// first.
+type fifoOrdering struct{}
+
+func (fifoOrdering) Order(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TxIterator {
+	it := &fifoIterator{
+		txs:     txs,
+		heads:   txsByTime(heads(signer, txs, baseFee)),
+		signer:  signer,
+		baseFee: baseFee,
+	}
+	heap.Init(&it.heads)
+	return it
+}
+
+// txsByTime implements a heap of transactions sorted by arrival time, using
+// the hash to break ties deterministically.
+type txsByTime []*types.Transaction
+
+func (s txsByTime) Len() int { return len(s) }
+func (s txsByTime) Less(i, j int) bool {
+	if s[i].Time().Equal(s[j].Time()) {
+		return bytes.Compare(s[i].Hash().Bytes(), s[j].Hash().Bytes()) < 0
+	}
+	return s[i].Time().Before(s[j].Time())
+}
+func (s txsByTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *txsByTime) Push(x interface{}) {
+	*s = append(*s, x.(*types.Transaction))
+}
+
+func (s *txsByTime) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*s = old[0 : n-1]
+	return x
+}
+
+// fifoIterator returns transactions in arrival order, in a nonce-honouring way.
+type fifoIterator struct {
+	txs     map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	heads   txsByTime                             // Next transaction for each unique account (time heap)
+	signer  types.Signer                          // Signer for the set of transactions
+	baseFee *big.Int                              // Current base fee
+}
+
+// Peek returns the earliest seen transaction.
+func (it *fifoIterator) Peek() *types.Transaction {
+	if len(it.heads) == 0 {
+		return nil
+	}
+	return it.heads[0]
+}
+
+// Shift replaces the current earliest head with the next one from the same account.
+func (it *fifoIterator) Shift() {
+	acc, _ := types.Sender(it.signer, it.heads[0])
+	if txs, ok := it.txs[acc]; ok && len(txs) > 0 && payable(txs[0], it.baseFee) {
+		it.heads[0], it.txs[acc] = txs[0], txs[1:]
+		heap.Fix(&it.heads, 0)
+		return
+	}
+	heap.Pop(&it.heads)
+}
+
+// Pop removes the earliest transaction, *not* replacing it with the next one
+// from the same account.
+func (it *fifoIterator) Pop() {
+	heap.Pop(&it.heads)
+}
+
+// fairOrdering includes the transactions of all senders in a round-robin
+// fashion, preventing a single sender from crowding out all the others.
+type fairOrdering struct{}
+
+func (fairOrdering) Order(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TxIterator {
+	queue := heads(signer, txs, baseFee)
+
+	// Start the rounds with the best paying senders, fall back to the arrival
+	// time and hash for a deterministic order
+	sort.Slice(queue, func(i, j int) bool {
+		if cmp := queue[i].EffectiveGasTipCmp(queue[j], baseFee); cmp != 0 {
+			return cmp > 0
+		}
+		if !queue[i].Time().Equal(queue[j].Time()) {
+			return queue[i].Time().Before(queue[j].Time())
+		}
+		return bytes.Compare(queue[i].Hash().Bytes(), queue[j].Hash().Bytes()) < 0
+	})
+	return &fairIterator{
+		txs:     txs,
+		queue:   queue,
+		signer:  signer,
+		baseFee: baseFee,
+	}
+}
+
+// fairIterator returns transactions round-robin across senders.
+type fairIterator struct {
+	txs     map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	queue   []*types.Transaction                  // Next transaction for each unique account (round-robin order)
+	signer  types.Signer                          // Signer for the set of transactions
+	baseFee *big.Int                              // Current base fee
+}
+
+// Peek returns the transaction of the sender whose turn it is.
+func (it *fairIterator) Peek() *types.Transaction {
+	if len(it.queue) == 0 {
+		return nil
+	}
+	return it.queue[0]
+}
+
+// Shift moves the current sender to the end of the round, with its next transaction.
+func (it *fairIterator) Shift() {
+	acc, _ := types.Sender(it.signer, it.queue[0])
+	if txs, ok := it.txs[acc]; ok && len(txs) > 0 && payable(txs[0], it.baseFee) {
+		it.queue = append(it.queue, txs[0])
+		it.txs[acc] = txs[1:]
+	}
+	it.queue = it.queue[1:]
+}
+
+// Pop removes the current sender from the rounds.
+func (it *fairIterator) Pop() {
+	it.queue = it.queue[1:]
+}
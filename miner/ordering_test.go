@@ -0,0 +1,166 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/consensus"
+	"github.com/ubiq/go-ubiq/v7/consensus/ubqhash"
+	"github.com/ubiq/go-ubiq/v7/core"
+	"github.com/ubiq/go-ubiq/v7/core/rawdb"
+	"github.com/ubiq/go-ubiq/v7/core/types"
+	"github.com/ubiq/go-ubiq/v7/core/vm"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/event"
+	"github.com/ubiq/go-ubiq/v7/params"
+)
+
+// Tests that unknown ordering policies are rejected.
+func TestTxOrderingPolicyNames(t *testing.T) {
+	for _, name := range []string{"", TxOrderingPriceAndNonce, TxOrderingFIFO, TxOrderingFair} {
+		if _, err := NewTxOrderingPolicy(name); err != nil {
+			t.Errorf("policy %q: unexpected error: %v", name, err)
+		}
+	}
+	if _, err := NewTxOrderingPolicy("random"); err == nil {
+		t.Errorf("unknown policy accepted")
+	}
+}
+
+// Tests that the worker fills blocks in the order mandated by the configured
+// transaction ordering policy.
+func TestTxOrderingPriceAndNonce(t *testing.T) {
+	// The best paying sender first, then by price and nonce
+	testTxOrdering(t, TxOrderingPriceAndNonce, []string{"C0", "A0", "A1", "A2", "B0", "B1"})
+}
+
+func TestTxOrderingFIFO(t *testing.T) {
+	// Strictly in arrival order
+	testTxOrdering(t, TxOrderingFIFO, []string{"A0", "A1", "A2", "B0", "B1", "C0"})
+}
+
+func TestTxOrderingFair(t *testing.T) {
+	// Senders take turns, each round starting with the best paying one
+	testTxOrdering(t, TxOrderingFair, []string{"C0", "A0", "B0", "A1", "B1", "A2"})
+}
+
+func testTxOrdering(t *testing.T, ordering string, want []string) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = ubqhash.NewFaker()
+		signer = types.LatestSigner(ubqhashChainConfig)
+
+		keys  = make(map[string]*ecdsa.PrivateKey)
+		gspec = core.Genesis{
+			Config: ubqhashChainConfig,
+			Alloc:  core.GenesisAlloc{},
+		}
+	)
+	defer engine.Close()
+
+	for _, name := range []string{"A", "B", "C"} {
+		keys[name], _ = crypto.GenerateKey()
+		gspec.Alloc[crypto.PubkeyToAddress(keys[name].PublicKey)] = core.GenesisAccount{Balance: testBankFunds}
+	}
+	gspec.MustCommit(db)
+
+	chain, _ := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true}, gspec.Config, engine, vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	backend := &testWorkerBackend{
+		db:      db,
+		chain:   chain,
+		txPool:  core.NewTxPool(testTxPoolConfig, ubqhashChainConfig, chain),
+		genesis: &gspec,
+	}
+	defer backend.txPool.Stop()
+
+	// Create the transactions in a known arrival order, with prices conflicting
+	// with it and with each other's nonce order
+	var (
+		txs   []*types.Transaction
+		names = make(map[common.Hash]string)
+	)
+	for _, spec := range []struct {
+		sender string
+		price  int64
+		count  int
+	}{
+		{"A", 3, 3},
+		{"B", 2, 2},
+		{"C", 4, 1},
+	} {
+		for nonce := 0; nonce < spec.count; nonce++ {
+			tx := types.MustSignNewTx(keys[spec.sender], signer, &types.LegacyTx{
+				Nonce:    uint64(nonce),
+				To:       &testUserAddress,
+				Value:    big.NewInt(1000),
+				Gas:      params.TxGas,
+				GasPrice: big.NewInt(spec.price * params.InitialBaseFee),
+			})
+			txs = append(txs, tx)
+			names[tx.Hash()] = spec.sender + string(rune('0'+nonce))
+		}
+	}
+	for i, err := range backend.txPool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	// Mine a block with the requested policy and check its transactions
+	config := *testConfig
+	config.TxOrdering = ordering
+
+	w := newWorker(&config, ubqhashChainConfig, engine, backend, new(event.TypeMux), nil, false, consensus.NewMerger(rawdb.NewMemoryDatabase()))
+	defer w.close()
+	w.setEtherbase(testBankAddress)
+
+	taskCh := make(chan *task, 1)
+	w.newTaskHook = func(task *task) {
+		if len(task.block.Transactions()) > 0 {
+			select {
+			case taskCh <- task:
+			default:
+			}
+		}
+	}
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	select {
+	case task := <-taskCh:
+		var have []string
+		for _, tx := range task.block.Transactions() {
+			have = append(have, names[tx.Hash()])
+		}
+		if len(have) != len(want) {
+			t.Fatalf("transaction count mismatch: have %v, want %v", have, want)
+		}
+		for i := range want {
+			if have[i] != want[i] {
+				t.Fatalf("inclusion order mismatch: have %v, want %v", have, want)
+			}
+		}
+	case <-time.NewTimer(3 * time.Second).C:
+		t.Fatalf("new task timeout")
+	}
+}
@@ -18,6 +18,7 @@ package miner
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"sync/atomic"
@@ -128,6 +129,8 @@ type worker struct {
 	eth         Backend
 	chain       *core.BlockChain
 	merger      *consensus.Merger
+	ordering    TxOrderingPolicy
+	bundles     bundlePool
 
 	// Feeds
 	pendingLogsFeed event.Feed
@@ -215,6 +218,14 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		resubmitIntervalCh: make(chan time.Duration),
 		resubmitAdjustCh:   make(chan *intervalAdjust, resubmitAdjustChanSize),
 	}
+	// Select the transaction ordering policy, falling back to the default one
+	ordering, err := NewTxOrderingPolicy(config.TxOrdering)
+	if err != nil {
+		log.Warn("Sanitizing miner transaction ordering", "provided", config.TxOrdering, "updated", TxOrderingPriceAndNonce, "err", err)
+		ordering, _ = NewTxOrderingPolicy(TxOrderingPriceAndNonce)
+	}
+	worker.ordering = ordering
+
 	// Subscribe NewTxsEvent for tx pool
 	worker.txsSub = eth.TxPool().SubscribeNewTxsEvent(worker.txsCh)
 	// Subscribe events for blockchain
@@ -523,7 +534,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee)
+				txset := w.ordering.Order(w.current.signer, txs, w.current.header.BaseFee)
 				tcount := w.current.tcount
 				w.commitTransactions(txset, coinbase, nil)
 				// Only update the snapshot if any new transactons were added
@@ -783,7 +794,93 @@ func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Addres
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
+// simulateBundle executes all the transactions of a bundle on top of a copy of
+// the current block's state, returning an error if any of them fails or reverts.
+func (w *worker) simulateBundle(bundle *Bundle, coinbase common.Address) error {
+	var (
+		state   = w.current.state.Copy()
+		gasPool = new(core.GasPool).AddGas(w.current.gasPool.Gas())
+		gasUsed = w.current.header.GasUsed
+	)
+	for i, tx := range bundle.Txs {
+		state.Prepare(tx.Hash(), w.current.tcount+i)
+
+		receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, gasPool, state, w.current.header, tx, &gasUsed, *w.chain.GetVMConfig())
+		if err == nil && receipt.Status == types.ReceiptStatusFailed {
+			err = errBundleTxReverted
+		}
+		if err != nil {
+			return fmt.Errorf("transaction %d (%x): %w", i, tx.Hash(), err)
+		}
+	}
+	return nil
+}
+
+// commitBundle simulates a bundle against the current block and, if all of its
+// transactions succeed, applies them on top of it. The bundle is applied either
+// entirely or not at all.
+func (w *worker) commitBundle(bundle *Bundle, coinbase common.Address) ([]*types.Log, error) {
+	if err := w.simulateBundle(bundle, coinbase); err != nil {
+		return nil, err
+	}
+	var (
+		snap     = w.current.state.Snapshot()
+		txs      = len(w.current.txs)
+		receipts = len(w.current.receipts)
+		tcount   = w.current.tcount
+		gas      = w.current.gasPool.Gas()
+		gasUsed  = w.current.header.GasUsed
+		logs     []*types.Log
+	)
+	for i, tx := range bundle.Txs {
+		w.current.state.Prepare(tx.Hash(), w.current.tcount)
+		txLogs, err := w.commitTransaction(tx, coinbase)
+		if err != nil {
+			// Should never happen, as the bundle was just simulated on the same
+			// state, but don't leave a partial bundle in the block if it does
+			w.current.state.RevertToSnapshot(snap)
+			w.current.txs = w.current.txs[:txs]
+			w.current.receipts = w.current.receipts[:receipts]
+			w.current.tcount = tcount
+			w.current.gasPool = new(core.GasPool).AddGas(gas)
+			w.current.header.GasUsed = gasUsed
+			return nil, fmt.Errorf("transaction %d (%x): %w", i, tx.Hash(), err)
+		}
+		logs = append(logs, txLogs...)
+		w.current.tcount++
+	}
+	return logs, nil
+}
+
+// commitBundles applies all the bundles targeting the current block, skipping
+// the ones which fail. The number of included bundles is returned.
+func (w *worker) commitBundles(coinbase common.Address) int {
+	// Short circuit if current is nil
+	if w.current == nil {
+		return 0
+	}
+	if w.current.gasPool == nil {
+		w.current.gasPool = new(core.GasPool).AddGas(w.current.header.GasLimit)
+	}
+	var (
+		included      int
+		coalescedLogs []*types.Log
+	)
+	for _, bundle := range w.bundles.eligible(w.current.header) {
+		logs, err := w.commitBundle(bundle, coinbase)
+		if err != nil {
+			log.Debug("Bundle failed, skipped", "hash", bundle.Hash(), "err", err)
+			continue
+		}
+		log.Debug("Committed bundle to block", "hash", bundle.Hash(), "txs", len(bundle.Txs))
+		coalescedLogs = append(coalescedLogs, logs...)
+		included++
+	}
+	w.postPendingLogs(coalescedLogs)
+	return included
+}
+
+func (w *worker) commitTransactions(txs TxIterator, coinbase common.Address, interrupt *int32) bool {
 	// Short circuit if current is nil
 	if w.current == nil {
 		return true
@@ -879,7 +976,20 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		}
 	}
 
-	if !w.isRunning() && len(coalescedLogs) > 0 {
+	w.postPendingLogs(coalescedLogs)
+
+	// Notify resubmit loop to decrease resubmitting interval if current interval is larger
+	// than the user-specified one.
+	if interrupt != nil {
+		w.resubmitAdjustCh <- &intervalAdjust{inc: false}
+	}
+	return false
+}
+
+// postPendingLogs announces the logs of freshly committed transactions to the
+// pending logs subscribers.
+func (w *worker) postPendingLogs(logs []*types.Log) {
+	if !w.isRunning() && len(logs) > 0 {
 		// We don't push the pendingLogsEvent while we are mining. The reason is that
 		// when we are mining, the worker will regenerate a mining block every 3 seconds.
 		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
@@ -887,19 +997,13 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
 		// logs by filling in the block hash when the block was mined by the local miner. This can
 		// cause a race condition if a log was "upgraded" before the PendingLogsEvent is processed.
-		cpy := make([]*types.Log, len(coalescedLogs))
-		for i, l := range coalescedLogs {
+		cpy := make([]*types.Log, len(logs))
+		for i, l := range logs {
 			cpy[i] = new(types.Log)
 			*cpy[i] = *l
 		}
 		w.pendingLogsFeed.Send(cpy)
 	}
-	// Notify resubmit loop to decrease resubmitting interval if current interval is larger
-	// than the user-specified one.
-	if interrupt != nil {
-		w.resubmitAdjustCh <- &intervalAdjust{inc: false}
-	}
-	return false
 }
 
 // commitNewWork generates several new sealing tasks based on the parent block.
@@ -980,12 +1084,15 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		w.commit(uncles, nil, false, tstart)
 	}
 
+	// Place the bundles targeting this block at its top
+	bundles := w.commitBundles(w.coinbase)
+
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
-	// Short circuit if there is no available pending transactions.
+	// Short circuit if there is no available pending transactions or bundles.
 	// But if we disable empty precommit already, ignore it. Since
 	// empty block is necessary to keep the liveness of the network.
-	if len(pending) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
+	if len(pending) == 0 && bundles == 0 && atomic.LoadUint32(&w.noempty) == 0 {
 		w.updateSnapshot()
 		return
 	}
@@ -998,13 +1105,13 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs, header.BaseFee)
+		txs := w.ordering.Order(w.current.signer, localTxs, header.BaseFee)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs, header.BaseFee)
+		txs := w.ordering.Order(w.current.signer, remoteTxs, header.BaseFee)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
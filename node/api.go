@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ubiq/go-ubiq/v7/common/hexutil"
 	"github.com/ubiq/go-ubiq/v7/crypto"
@@ -125,6 +126,81 @@ func (api *privateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// parseNodeID parses a node identifier given either as an enode URL, an ENR or
+// a hex encoded node ID.
+func parseNodeID(url string) (enode.ID, error) {
+	if id, err := enode.ParseID(url); err == nil {
+		return id, nil
+	}
+	node, err := enode.Parse(enode.ValidSchemes, url)
+	if err != nil {
+		return enode.ID{}, fmt.Errorf("invalid enode: %v", err)
+	}
+	return node.ID(), nil
+}
+
+// BanPeer bans a remote node for the given number of seconds, or permanently if
+// zero, disconnecting it if connected. Bans are persisted across restarts.
+func (api *privateAdminAPI) BanPeer(url string, duration uint64) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	id, err := parseNodeID(url)
+	if err != nil {
+		return false, err
+	}
+	if err := server.BanPeer(id, time.Duration(duration)*time.Second, "banned by admin"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnbanPeer lifts the ban of a remote node, returning whether it was banned.
+func (api *privateAdminAPI) UnbanPeer(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	id, err := parseNodeID(url)
+	if err != nil {
+		return false, err
+	}
+	return server.UnbanPeer(id)
+}
+
+// BanInfo represents a short summary of an active node ban.
+type BanInfo struct {
+	ID     string     `json:"id"`              // Unique identifier of the banned node
+	Until  *time.Time `json:"until,omitempty"` // Expiration of the ban, omitted if permanent
+	Reason string     `json:"reason"`          // Reason the node was banned for
+}
+
+// ListBans retrieves all the currently active node bans.
+func (api *privateAdminAPI) ListBans() ([]*BanInfo, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	bans, err := server.Bans()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*BanInfo, 0, len(bans))
+	for _, ban := range bans {
+		info := &BanInfo{ID: ban.ID.String(), Reason: ban.Reason}
+		if !ban.Until.IsZero() {
+			until := ban.Until
+			info.Until = &until
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *privateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -185,6 +261,7 @@ func (api *privateAdminAPI) StartHTTP(host *string, port *int, cors *string, api
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		rpcEndpointConfig:  api.node.config.rpcEndpointConfig(),
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -256,8 +333,9 @@ func (api *privateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 
 	// Determine config.
 	config := wsConfig{
-		Modules: api.node.config.WSModules,
-		Origins: api.node.config.WSOrigins,
+		Modules:           api.node.config.WSModules,
+		Origins:           api.node.config.WSOrigins,
+		rpcEndpointConfig: api.node.config.rpcEndpointConfig(),
 		// ExposeAll: api.node.config.WSExposeAll,
 	}
 	if apis != nil {
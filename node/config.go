@@ -138,6 +138,35 @@ type Config struct {
 	// HTTPPathPrefix specifies a path prefix on which http-rpc is to be served.
 	HTTPPathPrefix string `toml:",omitempty"`
 
+	// AuthAddr is the listening address on which authenticated APIs are provided.
+	AuthAddr string `toml:",omitempty"`
+
+	// AuthPort is the port number on which authenticated APIs are provided.
+	AuthPort int `toml:",omitempty"`
+
+	// AuthVirtualHosts is the list of virtual hostnames which are allowed on incoming requests
+	// for the authenticated api. This is by default {'localhost'}.
+	AuthVirtualHosts []string `toml:",omitempty"`
+
+	// AuthModules is a list of API modules to expose via the authenticated RPC
+	// interface, served over both HTTP and WebSocket.
+	AuthModules []string `toml:",omitempty"`
+
+	// BatchRequestLimit is the maximum number of calls executed per JSON-RPC batch
+	// on the HTTP, WebSocket and IPC endpoints. Excess calls are answered with an
+	// error. Zero means no limit.
+	BatchRequestLimit int `toml:",omitempty"`
+
+	// BatchResponseMaxSize is the maximum number of bytes of results returned for
+	// a single JSON-RPC batch. Calls past the limit are answered with an error.
+	// Zero means no limit.
+	BatchResponseMaxSize int `toml:",omitempty"`
+
+	// JWTSecret is the path to the hex-encoded jwt secret. If set, an additional
+	// HTTP and WebSocket endpoint is started, requiring every request to carry a
+	// fresh HS256 token signed with the secret.
+	JWTSecret string `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string
@@ -190,6 +219,19 @@ type Config struct {
 
 	// AllowUnprotectedTxs allows non EIP-155 protected transactions to be send over RPC.
 	AllowUnprotectedTxs bool `toml:",omitempty"`
+
+	// DBEngine is the key-value database engine backing the node's databases,
+	// either "leveldb" or "pebble". If empty, the engine of an existing database
+	// is used, falling back to leveldb for new ones.
+	DBEngine string `toml:",omitempty"`
+}
+
+// rpcEndpointConfig returns the settings shared by all RPC endpoints.
+func (c *Config) rpcEndpointConfig() rpcEndpointConfig {
+	return rpcEndpointConfig{
+		batchItemLimit:     c.BatchRequestLimit,
+		batchResponseLimit: c.BatchResponseMaxSize,
+	}
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
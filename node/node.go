@@ -17,8 +17,11 @@
 package node
 
 import (
+	crand "crypto/rand"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -28,6 +31,8 @@ import (
 
 	"github.com/prometheus/tsdb/fileutil"
 	"github.com/ubiq/go-ubiq/v7/accounts"
+	"github.com/ubiq/go-ubiq/v7/common"
+	"github.com/ubiq/go-ubiq/v7/common/hexutil"
 	"github.com/ubiq/go-ubiq/v7/core/rawdb"
 	"github.com/ubiq/go-ubiq/v7/ethdb"
 	"github.com/ubiq/go-ubiq/v7/event"
@@ -55,6 +60,7 @@ type Node struct {
 	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
 	http          *httpServer //
 	ws            *httpServer //
+	httpAuth      *httpServer // Serves the authenticated APIs over both HTTP and WebSocket
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
@@ -148,7 +154,8 @@ func New(conf *Config) (*Node, error) {
 	// Configure RPC servers.
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
-	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
+	node.ipc = newIPCServer(node.log, conf.IPCEndpoint(), conf.rpcEndpointConfig())
 
 	return node, nil
 }
@@ -357,6 +364,7 @@ func (n *Node) startRPC() error {
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
+			rpcEndpointConfig:  n.config.rpcEndpointConfig(),
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
 			return err
@@ -370,9 +378,10 @@ func (n *Node) startRPC() error {
 	if n.config.WSHost != "" {
 		server := n.wsServerForPort(n.config.WSPort)
 		config := wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:           n.config.WSModules,
+			Origins:           n.config.WSOrigins,
+			prefix:            n.config.WSPathPrefix,
+			rpcEndpointConfig: n.config.rpcEndpointConfig(),
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
@@ -382,12 +391,70 @@ func (n *Node) startRPC() error {
 		}
 	}
 
+	// Configure the authenticated HTTP and WebSocket endpoint.
+	if n.config.JWTSecret != "" {
+		secret, err := obtainJWTSecret(n.config.JWTSecret)
+		if err != nil {
+			return err
+		}
+		if err := n.httpAuth.setListenAddr(n.config.AuthAddr, n.config.AuthPort); err != nil {
+			return err
+		}
+		authConfig := n.config.rpcEndpointConfig()
+		authConfig.jwtSecret = secret
+
+		if err := n.httpAuth.enableRPC(n.rpcAPIs, httpConfig{
+			Vhosts:            n.config.AuthVirtualHosts,
+			Modules:           n.config.AuthModules,
+			rpcEndpointConfig: authConfig,
+		}); err != nil {
+			return err
+		}
+		if err := n.httpAuth.enableWS(n.rpcAPIs, wsConfig{
+			Modules:           n.config.AuthModules,
+			Origins:           []string{"localhost"},
+			rpcEndpointConfig: authConfig,
+		}); err != nil {
+			return err
+		}
+		if err := n.httpAuth.start(); err != nil {
+			return err
+		}
+	}
 	if err := n.http.start(); err != nil {
 		return err
 	}
 	return n.ws.start()
 }
 
+// obtainJWTSecret loads the hex-encoded jwt secret from the given file. If the
+// file does not exist yet, a new random secret is generated and stored there.
+func obtainJWTSecret(fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err == nil {
+		jwtSecret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(jwtSecret) == 32 {
+			log.Info("Loaded JWT secret file", "path", fileName, "crc32", fmt.Sprintf("%#x", crc32.ChecksumIEEE(jwtSecret)))
+			return jwtSecret, nil
+		}
+		log.Error("Invalid JWT secret", "path", fileName, "length", len(jwtSecret))
+		return nil, errors.New("invalid JWT secret")
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	// Need to generate one
+	jwtSecret := make([]byte, 32)
+	if _, err := crand.Read(jwtSecret); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(fileName, []byte(hexutil.Encode(jwtSecret)), 0600); err != nil {
+		return nil, err
+	}
+	log.Info("Generated JWT secret", "path", fileName)
+	return jwtSecret, nil
+}
+
 func (n *Node) wsServerForPort(port int) *httpServer {
 	if n.config.HTTPHost == "" || n.http.port == port {
 		return n.http
@@ -398,6 +465,7 @@ func (n *Node) wsServerForPort(port int) *httpServer {
 func (n *Node) stopRPC() {
 	n.http.stop()
 	n.ws.stop()
+	n.httpAuth.stop()
 	n.ipc.stop()
 	n.stopInProc()
 }
@@ -566,7 +634,14 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabase(n.ResolvePath(name), cache, handles, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:      n.config.DBEngine,
+			Directory: n.ResolvePath(name),
+			Namespace: namespace,
+			Cache:     cache,
+			Handles:   handles,
+			ReadOnly:  readonly,
+		})
 	}
 
 	if err == nil {
@@ -599,7 +674,15 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 		case !filepath.IsAbs(freezer):
 			freezer = n.ResolvePath(freezer)
 		}
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace, readonly)
+		db, err = rawdb.Open(rawdb.OpenOptions{
+			Type:              n.config.DBEngine,
+			Directory:         root,
+			AncientsDirectory: freezer,
+			Namespace:         namespace,
+			Cache:             cache,
+			Handles:           handles,
+			ReadOnly:          readonly,
+		})
 	}
 
 	if err == nil {
@@ -24,7 +24,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/ubiq/go-ubiq/v7/internal/testlog"
@@ -243,7 +245,7 @@ func createAndStartServer(t *testing.T, conf *httpConfig, ws bool, wsConf *wsCon
 }
 
 // wsRequest attempts to open a WebSocket connection to the given URL.
-func wsRequest(t *testing.T, url, browserOrigin string) error {
+func wsRequest(t *testing.T, url, browserOrigin string, extraHeaders ...string) error {
 	t.Helper()
 	t.Logf("checking WebSocket on %s (origin %q)", url, browserOrigin)
 
@@ -251,6 +253,12 @@ func wsRequest(t *testing.T, url, browserOrigin string) error {
 	if browserOrigin != "" {
 		headers.Set("Origin", browserOrigin)
 	}
+	if len(extraHeaders)%2 != 0 {
+		panic("odd extraHeaders length")
+	}
+	for i := 0; i < len(extraHeaders); i += 2 {
+		headers.Set(extraHeaders[i], extraHeaders[i+1])
+	}
 	conn, _, err := websocket.DefaultDialer.Dial(url, headers)
 	if conn != nil {
 		conn.Close()
@@ -291,3 +299,69 @@ func rpcRequest(t *testing.T, url string, extraHeaders ...string) *http.Response
 	}
 	return resp
 }
+
+// TestJWT tests that the authenticated HTTP and WebSocket handlers only accept
+// fresh HS256 tokens signed with the configured secret.
+func TestJWT(t *testing.T) {
+	var secret = []byte("secret")
+	issueToken := func(secret []byte, method jwt.SigningMethod, input map[string]interface{}) string {
+		if method == nil {
+			method = jwt.SigningMethodHS256
+		}
+		ss, _ := jwt.NewWithClaims(method, jwt.MapClaims(input)).SignedString(secret)
+		return ss
+	}
+	srv := createAndStartServer(t, &httpConfig{rpcEndpointConfig: rpcEndpointConfig{jwtSecret: secret}}, true, &wsConfig{Origins: []string{"*"}, rpcEndpointConfig: rpcEndpointConfig{jwtSecret: secret}})
+	defer srv.stop()
+
+	wsURL := "ws://" + srv.listenAddr()
+	htURL := "http://" + srv.listenAddr()
+
+	expOk := []string{
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix()})),
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix() + 4})),
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix() - 4})),
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{
+			"iat": time.Now().Unix(),
+			"exp": time.Now().Unix() + 2,
+		})),
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{
+			"iat": time.Now().Unix(),
+			"bar": "baz",
+		})),
+	}
+	for i, token := range expOk {
+		if err := wsRequest(t, wsURL, "", "Authorization", token); err != nil {
+			t.Errorf("test %d-ws, token '%v': expected ok, got %v", i, token, err)
+		}
+		if resp := rpcRequest(t, htURL, "Authorization", token); resp.StatusCode != 200 {
+			t.Errorf("test %d-http, token '%v': expected ok, got %v", i, token, resp.StatusCode)
+		}
+	}
+	expFail := []string{
+		// future
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix() + int64(jwtExpiryTimeout.Seconds()) + 1})),
+		// stale
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix() - int64(jwtExpiryTimeout.Seconds()) - 1})),
+		// wrong algo
+		fmt.Sprintf("Bearer %v", issueToken(secret, jwt.SigningMethodHS512, map[string]interface{}{"iat": time.Now().Unix() + 4})),
+		// expired
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix(), "exp": time.Now().Unix()})),
+		// missing mandatory iat
+		fmt.Sprintf("Bearer %v", issueToken(secret, nil, map[string]interface{}{})),
+		// wrong secret
+		fmt.Sprintf("Bearer %v", issueToken([]byte("wrong"), nil, map[string]interface{}{"iat": time.Now().Unix()})),
+		// missing bearer prefix
+		issueToken(secret, nil, map[string]interface{}{"iat": time.Now().Unix()}),
+		// missing token
+		"",
+	}
+	for i, token := range expFail {
+		if err := wsRequest(t, wsURL, "", "Authorization", token); err == nil {
+			t.Errorf("tc %d-ws, token '%v': expected not to allow, got ok", i, token)
+		}
+		if resp := rpcRequest(t, htURL, "Authorization", token); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("tc %d-http, token '%v': expected not to allow, got %v", i, token, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
+)
+
+// TrafficStats contains the amount of sub-protocol messages exchanged with a
+// peer and their total payload size.
+type TrafficStats struct {
+	IngressBytes    uint64 `json:"ingressBytes"`
+	IngressMessages uint64 `json:"ingressMessages"`
+	EgressBytes     uint64 `json:"egressBytes"`
+	EgressMessages  uint64 `json:"egressMessages"`
+}
+
+// trafficCounter accumulates the traffic of a single sub-protocol of a peer.
+type trafficCounter struct {
+	// These fields are accessed atomically, keep them 64 bit aligned.
+	ingressBytes    uint64
+	ingressMessages uint64
+	egressBytes     uint64
+	egressMessages  uint64
+}
+
+// ingress records a message read from the peer.
+func (c *trafficCounter) ingress(size uint32) {
+	atomic.AddUint64(&c.ingressBytes, uint64(size))
+	atomic.AddUint64(&c.ingressMessages, 1)
+}
+
+// egress records a message written to the peer.
+func (c *trafficCounter) egress(size uint32) {
+	atomic.AddUint64(&c.egressBytes, uint64(size))
+	atomic.AddUint64(&c.egressMessages, 1)
+}
+
+// stats returns a snapshot of the traffic counters.
+func (c *trafficCounter) stats() *TrafficStats {
+	return &TrafficStats{
+		IngressBytes:    atomic.LoadUint64(&c.ingressBytes),
+		IngressMessages: atomic.LoadUint64(&c.ingressMessages),
+		EgressBytes:     atomic.LoadUint64(&c.egressBytes),
+		EgressMessages:  atomic.LoadUint64(&c.egressMessages),
+	}
+}
+
+// tokenBucket is a bandwidth limiter refilled with a byte allowance at a steady
+// rate, permitting bursts of up to a second worth of traffic.
+type tokenBucket struct {
+	clock   mclock.Clock
+	rate    float64        // Number of tokens (bytes) refilled per second
+	tokens  float64        // Number of tokens available, negative if overdrawn
+	updated mclock.AbsTime // Time of the last refill
+	lock    sync.Mutex
+}
+
+func newTokenBucket(clock mclock.Clock, rate int) *tokenBucket {
+	return &tokenBucket{
+		clock:   clock,
+		rate:    float64(rate),
+		tokens:  float64(rate),
+		updated: clock.Now(),
+	}
+}
+
+// take withdraws a number of tokens from the bucket, returning how long the
+// caller needs to wait for the bucket to recover before it may proceed. Large
+// transfers may overdraw the bucket, delaying subsequent ones instead.
+func (b *tokenBucket) take(n uint32) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	b.tokens += b.rate * float64(now-b.updated) / float64(time.Second)
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.updated = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// rateLimits is a set of token buckets a transfer has to pass all of, e.g. the
+// bandwidth cap of an individual peer and the global one.
+type rateLimits []*tokenBucket
+
+// wait withdraws the size of a transfer from all the buckets and blocks until
+// all of them recovered. False is returned if the closed channel fires before.
+func (l rateLimits) wait(n uint32, closed <-chan struct{}) bool {
+	var (
+		wait  time.Duration
+		clock mclock.Clock
+	)
+	for _, bucket := range l {
+		if delay := bucket.take(n); delay > wait {
+			wait, clock = delay, bucket.clock
+		}
+	}
+	if wait == 0 {
+		return true
+	}
+	select {
+	case <-clock.After(wait):
+		return true
+	case <-closed:
+		return false
+	}
+}
+
+// newRateLimits assembles the bandwidth caps of a new peer from the configured
+// per-peer rate and the shared global bucket, either of them being optional.
+func newRateLimits(clock mclock.Clock, rate int, global *tokenBucket) rateLimits {
+	var limits rateLimits
+	if rate > 0 {
+		limits = append(limits, newTokenBucket(clock, rate))
+	}
+	if global != nil {
+		limits = append(limits, global)
+	}
+	return limits
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
+)
+
+// Tests that token buckets permit bursts up to their rate, delaying transfers
+// beyond it until they refill.
+func TestTokenBucket(t *testing.T) {
+	var (
+		clock  = new(mclock.Simulated)
+		bucket = newTokenBucket(clock, 1000)
+	)
+	if wait := bucket.take(1000); wait != 0 {
+		t.Fatalf("burst delayed: %v", wait)
+	}
+	if wait := bucket.take(500); wait != 500*time.Millisecond {
+		t.Fatalf("overdraft delay mismatch: have %v, want %v", wait, 500*time.Millisecond)
+	}
+	// Idle time refills the bucket, but no further than a second worth of tokens
+	clock.Run(10 * time.Second)
+	if wait := bucket.take(1000); wait != 0 {
+		t.Fatalf("refilled burst delayed: %v", wait)
+	}
+	if wait := bucket.take(1); wait == 0 {
+		t.Fatalf("bucket refilled beyond its capacity")
+	}
+}
+
+// Tests that transfers wait for the most constrained of multiple limits, and
+// that the wait is aborted on shutdown.
+func TestRateLimitsWait(t *testing.T) {
+	var (
+		clock  = new(mclock.Simulated)
+		global = newTokenBucket(clock, 100)
+		limits = newRateLimits(clock, 1000, global)
+		closed = make(chan struct{})
+		done   = make(chan bool)
+	)
+	if !limits.wait(100, closed) {
+		t.Fatalf("burst aborted")
+	}
+	go func() { done <- limits.wait(100, closed) }()
+
+	clock.WaitForTimers(1)
+	clock.Run(500 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatalf("transfer passed before the global limit recovered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	clock.Run(500 * time.Millisecond)
+	if !<-done {
+		t.Fatalf("transfer aborted")
+	}
+	// Overdraw the buckets and ensure shutdown interrupts waiting
+	go func() { done <- limits.wait(1000, closed) }()
+	clock.WaitForTimers(1)
+	close(closed)
+	if <-done {
+		t.Fatalf("transfer not aborted on shutdown")
+	}
+}
@@ -0,0 +1,547 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
+	"github.com/ubiq/go-ubiq/v7/crypto"
+	"github.com/ubiq/go-ubiq/v7/p2p/discover/v5wire"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+	"github.com/ubiq/go-ubiq/v7/rlp"
+)
+
+const (
+	topicAdLifetime       = 15 * time.Minute // lifetime of a topic ad
+	maxAdsPerTopic        = 100              // ad limit of a single topic queue
+	maxTopicAds           = 5000             // ad limit across all topic queues
+	ticketValidity        = 10 * time.Second // time window for using a ticket once its wait time has passed
+	maxTicketWait         = 5 * time.Minute  // tickets with longer wait times are not used
+	topicRegNodes         = 8                // number of nodes an ad is placed on
+	topicRegInterval      = 10 * time.Minute // ad renewal interval, must be below topicAdLifetime
+	topicRetryInterval    = 30 * time.Second // ad renewal interval if no ad could be placed
+	topicSearchInterval   = 30 * time.Second // delay between topic search lookups
+	topicQueryResultLimit = totalNodesResponseLimit * nodesResponseItemLimit
+)
+
+var (
+	errInvalidTicket = errors.New("invalid ticket")
+	errTicketNode    = errors.New("ticket issued to different node")
+	errTicketEarly   = errors.New("ticket used before wait time")
+	errTicketExpired = errors.New("ticket expired")
+)
+
+// Topic identifies a service advertised in the DHT. Ads for a topic are placed
+// on the nodes closest to the topic.
+type Topic [32]byte
+
+// NewTopic creates the topic for the given service name.
+func NewTopic(name string) Topic {
+	return Topic(crypto.Keccak256Hash([]byte(name)))
+}
+
+func (topic Topic) String() string {
+	return fmt.Sprintf("%x", topic[:])
+}
+
+// topicFromBytes converts the topic of a message, which must have the right size.
+func topicFromBytes(b []byte) (topic Topic, ok bool) {
+	if len(b) != len(topic) {
+		return topic, false
+	}
+	copy(topic[:], b)
+	return topic, true
+}
+
+// topicAd is a node advertised under a topic.
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicTable stores the ads placed on the local node. Ads of every topic are kept in
+// a queue ordered by expiry. Registrants are asked to wait until there is space for
+// their ad in both the topic queue and the table.
+//
+// The table is only accessed by the UDPv5 dispatch loop and isn't safe for concurrent
+// use.
+type topicTable struct {
+	clock  mclock.Clock
+	queues map[Topic][]*topicAd
+	count  int
+}
+
+func newTopicTable(clock mclock.Clock) *topicTable {
+	return &topicTable{clock: clock, queues: make(map[Topic][]*topicAd)}
+}
+
+// expire removes all expired ads.
+func (tt *topicTable) expire() {
+	now := tt.clock.Now()
+	for topic, queue := range tt.queues {
+		i := 0
+		for i < len(queue) && queue[i].expires <= now {
+			i++
+		}
+		if i == 0 {
+			continue
+		}
+		tt.count -= i
+		if i == len(queue) {
+			delete(tt.queues, topic)
+		} else {
+			tt.queues[topic] = queue[i:]
+		}
+	}
+}
+
+// find returns the position of a node in a topic queue, or -1 if not present.
+func (tt *topicTable) find(queue []*topicAd, id enode.ID) int {
+	for i, ad := range queue {
+		if ad.node.ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// waitTime returns how long the given node has to wait before its ad fits into the
+// table. Nodes renewing their existing ad don't need to wait.
+func (tt *topicTable) waitTime(topic Topic, id enode.ID) time.Duration {
+	tt.expire()
+
+	queue := tt.queues[topic]
+	if tt.find(queue, id) >= 0 {
+		return 0
+	}
+	now := tt.clock.Now()
+	if len(queue) >= maxAdsPerTopic {
+		return queue[0].expires.Sub(now)
+	}
+	if tt.count >= maxTopicAds {
+		var next time.Duration
+		for _, queue := range tt.queues {
+			if wait := queue[0].expires.Sub(now); next == 0 || wait < next {
+				next = wait
+			}
+		}
+		return next
+	}
+	return 0
+}
+
+// add places an ad for the given node, renewing its previous one if it exists. It
+// reports whether the ad was placed.
+func (tt *topicTable) add(topic Topic, n *enode.Node) bool {
+	tt.expire()
+
+	queue := tt.queues[topic]
+	if i := tt.find(queue, n.ID()); i >= 0 {
+		queue = append(queue[:i:i], queue[i+1:]...)
+		tt.count--
+	} else if len(queue) >= maxAdsPerTopic || tt.count >= maxTopicAds {
+		return false
+	}
+	tt.queues[topic] = append(queue, &topicAd{node: n, expires: tt.clock.Now().Add(topicAdLifetime)})
+	tt.count++
+	return true
+}
+
+// nodes returns up to limit nodes advertised under the topic, newest ads first.
+func (tt *topicTable) nodes(topic Topic, limit int) []*enode.Node {
+	tt.expire()
+
+	queue := tt.queues[topic]
+	nodes := make([]*enode.Node, 0, min(limit, len(queue)))
+	for i := len(queue) - 1; i >= 0 && len(nodes) < limit; i-- {
+		nodes = append(nodes, queue[i].node)
+	}
+	return nodes
+}
+
+// ticket is the content of a TICKET. It is opaque to the registrant and only
+// interpreted by the issuing node, which authenticates it with a local secret.
+type ticket struct {
+	Topic  Topic
+	ID     enode.ID // node the ticket was issued to
+	Issued uint64   // local clock time of issuance
+	Wait   uint64   // wait time in nanoseconds
+}
+
+// encodeTicket encodes and authenticates a ticket.
+func (t *UDPv5) encodeTicket(tk *ticket) []byte {
+	blob, _ := rlp.EncodeToBytes(tk)
+	mac := hmac.New(sha256.New, t.ticketKey)
+	mac.Write(blob)
+	return mac.Sum(blob)
+}
+
+// decodeTicket authenticates and decodes a ticket issued by the local node.
+func (t *UDPv5) decodeTicket(blob []byte) (*ticket, error) {
+	if len(blob) < sha256.Size {
+		return nil, errInvalidTicket
+	}
+	data, sum := blob[:len(blob)-sha256.Size], blob[len(blob)-sha256.Size:]
+	mac := hmac.New(sha256.New, t.ticketKey)
+	mac.Write(data)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return nil, errInvalidTicket
+	}
+	tk := new(ticket)
+	if err := rlp.DecodeBytes(data, tk); err != nil {
+		return nil, errInvalidTicket
+	}
+	return tk, nil
+}
+
+// checkTicket verifies that a ticket may be used by the given node right now, and
+// returns the topic it was issued for.
+func (t *UDPv5) checkTicket(blob []byte, fromID enode.ID) (Topic, error) {
+	tk, err := t.decodeTicket(blob)
+	if err != nil {
+		return Topic{}, err
+	}
+	if tk.ID != fromID {
+		return Topic{}, errTicketNode
+	}
+	var (
+		now   = t.clock.Now()
+		start = mclock.AbsTime(tk.Issued).Add(time.Duration(tk.Wait))
+	)
+	switch {
+	case now < start:
+		return Topic{}, errTicketEarly
+	case now > start.Add(ticketValidity):
+		return Topic{}, errTicketExpired
+	}
+	return tk.Topic, nil
+}
+
+// RegisterTopic starts advertising the local node under the given topic. Ads are
+// placed on the nodes closest to the topic and renewed until the topic is
+// unregistered or the transport is closed.
+func (t *UDPv5) RegisterTopic(topic Topic) {
+	t.toplock.Lock()
+	defer t.toplock.Unlock()
+
+	if _, ok := t.topicRegs[topic]; ok || t.closeCtx.Err() != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	t.topicRegs[topic] = cancel
+	t.wg.Add(1)
+	go t.topicRegLoop(ctx, topic)
+}
+
+// UnregisterTopic stops advertising the local node under the given topic. Ads
+// placed already remain valid until they expire.
+func (t *UDPv5) UnregisterTopic(topic Topic) {
+	t.toplock.Lock()
+	defer t.toplock.Unlock()
+
+	if cancel, ok := t.topicRegs[topic]; ok {
+		cancel()
+		delete(t.topicRegs, topic)
+	}
+}
+
+// topicRegLoop runs in its own goroutine and renews the ads of a topic.
+func (t *UDPv5) topicRegLoop(ctx context.Context, topic Topic) {
+	defer t.wg.Done()
+
+	for {
+		interval := topicRegInterval
+		if placed := t.registerTopic(ctx, topic); placed == 0 {
+			interval = topicRetryInterval
+		}
+		select {
+		case <-t.clock.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// registerTopic places ads for the topic on the nodes closest to it, returning the
+// number of ads placed.
+func (t *UDPv5) registerTopic(ctx context.Context, topic Topic) int {
+	nodes := t.newLookup(ctx, enode.ID(topic)).run()
+	if len(nodes) > topicRegNodes {
+		nodes = nodes[:topicRegNodes]
+	}
+	var (
+		wg     sync.WaitGroup
+		placed = make(chan bool, len(nodes))
+	)
+	for _, n := range nodes {
+		wg.Add(1)
+		go func(n *enode.Node) {
+			defer wg.Done()
+			placed <- t.placeAd(ctx, n, topic)
+		}(n)
+	}
+	wg.Wait()
+	close(placed)
+
+	count := 0
+	for ok := range placed {
+		if ok {
+			count++
+		}
+	}
+	t.log.Debug("Registered topic", "topic", topic, "nodes", len(nodes), "placed", count)
+	return count
+}
+
+// placeAd acquires a ticket for the topic from the given node, waits until it can
+// be used and registers the local node.
+func (t *UDPv5) placeAd(ctx context.Context, n *enode.Node, topic Topic) bool {
+	tk, err := t.requestTicket(n, topic)
+	if err != nil {
+		t.log.Trace("Topic ticket request failed", "id", n.ID(), "topic", topic, "err", err)
+		return false
+	}
+	wait := time.Duration(tk.WaitTime) * time.Second
+	if wait > maxTicketWait {
+		t.log.Trace("Topic ticket wait time too long", "id", n.ID(), "topic", topic, "wait", wait)
+		return false
+	}
+	if wait > 0 {
+		select {
+		case <-t.clock.After(wait):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	registered, err := t.regtopic(n, tk.Ticket)
+	if err != nil {
+		t.log.Trace("Topic registration failed", "id", n.ID(), "topic", topic, "err", err)
+		return false
+	}
+	return registered
+}
+
+// requestTicket calls REQUESTTICKET on a node and waits for a TICKET response.
+func (t *UDPv5) requestTicket(n *enode.Node, topic Topic) (*v5wire.Ticket, error) {
+	resp := t.call(n, v5wire.TicketMsg, &v5wire.RequestTicket{Topic: topic[:]})
+	defer t.callDone(resp)
+
+	select {
+	case tk := <-resp.ch:
+		return tk.(*v5wire.Ticket), nil
+	case err := <-resp.err:
+		return nil, err
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for a REGCONFIRMATION response.
+func (t *UDPv5) regtopic(n *enode.Node, ticket []byte) (bool, error) {
+	req := &v5wire.Regtopic{Ticket: ticket, ENR: t.Self().Record()}
+	resp := t.call(n, v5wire.RegconfirmationMsg, req)
+	defer t.callDone(resp)
+
+	select {
+	case conf := <-resp.ch:
+		return conf.(*v5wire.Regconfirmation).Registered, nil
+	case err := <-resp.err:
+		return false, err
+	}
+}
+
+// topicQuery calls TOPICQUERY on a node and waits for responses.
+func (t *UDPv5) topicQuery(n *enode.Node, topic Topic) ([]*enode.Node, error) {
+	resp := t.call(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic[:]})
+	return t.waitForNodes(resp, nil)
+}
+
+// handleRequestTicket issues a ticket for the requested topic.
+func (t *UDPv5) handleRequestTicket(p *v5wire.RequestTicket, fromID enode.ID, fromAddr *net.UDPAddr) {
+	topic, ok := topicFromBytes(p.Topic)
+	if !ok {
+		t.log.Debug("Invalid topic in "+p.Name(), "id", fromID, "addr", fromAddr)
+		return
+	}
+	wait := t.topics.waitTime(topic, fromID)
+	tk := &ticket{Topic: topic, ID: fromID, Issued: uint64(t.clock.Now()), Wait: uint64(wait)}
+	t.sendResponse(fromID, fromAddr, &v5wire.Ticket{
+		ReqID:    p.ReqID,
+		Ticket:   t.encodeTicket(tk),
+		WaitTime: uint64((wait + time.Second - 1) / time.Second),
+	})
+}
+
+// handleRegtopic places an ad for the sender if its ticket is valid.
+func (t *UDPv5) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) {
+	resp := &v5wire.Regconfirmation{ReqID: p.ReqID}
+	topic, err := t.checkTicket(p.Ticket, fromID)
+	if err == nil {
+		var n *enode.Node
+		if n, err = t.checkAdRecord(p, fromID, fromAddr); err == nil {
+			resp.Registered = t.topics.add(topic, n)
+		}
+	}
+	if err != nil {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+	}
+	t.sendResponse(fromID, fromAddr, resp)
+}
+
+// checkAdRecord verifies that the record of a REGTOPIC belongs to its sender.
+func (t *UDPv5) checkAdRecord(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) (*enode.Node, error) {
+	if p.ENR == nil {
+		return nil, errors.New("missing record")
+	}
+	n, err := enode.New(t.validSchemes, p.ENR)
+	if err != nil {
+		return nil, err
+	}
+	if n.ID() != fromID {
+		return nil, errors.New("record of different node")
+	}
+	if !n.IP().Equal(fromAddr.IP) {
+		return nil, errors.New("record IP mismatch")
+	}
+	return n, nil
+}
+
+// handleTopicQuery returns the nodes advertised under the topic to the requester.
+func (t *UDPv5) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr *net.UDPAddr) {
+	var nodes []*enode.Node
+	if topic, ok := topicFromBytes(p.Topic); ok {
+		nodes = t.topics.nodes(topic, topicQueryResultLimit)
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		t.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// TopicSearch returns an iterator over the nodes advertised under the given topic.
+// The search repeatedly looks up the nodes closest to the topic and asks them for
+// their ads.
+func (t *UDPv5) TopicSearch(topic Topic) enode.Iterator {
+	if t.tab.len() == 0 {
+		// All nodes were dropped, refresh. The very first query will hit this
+		// case and run the bootstrapping logic.
+		<-t.tab.refresh()
+	}
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicIterator{t: t, topic: topic, ctx: ctx, cancel: cancel}
+}
+
+// topicIterator performs topic lookups and iterates over the advertised nodes found
+// during them. Every node is returned at most once per lookup.
+type topicIterator struct {
+	t      *UDPv5
+	topic  Topic
+	ctx    context.Context
+	cancel func()
+	lookup *lookup
+	rounds int
+	buffer []*enode.Node
+
+	mu    sync.Mutex
+	found []*enode.Node
+	seen  map[enode.ID]struct{}
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	// Consume next node in buffer.
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	// Advance the lookup to refill the buffer.
+	for len(it.buffer) == 0 {
+		if it.ctx.Err() != nil {
+			it.lookup = nil
+			it.buffer = nil
+			return false
+		}
+		if it.buffer = it.takeFound(); len(it.buffer) > 0 {
+			break
+		}
+		if it.lookup == nil {
+			if it.rounds > 0 {
+				select {
+				case <-it.t.clock.After(topicSearchInterval):
+				case <-it.ctx.Done():
+					continue
+				}
+			}
+			it.rounds++
+			it.mu.Lock()
+			it.seen = make(map[enode.ID]struct{})
+			it.mu.Unlock()
+			it.lookup = newLookup(it.ctx, it.t.tab, enode.ID(it.topic), it.query)
+			continue
+		}
+		if !it.lookup.advance() {
+			it.lookup = nil
+		}
+	}
+	return true
+}
+
+// query is the query function of topic lookups. It collects the ads of the node and
+// returns its neighbors closer to the topic.
+func (it *topicIterator) query(n *node) ([]*node, error) {
+	ads, err := it.t.topicQuery(unwrapNode(n), it.topic)
+	if err == errClosed {
+		return nil, err
+	}
+	it.mu.Lock()
+	for _, ad := range ads {
+		if _, ok := it.seen[ad.ID()]; !ok && ad.ID() != it.t.Self().ID() {
+			it.seen[ad.ID()] = struct{}{}
+			it.found = append(it.found, ad)
+		}
+	}
+	it.mu.Unlock()
+
+	return it.t.lookupWorker(n, enode.ID(it.topic))
+}
+
+// takeFound returns the nodes found by queries since the last call.
+func (it *topicIterator) takeFound() []*enode.Node {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	found := it.found
+	it.found = nil
+	return found
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.cancel()
+}
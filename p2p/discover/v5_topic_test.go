@@ -0,0 +1,233 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
+	"github.com/ubiq/go-ubiq/v7/p2p/discover/v5wire"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+)
+
+// This test checks that the topic table limits its queues and expires ads.
+func TestTopicTable(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tt    = newTopicTable(clock)
+		topic = NewTopic("test")
+		nodes = nodesAtDistance(enode.ID{}, 256, maxAdsPerTopic+1)
+	)
+	for i, n := range nodes[:maxAdsPerTopic] {
+		if wait := tt.waitTime(topic, n.ID()); wait != 0 {
+			t.Fatalf("ad %d: wait time %v in non-full queue", i, wait)
+		}
+		if !tt.add(topic, n) {
+			t.Fatalf("ad %d: not placed in non-full queue", i)
+		}
+		clock.Run(time.Second)
+	}
+	// The queue is full, new nodes need to wait for the oldest ad to expire.
+	extra := nodes[maxAdsPerTopic]
+	if want := topicAdLifetime - maxAdsPerTopic*time.Second; tt.waitTime(topic, extra.ID()) != want {
+		t.Fatalf("wrong wait time %v in full queue, want %v", tt.waitTime(topic, extra.ID()), want)
+	}
+	if tt.add(topic, extra) {
+		t.Fatal("ad placed in full queue")
+	}
+	// Renewing an existing ad is always possible.
+	if wait := tt.waitTime(topic, nodes[0].ID()); wait != 0 {
+		t.Fatalf("wait time %v for renewal", wait)
+	}
+	if !tt.add(topic, nodes[0]) {
+		t.Fatal("renewal rejected")
+	}
+	if found := tt.nodes(topic, 2); !reflect.DeepEqual(found, []*enode.Node{nodes[0], nodes[maxAdsPerTopic-1]}) {
+		t.Fatalf("wrong nodes %v, want newest ads first", found)
+	}
+	// Once the oldest ads expire, new nodes can register again.
+	clock.Run(topicAdLifetime - maxAdsPerTopic*time.Second + time.Second)
+	if wait := tt.waitTime(topic, extra.ID()); wait != 0 {
+		t.Fatalf("wait time %v after expiry", wait)
+	}
+	if !tt.add(topic, extra) {
+		t.Fatal("ad not placed after expiry")
+	}
+	clock.Run(topicAdLifetime)
+	if found := tt.nodes(topic, maxAdsPerTopic); len(found) != 0 || tt.count != 0 {
+		t.Fatalf("%d ads (count %d) left after expiry", len(found), tt.count)
+	}
+}
+
+// This test checks that incoming topic registrations and queries are handled correctly.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		tk     []byte
+	)
+	// Request a ticket, which can be used right away.
+	test.packetIn(&v5wire.RequestTicket{ReqID: []byte{0}, Topic: topic[:]})
+	test.waitPacketOut(func(p *v5wire.Ticket, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte{0}) {
+			t.Fatalf("wrong request ID %v in TICKET", p.ReqID)
+		}
+		if p.WaitTime != 0 {
+			t.Fatalf("wrong wait time %d in TICKET", p.WaitTime)
+		}
+		tk = p.Ticket
+	})
+	// Tickets can't be forged or used by other nodes.
+	forged := append([]byte{}, tk...)
+	forged[0]++
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{1}, Ticket: forged, ENR: remote.Record()})
+	test.expectRegconfirmation([]byte{1}, false)
+
+	otherKey, otherAddr := newkey(), &net.UDPAddr{IP: net.IP{10, 0, 1, 100}, Port: 30303}
+	other := test.getNode(otherKey, otherAddr).Node()
+	test.packetInFrom(otherKey, otherAddr, &v5wire.Regtopic{ReqID: []byte{2}, Ticket: tk, ENR: other.Record()})
+	test.expectRegconfirmation([]byte{2}, false)
+	// The record must belong to the registrant.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{3}, Ticket: tk, ENR: other.Record()})
+	test.expectRegconfirmation([]byte{3}, false)
+
+	// Register with the ticket and check the node is returned for the topic.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte{4}, Ticket: tk, ENR: remote.Record()})
+	test.expectRegconfirmation([]byte{4}, true)
+
+	test.packetIn(&v5wire.TopicQuery{ReqID: []byte{5}, Topic: topic[:]})
+	test.expectNodes([]byte{5}, 1, []*enode.Node{remote})
+
+	// Other topics have no ads.
+	unknown := NewTopic("unknown")
+	test.packetIn(&v5wire.TopicQuery{ReqID: []byte{6}, Topic: unknown[:]})
+	test.expectNodes([]byte{6}, 1, nil)
+}
+
+func (test *udpV5Test) expectRegconfirmation(wantReqID []byte, wantRegistered bool) {
+	test.t.Helper()
+
+	test.waitPacketOut(func(p *v5wire.Regconfirmation, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, wantReqID) {
+			test.t.Fatalf("wrong request ID %v in REGCONFIRMATION, want %v", p.ReqID, wantReqID)
+		}
+		if p.Registered != wantRegistered {
+			test.t.Fatalf("wrong registration result %t, want %t", p.Registered, wantRegistered)
+		}
+	})
+}
+
+// This test checks that outgoing topic registrations work.
+func TestUDPv5_topicRegistration(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		done   = make(chan bool, 1)
+	)
+	go func() {
+		done <- test.udp.placeAd(context.Background(), remote, topic)
+	}()
+	test.waitPacketOut(func(p *v5wire.RequestTicket, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.Topic, topic[:]) {
+			t.Fatalf("wrong topic %x in REQUESTTICKET", p.Topic)
+		}
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: []byte("ticket")})
+	})
+	test.waitPacketOut(func(p *v5wire.Regtopic, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.Ticket, []byte("ticket")) {
+			t.Fatalf("wrong ticket %q in REGTOPIC", p.Ticket)
+		}
+		if !reflect.DeepEqual(p.ENR, test.udp.Self().Record()) {
+			t.Fatal("wrong record in REGTOPIC")
+		}
+		test.packetIn(&v5wire.Regconfirmation{ReqID: p.ReqID, Registered: true})
+	})
+	if !<-done {
+		t.Fatal("ad not placed")
+	}
+
+	// Tickets with excessive wait times are dropped.
+	go func() {
+		done <- test.udp.placeAd(context.Background(), remote, topic)
+	}()
+	test.waitPacketOut(func(p *v5wire.RequestTicket, addr *net.UDPAddr, _ v5wire.Nonce) {
+		wait := uint64((maxTicketWait + time.Second) / time.Second)
+		test.packetIn(&v5wire.Ticket{ReqID: p.ReqID, Ticket: []byte("ticket"), WaitTime: wait})
+	})
+	if <-done {
+		t.Fatal("ad placed despite long wait time")
+	}
+}
+
+// This test checks that topic search returns the advertised nodes.
+func TestUDPv5_topicSearch(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		topic  = NewTopic("test")
+		remote = test.getNode(test.remotekey, test.remoteaddr).Node()
+		ads    = nodesAtDistance(enode.ID(topic), 240, 4)
+	)
+	fillTable(test.table, []*node{wrapNode(remote)})
+
+	it := test.udp.TopicSearch(topic)
+	defer it.Close()
+
+	found := make(chan *enode.Node, len(ads))
+	go func() {
+		for i := 0; i < len(ads) && it.Next(); i++ {
+			found <- it.Node()
+		}
+		close(found)
+	}()
+	test.waitPacketOut(func(p *v5wire.TopicQuery, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.Topic, topic[:]) {
+			t.Fatalf("wrong topic %x in TOPICQUERY", p.Topic)
+		}
+		for _, resp := range packNodes(p.ReqID, ads) {
+			test.packetIn(resp)
+		}
+	})
+	test.waitPacketOut(func(p *v5wire.Findnode, addr *net.UDPAddr, _ v5wire.Nonce) {
+		test.packetIn(&v5wire.Nodes{ReqID: p.ReqID, Total: 1})
+	})
+	var results []*enode.Node
+	for n := range found {
+		results = append(results, n)
+	}
+	if len(results) != len(ads) {
+		t.Fatalf("wrong number of topic search results %d, want %d", len(results), len(ads))
+	}
+	if err := checkNodesEqual(results, ads); err != nil {
+		t.Fatalf("wrong topic search results: %v", err)
+	}
+}
@@ -40,6 +40,7 @@ const (
 	dbVersionKey   = "version" // Version of the database to flush if changes
 	dbNodePrefix   = "n:"      // Identifier to prefix node entries with
 	dbLocalPrefix  = "local:"
+	dbBanPrefix    = "ban:" // Identifier to prefix node bans with, kept apart from expiring node entries
 	dbDiscoverRoot = "v4"
 	dbDiscv5Root   = "v5"
 
@@ -187,6 +188,11 @@ func v5Key(id ID, ip net.IP, field string) []byte {
 	}, []byte{':'})
 }
 
+// banKey returns the database key for the ban of a node.
+func banKey(id ID) []byte {
+	return append([]byte(dbBanPrefix), id[:]...)
+}
+
 // localItemKey returns the key of a local node item.
 func localItemKey(id ID, field string) []byte {
 	key := append([]byte(dbLocalPrefix), id[:]...)
@@ -315,6 +321,7 @@ func (db *DB) expirer() {
 		select {
 		case <-tick.C:
 			db.expireNodes()
+			db.expireBans()
 		case <-db.quit:
 			return
 		}
@@ -427,6 +434,104 @@ func (db *DB) UpdateFindFailsV5(id ID, ip net.IP, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// Ban is a persistent ban of a remote node, refusing any connection to or from it.
+type Ban struct {
+	ID     ID
+	Until  time.Time // Time the ban expires at, zero if permanent
+	Reason string
+}
+
+// Expired reports whether the ban has already lapsed at the given time.
+func (b *Ban) Expired(now time.Time) bool {
+	return !b.Until.IsZero() && !now.Before(b.Until)
+}
+
+// banEntry is the database encoding of a ban.
+type banEntry struct {
+	Until  uint64 // Unix timestamp of the expiration, zero if permanent
+	Reason string
+}
+
+// decodeBan decodes a ban entry stored under the given node ID.
+func decodeBan(id []byte, blob []byte) (*Ban, error) {
+	var entry banEntry
+	if err := rlp.DecodeBytes(blob, &entry); err != nil {
+		return nil, err
+	}
+	ban := &Ban{Reason: entry.Reason}
+	copy(ban.ID[:], id)
+	if entry.Until != 0 {
+		ban.Until = time.Unix(int64(entry.Until), 0)
+	}
+	return ban, nil
+}
+
+// Ban retrieves the active ban of a node, or nil if the node is not banned.
+func (db *DB) Ban(id ID) *Ban {
+	blob, err := db.lvl.Get(banKey(id), nil)
+	if err != nil {
+		return nil
+	}
+	ban, err := decodeBan(id[:], blob)
+	if err != nil || ban.Expired(time.Now()) {
+		return nil
+	}
+	return ban
+}
+
+// UpdateBan inserts - potentially overwriting - the ban of a node.
+func (db *DB) UpdateBan(ban *Ban) error {
+	entry := banEntry{Reason: ban.Reason}
+	if !ban.Until.IsZero() {
+		entry.Until = uint64(ban.Until.Unix())
+	}
+	blob, err := rlp.EncodeToBytes(&entry)
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(banKey(ban.ID), blob, nil)
+}
+
+// DeleteBan lifts the ban of a node, reporting whether there was one.
+func (db *DB) DeleteBan(id ID) bool {
+	banned := db.Ban(id) != nil
+	db.lvl.Delete(banKey(id), nil)
+	return banned
+}
+
+// Bans retrieves all the active node bans.
+func (db *DB) Bans() []*Ban {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbBanPrefix)), nil)
+	defer it.Release()
+
+	var (
+		now  = time.Now()
+		bans []*Ban
+	)
+	for it.Next() {
+		ban, err := decodeBan(it.Key()[len(dbBanPrefix):], it.Value())
+		if err != nil || ban.Expired(now) {
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	return bans
+}
+
+// expireBans deletes all the node bans which have already lapsed.
+func (db *DB) expireBans() {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbBanPrefix)), nil)
+	defer it.Release()
+
+	now := time.Now()
+	for it.Next() {
+		ban, err := decodeBan(it.Key()[len(dbBanPrefix):], it.Value())
+		if err != nil || ban.Expired(now) {
+			db.lvl.Delete(it.Key(), nil)
+		}
+	}
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
@@ -473,3 +473,44 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+func TestDBBans(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		permanent = &Ban{ID: ID{0x01}, Reason: "permanent"}
+		temporary = &Ban{ID: ID{0x02}, Until: time.Now().Add(time.Hour).Truncate(time.Second), Reason: "temporary"}
+		expired   = &Ban{ID: ID{0x03}, Until: time.Now().Add(-time.Hour), Reason: "expired"}
+	)
+	for _, ban := range []*Ban{permanent, temporary, expired} {
+		if err := db.UpdateBan(ban); err != nil {
+			t.Fatalf("failed to insert ban %x: %v", ban.ID[:1], err)
+		}
+	}
+	if ban := db.Ban(permanent.ID); !reflect.DeepEqual(ban, permanent) {
+		t.Errorf("permanent ban mismatch: have %+v, want %+v", ban, permanent)
+	}
+	if ban := db.Ban(temporary.ID); ban == nil || !ban.Until.Equal(temporary.Until) || ban.Reason != temporary.Reason {
+		t.Errorf("temporary ban mismatch: have %+v, want %+v", ban, temporary)
+	}
+	if ban := db.Ban(expired.ID); ban != nil {
+		t.Errorf("expired ban still active: %+v", ban)
+	}
+	if bans := db.Bans(); len(bans) != 2 {
+		t.Errorf("active ban count mismatch: have %d, want 2", len(bans))
+	}
+	// Lift a ban and expire the lapsed ones
+	if !db.DeleteBan(permanent.ID) {
+		t.Errorf("permanent ban not reported as lifted")
+	}
+	if db.DeleteBan(expired.ID) {
+		t.Errorf("expired ban reported as lifted")
+	}
+	db.expireBans()
+
+	bans := db.Bans()
+	if len(bans) != 1 || bans[0].ID != temporary.ID {
+		t.Errorf("remaining bans mismatch: have %+v, want only %x", bans, temporary.ID[:1])
+	}
+}
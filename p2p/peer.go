@@ -115,6 +115,15 @@ type Peer struct {
 	pingRecv chan struct{}
 	disc     chan DiscReason
 
+	// penalize reports misbehaviour to the server if set, returning whether
+	// the peer got banned
+	penalize func(enode.ID, Penalty, string) bool
+
+	// bandwidth caps waited on before handing read messages to sub-protocols
+	// and before writing messages
+	ingressLimits rateLimits
+	egressLimits  rateLimits
+
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
@@ -214,6 +223,18 @@ func (p *Peer) Disconnect(reason DiscReason) {
 	}
 }
 
+// Penalize lowers the reputation of the peer due to some misbehaviour. If the
+// peer misbehaves too often, it gets banned for a while and disconnected. The
+// reputation of trusted peers is not tracked.
+func (p *Peer) Penalize(penalty Penalty, reason string) {
+	if p.penalize == nil || p.rw.is(trustedConn) {
+		return
+	}
+	if p.penalize(p.ID(), penalty, reason) {
+		p.Disconnect(DiscUselessPeer)
+	}
+}
+
 // String implements fmt.Stringer.
 func (p *Peer) String() string {
 	id := p.ID()
@@ -362,6 +383,7 @@ func (p *Peer) handle(msg Msg) error {
 			metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
 			metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
 		}
+		proto.traffic.ingress(msg.Size)
 		select {
 		case proto.in <- msg:
 			return nil
@@ -399,7 +421,7 @@ outer:
 					offset -= old.Length
 				}
 				// Assign the new match
-				result[cap.Name] = &protoRW{Protocol: proto, offset: offset, in: make(chan Msg), w: rw}
+				result[cap.Name] = &protoRW{Protocol: proto, offset: offset, in: make(chan Msg), w: rw, traffic: new(trafficCounter)}
 				offset += proto.Length
 
 				continue outer
@@ -416,6 +438,8 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 		proto.closed = p.closed
 		proto.wstart = writeStart
 		proto.werr = writeErr
+		proto.ingress = p.ingressLimits
+		proto.egress = p.egressLimits
 		var rw MsgReadWriter = proto
 		if p.events != nil {
 			rw = newMsgEventer(rw, p.events, p.ID(), proto.Name, p.Info().Network.RemoteAddress, p.Info().Network.LocalAddress)
@@ -454,6 +478,10 @@ type protoRW struct {
 	werr   chan<- error    // for write results
 	offset uint64
 	w      MsgWriter
+
+	traffic *trafficCounter // traffic exchanged through the protocol
+	ingress rateLimits      // ingress bandwidth caps to wait on before reads
+	egress  rateLimits      // egress bandwidth caps to wait on before writes
 }
 
 func (rw *protoRW) WriteMsg(msg Msg) (err error) {
@@ -465,9 +493,16 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 
 	msg.Code += rw.offset
 
+	if !rw.egress.wait(msg.Size, rw.closed) {
+		return ErrShuttingDown
+	}
 	select {
 	case <-rw.wstart:
+		size := msg.Size
 		err = rw.w.WriteMsg(msg)
+		if err == nil {
+			rw.traffic.egress(size)
+		}
 		// Report write status back to Peer.run. It will initiate
 		// shutdown if the error is non-nil and unblock the next write
 		// otherwise. The calling protocol code should exit for errors
@@ -482,6 +517,12 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 func (rw *protoRW) ReadMsg() (Msg, error) {
 	select {
 	case msg := <-rw.in:
+		// Throttle the sub-protocol instead of the peer's read loop, so base
+		// protocol messages (e.g. pings) are still handled while waiting
+		if msg.Code >= baseProtocolLength && !rw.ingress.wait(msg.Size, rw.closed) {
+			msg.Discard()
+			return Msg{}, io.EOF
+		}
 		msg.Code -= rw.offset
 		return msg, nil
 	case <-rw.closed:
@@ -505,7 +546,8 @@ type PeerInfo struct {
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
 	} `json:"network"`
-	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
+	Protocols map[string]interface{}   `json:"protocols"` // Sub-protocol specific metadata fields
+	Traffic   map[string]*TrafficStats `json:"traffic"`   // Sub-protocol message counters
 }
 
 // Info gathers and returns a collection of metadata known about a peer.
@@ -522,6 +564,7 @@ func (p *Peer) Info() *PeerInfo {
 		Name:      p.Fullname(),
 		Caps:      caps,
 		Protocols: make(map[string]interface{}),
+		Traffic:   make(map[string]*TrafficStats),
 	}
 	if p.Node().Seq() > 0 {
 		info.ENR = p.Node().String()
@@ -543,6 +586,7 @@ func (p *Peer) Info() *PeerInfo {
 			}
 		}
 		info.Protocols[proto.Name] = protoInfo
+		info.Traffic[proto.Name] = proto.traffic.stats()
 	}
 	return info
 }
@@ -28,6 +28,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
 	"github.com/ubiq/go-ubiq/v7/log"
 	"github.com/ubiq/go-ubiq/v7/p2p/enode"
 	"github.com/ubiq/go-ubiq/v7/p2p/enr"
@@ -168,6 +169,35 @@ func TestPeerProtoEncodeMsg(t *testing.T) {
 	}
 }
 
+func TestPeerTraffic(t *testing.T) {
+	proto := Protocol{
+		Name:   "a",
+		Length: 5,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			if err := ExpectMsg(rw, 2, []uint{1}); err != nil {
+				t.Error(err)
+			}
+			return SendItems(rw, 3, "foo")
+		},
+	}
+	closer, rw, peer, errc := testPeer([]Protocol{proto})
+	defer closer()
+
+	Send(rw, baseProtocolLength+2, []uint{1})
+	if err := ExpectMsg(rw, baseProtocolLength+3, []string{"foo"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errc:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("protocol timeout")
+	}
+	want := &TrafficStats{IngressBytes: 2, IngressMessages: 1, EgressBytes: 5, EgressMessages: 1}
+	if have := peer.Info().Traffic["a"]; !reflect.DeepEqual(have, want) {
+		t.Errorf("traffic mismatch: have %+v, want %+v", have, want)
+	}
+}
+
 func TestPeerPing(t *testing.T) {
 	closer, rw, _, _ := testPeer(nil)
 	defer closer()
@@ -179,6 +209,77 @@ func TestPeerPing(t *testing.T) {
 	}
 }
 
+// This test checks that a peer throttled by its ingress limits still answers
+// pings while a sub-protocol waits for the bandwidth to recover.
+func TestPeerPingThrottled(t *testing.T) {
+	var (
+		clock    = new(mclock.Simulated)
+		bucket   = newTokenBucket(clock, 100)
+		received = make(chan struct{})
+	)
+	proto := Protocol{
+		Name:   "a",
+		Length: 5,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			if err := ExpectMsg(rw, 1, []uint{1}); err != nil {
+				t.Error(err)
+			}
+			close(received)
+			return nil
+		},
+	}
+	var (
+		fd1, fd2   = net.Pipe()
+		key1, key2 = newkey(), newkey()
+		t1         = newTestTransport(&key2.PublicKey, fd1, nil)
+		t2         = newTestTransport(&key1.PublicKey, fd2, &key1.PublicKey)
+		c1         = &conn{fd: fd1, node: newNode(uintID(1), ""), transport: t1, caps: []Cap{proto.cap()}}
+		rw         = &conn{fd: fd2, node: newNode(uintID(2), ""), transport: t2, caps: []Cap{proto.cap()}}
+	)
+	defer rw.close(errors.New("test done"))
+
+	peer := newPeer(log.Root(), c1, []Protocol{proto})
+	peer.ingressLimits = rateLimits{bucket}
+	go peer.run()
+
+	// Exhaust the bandwidth and send a sub-protocol message, which needs to
+	// wait for the bucket to refill
+	bucket.take(100)
+	if err := Send(rw, baseProtocolLength+1, []uint{1}); err != nil {
+		t.Fatal(err)
+	}
+	clock.WaitForTimers(1)
+
+	// Pings must be answered nonetheless
+	pong := make(chan error, 1)
+	go func() {
+		if err := SendItems(rw, pingMsg); err != nil {
+			pong <- err
+			return
+		}
+		pong <- ExpectMsg(rw, pongMsg, nil)
+	}()
+	select {
+	case err := <-pong:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ping not answered while throttled")
+	}
+	select {
+	case <-received:
+		t.Fatal("message delivered before the bandwidth recovered")
+	default:
+	}
+	clock.Run(time.Second)
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered after the bandwidth recovered")
+	}
+}
+
 // This test checks that a disconnect message sent by a peer is returned
 // as the error from Peer.run.
 func TestPeerDisconnect(t *testing.T) {
@@ -0,0 +1,176 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+)
+
+// Penalty is an amount of reputation deducted from a peer for misbehaving.
+type Penalty float64
+
+const (
+	PenaltyTimeout Penalty = 5  // Peer failed to answer a request in time
+	PenaltyUseless Penalty = 10 // Peer answered with an empty or useless response
+	PenaltyInvalid Penalty = 50 // Peer sent a malformed or invalid message
+)
+
+const (
+	// banThreshold is the accumulated penalty at which a peer gets banned.
+	banThreshold = 100
+
+	// penaltyHalfLife is the time it takes for accumulated penalties to decay to
+	// half of their value, so occasional failures never add up to a ban.
+	penaltyHalfLife = 10 * time.Minute
+
+	// penaltyBanDuration is the duration of bans placed due to bad reputation.
+	penaltyBanDuration = time.Hour
+
+	// maxTrackedPenalties is the number of peers with penalties after which the
+	// tracker drops the ones which mostly decayed already.
+	maxTrackedPenalties = 1024
+)
+
+// penaltyScore is the decaying penalty accumulated by a single peer.
+type penaltyScore struct {
+	value   float64
+	updated mclock.AbsTime
+}
+
+// reputation tracks the penalties accumulated by peers, deciding when a peer
+// misbehaved often enough to be banned.
+type reputation struct {
+	clock  mclock.Clock
+	scores map[enode.ID]*penaltyScore
+	lock   sync.Mutex
+}
+
+func newReputation(clock mclock.Clock) *reputation {
+	return &reputation{
+		clock:  clock,
+		scores: make(map[enode.ID]*penaltyScore),
+	}
+}
+
+// decay returns the value of a score at the given time.
+func (s *penaltyScore) decay(now mclock.AbsTime) float64 {
+	return s.value * math.Exp2(-float64(now-s.updated)/float64(penaltyHalfLife))
+}
+
+// penalize adds a penalty to the score of a peer, reporting whether the peer
+// crossed the ban threshold. The score of a banned peer is reset.
+func (r *reputation) penalize(id enode.ID, penalty Penalty) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.clock.Now()
+	score := r.scores[id]
+	if score == nil {
+		if len(r.scores) >= maxTrackedPenalties {
+			r.prune(now)
+		}
+		score = new(penaltyScore)
+		r.scores[id] = score
+	}
+	score.value, score.updated = score.decay(now)+float64(penalty), now
+	if score.value < banThreshold {
+		return false
+	}
+	delete(r.scores, id)
+	return true
+}
+
+// prune drops all the scores which decayed to insignificance.
+func (r *reputation) prune(now mclock.AbsTime) {
+	for id, score := range r.scores {
+		if score.decay(now) < float64(PenaltyTimeout) {
+			delete(r.scores, id)
+		}
+	}
+}
+
+// penalize lowers the reputation of a peer, banning it if it misbehaved too
+// often. It reports whether the peer got banned and should be disconnected.
+func (srv *Server) penalize(id enode.ID, penalty Penalty, reason string) bool {
+	if !srv.reputation.penalize(id, penalty) {
+		return false
+	}
+	srv.log.Debug("Banning misbehaving peer", "id", id, "duration", penaltyBanDuration, "reason", reason)
+	ban := &enode.Ban{ID: id, Until: time.Now().Add(penaltyBanDuration), Reason: reason}
+	if err := srv.nodedb.UpdateBan(ban); err != nil {
+		srv.log.Warn("Failed to persist peer ban", "id", id, "err", err)
+	}
+	return true
+}
+
+// isBanned reports whether a node is currently banned.
+func (srv *Server) isBanned(id enode.ID) bool {
+	return srv.nodedb.Ban(id) != nil
+}
+
+// BanPeer bans the given node for the given duration, or permanently if the
+// duration is zero. The node is disconnected if connected and neither dialed nor
+// accepted until the ban expires or is lifted. Bans persist across restarts in
+// the node database.
+func (srv *Server) BanPeer(id enode.ID, duration time.Duration, reason string) error {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return errServerStopped
+	}
+	ban := &enode.Ban{ID: id, Reason: reason}
+	if duration > 0 {
+		ban.Until = time.Now().Add(duration)
+	}
+	if err := srv.nodedb.UpdateBan(ban); err != nil {
+		return err
+	}
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		if peer := peers[id]; peer != nil {
+			peer.Disconnect(DiscUselessPeer)
+		}
+	})
+	return nil
+}
+
+// UnbanPeer lifts the ban of the given node, reporting whether it was banned.
+func (srv *Server) UnbanPeer(id enode.ID) (bool, error) {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return false, errServerStopped
+	}
+	return srv.nodedb.DeleteBan(id), nil
+}
+
+// Bans returns all the currently active node bans.
+func (srv *Server) Bans() ([]*enode.Ban, error) {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return nil, errServerStopped
+	}
+	return srv.nodedb.Bans(), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ubiq/go-ubiq/v7/common/mclock"
+	"github.com/ubiq/go-ubiq/v7/internal/testlog"
+	"github.com/ubiq/go-ubiq/v7/log"
+	"github.com/ubiq/go-ubiq/v7/p2p/enode"
+	"github.com/ubiq/go-ubiq/v7/p2p/enr"
+)
+
+// Tests that penalties accumulate up to a ban, but decay over time.
+func TestReputationDecay(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		rep   = newReputation(clock)
+		id    = randomID()
+	)
+	if rep.penalize(id, PenaltyInvalid) {
+		t.Fatalf("banned after a single penalty")
+	}
+	// Half of the penalty decays, a second one must not cross the threshold
+	clock.Run(penaltyHalfLife)
+	if rep.penalize(id, PenaltyInvalid) {
+		t.Fatalf("banned despite decayed penalty")
+	}
+	// Without decay, the third penalty crosses the threshold
+	if !rep.penalize(id, PenaltyInvalid) {
+		t.Fatalf("not banned after crossing the threshold")
+	}
+	// Bans reset the accumulated penalties
+	if rep.penalize(id, PenaltyInvalid) {
+		t.Fatalf("banned right after previous ban")
+	}
+}
+
+// Tests that banned nodes are rejected until unbanned, and that misbehaving
+// peers get banned automatically.
+func TestServerBans(t *testing.T) {
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+	defer srv.Stop()
+
+	newconn := func(id enode.ID) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(&newkey().PublicKey, fd, nil)
+		node := enode.SignNull(new(enr.Record), id)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+	}
+	// Ban a node and ensure it's rejected
+	id := randomID()
+	if err := srv.BanPeer(id, time.Hour, "test"); err != nil {
+		t.Fatalf("failed to ban node: %v", err)
+	}
+	if err := srv.checkpoint(newconn(id), srv.checkpointPostHandshake); err != DiscUselessPeer {
+		t.Errorf("banned node error mismatch: have %v, want %v", err, DiscUselessPeer)
+	}
+	bans, err := srv.Bans()
+	if err != nil || len(bans) != 1 || bans[0].ID != id || bans[0].Reason != "test" {
+		t.Errorf("ban list mismatch: have %v (%v), want %x", bans, err, id)
+	}
+	// Lift the ban and ensure the node is accepted again
+	if lifted, err := srv.UnbanPeer(id); err != nil || !lifted {
+		t.Fatalf("failed to unban node: %v, %v", lifted, err)
+	}
+	if err := srv.checkpoint(newconn(id), srv.checkpointPostHandshake); err != nil {
+		t.Errorf("unbanned node rejected: %v", err)
+	}
+	// Misbehave until banned automatically
+	id = randomID()
+	for i := 0; ; i++ {
+		if srv.penalize(id, PenaltyInvalid, "invalid") {
+			break
+		}
+		if i > banThreshold/int(PenaltyInvalid) {
+			t.Fatalf("misbehaving node not banned")
+		}
+	}
+	if err := srv.checkpoint(newconn(id), srv.checkpointPostHandshake); err != DiscUselessPeer {
+		t.Errorf("misbehaving node error mismatch: have %v, want %v", err, DiscUselessPeer)
+	}
+}
@@ -119,6 +119,15 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// IngressLimit and EgressLimit cap the bandwidth, in payload bytes per second,
+	// of the sub-protocol messages read from and written to all peers combined.
+	// PeerIngressLimit and PeerEgressLimit cap the same of each individual peer.
+	// Zero means no limit.
+	IngressLimit     int `toml:",omitempty"`
+	EgressLimit      int `toml:",omitempty"`
+	PeerIngressLimit int `toml:",omitempty"`
+	PeerEgressLimit  int `toml:",omitempty"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
@@ -178,12 +187,15 @@ type Server struct {
 	peerFeed     event.Feed
 	log          log.Logger
 
-	nodedb    *enode.DB
-	localnode *enode.LocalNode
-	ntab      *discover.UDPv4
-	DiscV5    *discover.UDPv5
-	discmix   *enode.FairMix
-	dialsched *dialScheduler
+	nodedb     *enode.DB
+	reputation *reputation
+	ingress    *tokenBucket // Global ingress bandwidth cap, nil if unlimited
+	egress     *tokenBucket // Global egress bandwidth cap, nil if unlimited
+	localnode  *enode.LocalNode
+	ntab       *discover.UDPv4
+	DiscV5     *discover.UDPv5
+	discmix    *enode.FairMix
+	dialsched  *dialScheduler
 
 	// Channels into the run loop.
 	quit                    chan struct{}
@@ -469,6 +481,13 @@ func (srv *Server) Start() (err error) {
 	srv.removetrusted = make(chan *enode.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
+	srv.reputation = newReputation(srv.clock)
+	if srv.IngressLimit > 0 {
+		srv.ingress = newTokenBucket(srv.clock, srv.IngressLimit)
+	}
+	if srv.EgressLimit > 0 {
+		srv.egress = newTokenBucket(srv.clock, srv.EgressLimit)
+	}
 
 	if err := srv.setupLocalNode(); err != nil {
 		return err
@@ -621,6 +640,7 @@ func (srv *Server) setupDialScheduler() {
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.Logger,
 		netRestrict:    srv.NetRestrict,
+		banned:         srv.isBanned,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -810,6 +830,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case srv.isBanned(c.node.ID()):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
@@ -1014,6 +1036,9 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.penalize = srv.penalize
+	p.ingressLimits = newRateLimits(srv.clock, srv.PeerIngressLimit, srv.ingress)
+	p.egressLimits = newRateLimits(srv.clock, srv.PeerEgressLimit, srv.egress)
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
@@ -85,6 +85,10 @@ type Client struct {
 
 	idCounter uint32
 
+	// Limits applied to batches served on this connection.
+	batchItemLimit     int
+	batchResponseLimit int
+
 	// This function, if non-nil, is called when the connection is lost.
 	reconnectFunc reconnectFunc
 
@@ -120,7 +124,7 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	if !c.isHTTP() && c.scheme != "" {
 		ctx = context.WithValue(ctx, "scheme", c.scheme)
 	}
-	handler := newHandler(ctx, conn, c.idgen, c.services)
+	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseLimit)
 	return &clientConn{conn, handler}
 }
 
@@ -206,12 +210,12 @@ func newClient(initctx context.Context, connect reconnectFunc) (*Client, error)
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), new(serviceRegistry))
+	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), 0, 0)
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, batchItemLimit, batchResponseLimit int) *Client {
 	scheme := ""
 	switch conn.(type) {
 	case *httpConn:
@@ -235,6 +239,9 @@ func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *C
 		reqInit:     make(chan *requestOp),
 		reqSent:     make(chan error, 1),
 		reqTimeout:  make(chan *requestOp),
+
+		batchItemLimit:     batchItemLimit,
+		batchResponseLimit: batchResponseLimit,
 	}
 	if !c.isHTTP() {
 		go c.dispatch(conn)
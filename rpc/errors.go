@@ -54,10 +54,19 @@ var (
 	_ Error = new(invalidRequestError)
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
+	_ Error = new(batchLimitError)
 	_ Error = new(CustomError)
 )
 
-const defaultErrorCode = -32000
+const (
+	defaultErrorCode        = -32000
+	errcodeBatchLimitExceed = -32005
+)
+
+const (
+	errMsgBatchTooLarge    = "batch too large"
+	errMsgResponseTooLarge = "response too large"
+)
 
 type methodNotFoundError struct{ method string }
 
@@ -103,6 +112,13 @@ func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
 
+// batch item or response size limit exceeded
+type batchLimitError struct{ message string }
+
+func (e *batchLimitError) ErrorCode() int { return errcodeBatchLimitExceed }
+
+func (e *batchLimitError) Error() string { return e.message }
+
 type CustomError struct {
 	Code            int
 	ValidationError string
@@ -62,6 +62,9 @@ type handler struct {
 	log            log.Logger
 	allowSubscribe bool
 
+	batchItemLimit     int // maximum number of calls executed per batch (0 = unlimited)
+	batchResponseLimit int // maximum total size of batch results in bytes (0 = unlimited)
+
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
 }
@@ -71,7 +74,7 @@ type callProc struct {
 	notifiers []*Notifier
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, batchItemLimit, batchResponseLimit int) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	h := &handler{
 		reg:            reg,
@@ -84,6 +87,9 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		allowSubscribe: true,
 		serverSubs:     make(map[ID]*Subscription),
 		log:            log.Root(),
+
+		batchItemLimit:     batchItemLimit,
+		batchResponseLimit: batchResponseLimit,
 	}
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
@@ -112,14 +118,33 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	if len(calls) == 0 {
 		return
 	}
+	// Calls beyond the batch item limit are not executed at all
+	var excess []*jsonrpcMessage
+	if h.batchItemLimit > 0 && len(calls) > h.batchItemLimit {
+		calls, excess = calls[:h.batchItemLimit], calls[h.batchItemLimit:]
+	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
-		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
+		var (
+			answers = make([]*jsonrpcMessage, 0, len(msgs))
+			size    int
+		)
+		for i, msg := range calls {
+			answer := h.handleCallMsg(cp, msg)
+			if answer == nil {
+				continue
 			}
+			// Once the accumulated results exceed the response limit, reject the
+			// offending call and all the remaining ones without executing them.
+			size += len(answer.Result)
+			if h.batchResponseLimit > 0 && size > h.batchResponseLimit {
+				answers = append(answers, msg.errorResponse(&batchLimitError{errMsgResponseTooLarge}))
+				answers = appendBatchErrors(answers, calls[i+1:], errMsgResponseTooLarge)
+				break
+			}
+			answers = append(answers, answer)
 		}
+		answers = appendBatchErrors(answers, excess, errMsgBatchTooLarge)
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
 			h.conn.writeJSON(cp.ctx, answers)
@@ -130,6 +155,17 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// appendBatchErrors appends a batch limit error response for every call among
+// the given messages. Notifications are dropped silently, as they expect no reply.
+func appendBatchErrors(answers []*jsonrpcMessage, msgs []*jsonrpcMessage, reason string) []*jsonrpcMessage {
+	for _, msg := range msgs {
+		if msg.isCall() {
+			answers = append(answers, msg.errorResponse(&batchLimitError{reason}))
+		}
+	}
+	return answers
+}
+
 // handleMsg handles a single message.
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	if ok := h.handleImmediate(msg); ok {
@@ -26,7 +26,7 @@ var (
 	rpcRequestGauge        = metrics.NewRegisteredGauge("rpc/requests", nil)
 	successfulRequestGauge = metrics.NewRegisteredGauge("rpc/success", nil)
 	failedReqeustGauge     = metrics.NewRegisteredGauge("rpc/failure", nil)
-	rpcServingTimer        = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+	rpcServingTimer        = metrics.NewRegisteredBucketedTimer("rpc/duration/all", nil, metrics.DefaultTimerBuckets)
 )
 
 func newRPCServingTimer(method string, valid bool) metrics.Timer {
@@ -35,5 +35,5 @@ func newRPCServingTimer(method string, valid bool) metrics.Timer {
 		flag = "failure"
 	}
 	m := fmt.Sprintf("rpc/duration/%s/%s", method, flag)
-	return metrics.GetOrRegisterTimer(m, nil)
+	return metrics.GetOrRegisterBucketedTimer(m, nil, metrics.DefaultTimerBuckets)
 }
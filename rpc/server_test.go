@@ -69,17 +69,20 @@ func TestServer(t *testing.T) {
 }
 
 func runTestScript(t *testing.T, file string) {
-	server := newTestServer()
 	content, err := ioutil.ReadFile(file)
 	if err != nil {
 		t.Fatal(err)
 	}
+	runTestScriptOn(t, newTestServer(), string(content))
+}
 
+// runTestScriptOn executes the given test script against the given server.
+func runTestScriptOn(t *testing.T, server *Server, content string) {
 	clientConn, serverConn := net.Pipe()
 	defer clientConn.Close()
 	go server.ServeCodec(NewCodec(serverConn), 0)
 	readbuf := bufio.NewReader(clientConn)
-	for _, line := range strings.Split(string(content), "\n") {
+	for _, line := range strings.Split(content, "\n") {
 		line = strings.TrimSpace(line)
 		switch {
 		case len(line) == 0 || strings.HasPrefix(line, "//"):
@@ -111,6 +114,31 @@ func runTestScript(t *testing.T, file string) {
 	}
 }
 
+// This test checks that batches exceeding the configured item count or response
+// size limits are answered with an error for every excess call.
+func TestServerBatchLimits(t *testing.T) {
+	t.Run("items", func(t *testing.T) {
+		server := newTestServer()
+		server.SetBatchLimits(2, 0)
+		defer server.Stop()
+
+		runTestScriptOn(t, server, `
+			--> [{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",3]},{"jsonrpc":"2.0","method":"test_echo","params":["x",4]},{"jsonrpc":"2.0","id":5,"method":"test_echo","params":["x",5]}]
+			<-- [{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}},{"jsonrpc":"2.0","id":3,"error":{"code":-32005,"message":"batch too large"}},{"jsonrpc":"2.0","id":5,"error":{"code":-32005,"message":"batch too large"}}]
+		`)
+	})
+	t.Run("response", func(t *testing.T) {
+		server := newTestServer()
+		server.SetBatchLimits(0, 60)
+		defer server.Stop()
+
+		runTestScriptOn(t, server, `
+			--> [{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["x",3]}]
+			<-- [{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}},{"jsonrpc":"2.0","id":2,"error":{"code":-32005,"message":"response too large"}},{"jsonrpc":"2.0","id":3,"error":{"code":-32005,"message":"response too large"}}]
+		`)
+	})
+}
+
 // This test checks that responses are delivered for very short-lived connections that
 // only carry a single request.
 func TestServerShortLivedConn(t *testing.T) {